@@ -6,16 +6,22 @@ import (
 	"os"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	shlex "github.com/carapace-sh/carapace-shlex"
 	"github.com/carapace-sh/carapace/internal/cache"
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/env"
+	"github.com/carapace-sh/carapace/internal/profile"
 	"github.com/carapace-sh/carapace/pkg/cache/key"
+	"github.com/carapace-sh/carapace/pkg/execlocation"
 	"github.com/carapace-sh/carapace/pkg/match"
+	pkgshlex "github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/carapace-sh/carapace/pkg/style"
 	pkgtraverse "github.com/carapace-sh/carapace/pkg/traverse"
+	"github.com/carapace-sh/carapace/pkg/types"
 	"github.com/carapace-sh/carapace/pkg/uid"
 )
 
@@ -24,6 +30,9 @@ type Action struct {
 	meta      common.Meta
 	rawValues common.RawValues
 	callback  CompletionCallback
+	location  string // where the callback was created (only set with CARAPACE_PROFILE)
+
+	explanation string // short description of the action (see Explain)
 }
 
 // ActionMap maps Actions to an identifier.
@@ -38,6 +47,10 @@ func (a Action) Cache(timeout time.Duration, keys ...key.Key) Action {
 		cachedCallback := a.callback
 		_, file, line, _ := runtime.Caller(1) // generate uid from wherever Cache() was called
 		a.callback = func(c Context) Action {
+			if c.dryRun {
+				return cachedCallback(c) // don't cache the output of commands that weren't executed
+			}
+
 			cacheFile, err := cache.File(file, line, keys...)
 			if err != nil {
 				return cachedCallback(c)
@@ -48,7 +61,7 @@ func (a Action) Cache(timeout time.Duration, keys ...key.Key) Action {
 			}
 
 			invokedAction := (Action{callback: cachedCallback}).Invoke(c)
-			if invokedAction.action.meta.Messages.IsEmpty() {
+			if invokedAction.action.meta.Messages.IsEmpty() && !invokedAction.action.meta.Sensitive {
 				if cacheFile, err := cache.File(file, line, keys...); err == nil { // regenerate as cache keys might have changed due to invocation
 					_ = cache.WriteE(cacheFile, invokedAction.export())
 				}
@@ -59,6 +72,27 @@ func (a Action) Cache(timeout time.Duration, keys ...key.Key) Action {
 	return a
 }
 
+// Sensitive marks the values as sensitive so that they are never written to the cache.
+//
+//	carapace.ActionCallback(secrets).Sensitive().Cache(time.Hour) // not cached
+func (a Action) Sensitive() Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		invoked.action.meta.Sensitive = true
+		return invoked.ToA()
+	})
+}
+
+// Prefetchable registers the action to be invoked by `_carapace warm`.
+// Combined with Cache this populates the cache in advance (e.g. from shell init or cron).
+//
+//	carapace.ActionCallback(expensive).Cache(24 * time.Hour).Prefetchable()
+func (a Action) Prefetchable() Action {
+	_, file, line, _ := runtime.Caller(1) // register once per call site (e.g. when called within a callback)
+	prefetch.add(fmt.Sprintf("%v:%v", file, line), a)
+	return a
+}
+
 // Chdir changes the current working directory to the named directory for the duration of invocation.
 func (a Action) Chdir(dir string) Action {
 	return ActionCallback(func(c Context) Action {
@@ -87,6 +121,79 @@ func (a Action) ChdirF(f func(tc pkgtraverse.Context) (string, error)) Action {
 	})
 }
 
+// ChdirRepoRoot changes the current working directory to the nearest parent directory
+// containing any of given markers (default: `.git`, `go.mod`, `package.json`).
+//
+//	carapace.ActionFiles().ChdirRepoRoot()
+//	carapace.ActionFiles().ChdirRepoRoot("go.work", ".git")
+func (a Action) ChdirRepoRoot(markers ...string) Action {
+	return a.ChdirF(pkgtraverse.RepoRoot(markers...))
+}
+
+// DirectoriesFirst orders directories before other values (unless overridden with `CARAPACE_ORDER`).
+//
+//	carapace.ActionFiles().DirectoriesFirst()
+func (a Action) DirectoriesFirst() Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		invoked.action.meta.Order = "directories"
+		return invoked.ToA()
+	})
+}
+
+// DocumentationF sets a longer documentation shown in preview windows using a function.
+//
+//	carapace.ActionValues("HEAD", "HEAD~1").DocumentationF(func(s string) string {
+//		output, _ := exec.Command("git", "show", "--stat", s).Output()
+//		return string(output)
+//	})
+func (a Action) DocumentationF(f func(s string) string) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		for index, v := range invoked.action.rawValues {
+			invoked.action.rawValues[index].Documentation = f(v.Value)
+		}
+		return invoked.ToA()
+	})
+}
+
+// Explain attaches a short description of the action for tooling (e.g. `export` output, Lint and the log).
+// It is stored on the returned action, so it should be the last modifier of a registered action.
+//
+//	carapace.ActionExecCommand("kubectl", "get", "namespaces")(parse).Explain("lists kubernetes namespaces")
+func (a Action) Explain(s string) Action {
+	explained := ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		Log().Debugf("action %#v returned %v values", s, len(invoked.action.rawValues))
+		invoked.action.meta.Explanation = s
+		return invoked.ToA()
+	})
+	explained.explanation = s
+	return explained
+}
+
+// ExecLocation executes external commands at given location (e.g. inside a container).
+// Only affects actions using Context.Command (files are still completed locally).
+//
+//	carapace.ActionExecCommand("ls", "/etc")(...).ExecLocation(execlocation.Docker{Container: "web"})
+func (a Action) ExecLocation(location execlocation.Location) Action {
+	return ActionCallback(func(c Context) Action {
+		c.location = location
+		return a.Invoke(c).ToA()
+	})
+}
+
+// ExecLocationF is like ExecLocation but uses a function.
+func (a Action) ExecLocationF(f func(tc pkgtraverse.Context) (execlocation.Location, error)) Action {
+	return ActionCallback(func(c Context) Action {
+		location, err := f(c)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+		return a.ExecLocation(location)
+	})
+}
+
 // Filter filters given values.
 //
 //	carapace.ActionValues("A", "B", "C").Filter("B") // ["A", "C"]
@@ -123,6 +230,9 @@ func (a Action) Invoke(c Context) InvokedAction {
 	}
 
 	if a.rawValues == nil && a.callback != nil {
+		if a.location != "" {
+			defer profile.Start(profile.KindCallback, a.location)()
+		}
 		result := a.callback(c).Invoke(c)
 		result.action.meta.Merge(a.meta)
 		return result
@@ -130,6 +240,54 @@ func (a Action) Invoke(c Context) InvokedAction {
 	return InvokedAction{a}
 }
 
+// Limit restricts the amount of values to given maximum.
+// Further values are available with `_carapace export --page {n}`.
+//
+//	carapace.ActionValues(tags...).Limit(100)
+func (a Action) Limit(n int) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		filtered := invoked.action.rawValues.FilterPrefix(c.Value)
+		if invoked.action.meta.Fold {
+			filtered = invoked.action.rawValues.FilterPrefixFold(c.Value)
+		}
+		if n <= 0 || len(filtered) <= n {
+			return invoked.ToA()
+		}
+		sort.Sort(common.ByDisplay(filtered))
+
+		start := (env.Page() - 1) * n
+		if start > len(filtered) {
+			start = len(filtered)
+		}
+		end := start + n
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+
+		invoked.action.rawValues = filtered[start:end]
+		if remaining := len(filtered) - end; remaining > 0 {
+			invoked.action.meta.AddMore(remaining, c.translate)
+		}
+		return invoked.ToA()
+	})
+}
+
+// LinkF sets a link (e.g. url of an issue) shown as terminal hyperlink using a function.
+//
+//	carapace.ActionValues("1234", "1235").LinkF(func(s string) string {
+//		return "https://github.com/carapace-sh/carapace/issues/" + s
+//	})
+func (a Action) LinkF(f func(s string) string) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		for index, v := range invoked.action.rawValues {
+			invoked.action.rawValues[index].Link = f(v.Value)
+		}
+		return invoked.ToA()
+	})
+}
+
 // List wraps the Action in an ActionMultiParts with given divider.
 func (a Action) List(divider string) Action {
 	return ActionMultiParts(divider, func(c Context) Action {
@@ -137,6 +295,18 @@ func (a Action) List(divider string) Action {
 	})
 }
 
+// MessageLink links messages to given documentation url (e.g. how to configure a missing token).
+// Shells supporting it show the link along with the message (zsh as terminal hyperlink).
+//
+//	carapace.ActionMessage("missing GITHUB_TOKEN").MessageLink("https://example.com/docs/auth")
+func (a Action) MessageLink(link string) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		invoked.action.meta.Messages.Link(link)
+		return invoked.ToA()
+	})
+}
+
 // MultiParts splits values of an Action by given dividers and completes each segment separately.
 func (a Action) MultiParts(dividers ...string) Action {
 	return ActionCallback(func(c Context) Action {
@@ -235,10 +405,11 @@ func (a Action) NoSpace(suffixes ...rune) Action {
 //	carapace.ActionValues("melon", "drop", "fall").Prefix("water")
 func (a Action) Prefix(prefix string) Action {
 	return ActionCallback(func(c Context) Action {
+		m := match.Current()
 		switch {
-		case match.HasPrefix(c.Value, prefix):
-			c.Value = match.TrimPrefix(c.Value, prefix)
-		case match.HasPrefix(prefix, c.Value):
+		case m.HasPrefix(c.Value, prefix):
+			c.Value = m.TrimPrefix(c.Value, prefix)
+		case m.HasPrefix(prefix, c.Value):
 			c.Value = ""
 		default:
 			return ActionValues()
@@ -291,9 +462,9 @@ func (a Action) split(pipelines bool) Action {
 		var context Context
 		if pipelines {
 			tokens = tokens.CurrentPipeline()
-			context = NewContext(tokens.FilterRedirects().Words().Strings()...)
+			context = NewContext(pkgshlex.SkipAssignments(tokens.FilterRedirects().Words().Strings())...)
 		} else {
-			context = NewContext(tokens.Words().Strings()...)
+			context = NewContext(pkgshlex.SkipAssignments(tokens.Words().Strings())...)
 		}
 
 		originalValue := c.Value
@@ -304,22 +475,29 @@ func (a Action) split(pipelines bool) Action {
 
 		if pipelines { // support redirects
 			if len(tokens) > 1 && tokens[len(tokens)-2].WordbreakType.IsRedirect() {
-				LOG.Printf("completing files for redirect arg %#v", tokens.Words().CurrentToken().Value)
+				Log().Debugf("completing files for redirect arg %#v", tokens.Words().CurrentToken().Value)
 				prefix = originalValue[:tokens.CurrentToken().Index]
 				c.Value = tokens.CurrentToken().Value
 				a = ActionFiles()
 			}
 		}
 
+		c.Quote = pkgshlex.OpenQuote(tokens.CurrentToken().State)
+
 		invoked := a.Invoke(c)
 		for index, value := range invoked.action.rawValues {
-			if !invoked.action.meta.Nospace.Matches(value.Value) || strings.Contains(value.Value, " ") { // TODO special characters
-				switch tokens.CurrentToken().State {
-				case shlex.QUOTING_ESCAPING_STATE:
-					invoked.action.rawValues[index].Value = fmt.Sprintf(`"%v"`, strings.ReplaceAll(value.Value, `"`, `\"`))
-				case shlex.QUOTING_STATE:
-					invoked.action.rawValues[index].Value = fmt.Sprintf(`'%v'`, strings.ReplaceAll(value.Value, `'`, `'"'"'`))
-				default:
+			closingQuote := c.Quote // keep the quote open for nospace values (e.g. `"dir/` for further descent)
+			if invoked.action.meta.Nospace.Matches(value.Value) {
+				closingQuote = ""
+			}
+
+			switch c.Quote {
+			case `"`:
+				invoked.action.rawValues[index].Value = fmt.Sprintf(`"%v%v`, strings.ReplaceAll(value.Value, `"`, `\"`), closingQuote)
+			case `'`:
+				invoked.action.rawValues[index].Value = fmt.Sprintf(`'%v%v`, strings.ReplaceAll(value.Value, `'`, `'"'"'`), closingQuote)
+			default:
+				if !invoked.action.meta.Nospace.Matches(value.Value) || strings.Contains(value.Value, " ") { // TODO special characters
 					invoked.action.rawValues[index].Value = strings.Replace(value.Value, ` `, `\ `, -1)
 				}
 			}
@@ -346,10 +524,24 @@ func (a Action) Style(s string) Action {
 //	ActionValues("dir/", "test.txt").StyleF(style.ForPathExt)
 //	ActionValues("true", "false").StyleF(style.ForKeyword)
 func (a Action) StyleF(f func(s string, sc style.Context) string) Action {
+	return a.StyleValueF(func(v types.RawValue, sc style.Context) string {
+		return f(v.Value, sc)
+	})
+}
+
+// StyleValueF sets the style using a function which receives the whole value (display, description, tag, current style, ...).
+//
+//	ActionValuesDescribed("one", "deprecated: use two", "two", "").StyleValueF(func(v types.RawValue, sc style.Context) string {
+//		if strings.HasPrefix(v.Description, "deprecated") {
+//			return style.Dim
+//		}
+//		return v.Style
+//	})
+func (a Action) StyleValueF(f func(v types.RawValue, sc style.Context) string) Action {
 	return ActionCallback(func(c Context) Action {
 		invoked := a.Invoke(c)
 		for index, v := range invoked.action.rawValues {
-			invoked.action.rawValues[index].Style = f(v.Value, c)
+			invoked.action.rawValues[index].Style = f(v, c)
 		}
 		return invoked.ToA()
 	})
@@ -377,6 +569,16 @@ func (a Action) Suffix(suffix string) Action {
 	})
 }
 
+// Suggest marks given values as default so that shells can highlight them.
+// This is done automatically for the default value of a flag.
+//
+//	carapace.ActionValues("json", "yaml").Suggest("json")
+func (a Action) Suggest(values ...string) Action {
+	return ActionCallback(func(c Context) Action {
+		return a.Invoke(c).Suggest(values...).ToA()
+	})
+}
+
 // Suppress suppresses specific error messages using regular expressions.
 func (a Action) Suppress(expr ...string) Action {
 	return ActionCallback(func(c Context) Action {
@@ -437,6 +639,18 @@ func (a Action) Timeout(d time.Duration, alternative Action) Action {
 	})
 }
 
+// Unique filters values already present in `Context.Args` or `Context.Parts`.
+//
+//	carapace.ActionValues("A", "B", "C").Unique()
+func (a Action) Unique() Action {
+	return ActionCallback(func(c Context) Action {
+		entered := make([]string, 0, len(c.Args)+len(c.Parts))
+		entered = append(entered, c.Args...)
+		entered = append(entered, c.Parts...)
+		return a.Filter(entered...)
+	})
+}
+
 // UniqueList wraps the Action in an ActionMultiParts with given divider.
 func (a Action) UniqueList(divider string) Action {
 	return ActionMultiParts(divider, func(c Context) Action {
@@ -512,6 +726,25 @@ func (a Action) UidF(f func(s string, uc uid.Context) (*url.URL, error)) Action
 	})
 }
 
+// source prefixes the source of values with given producer (e.g. `flag:--values > batch[1] > exec:git`).
+// Only applies with `CARAPACE_PROVENANCE` enabled.
+func (a Action) source(producer string) Action {
+	if !env.Provenance() {
+		return a
+	}
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		for index, value := range invoked.action.rawValues {
+			if value.Source == "" {
+				invoked.action.rawValues[index].Source = producer
+			} else {
+				invoked.action.rawValues[index].Source = producer + " > " + value.Source
+			}
+		}
+		return invoked.ToA()
+	})
+}
+
 // Usage sets the usage.
 func (a Action) Usage(usage string, args ...interface{}) Action {
 	return a.UsageF(func() string {
@@ -528,3 +761,24 @@ func (a Action) UsageF(f func() string) Action {
 		return a
 	})
 }
+
+// WithEnv sets an environment variable for the duration of invocation.
+//
+//	carapace.ActionExecCommand("kubectl", "config", "get-contexts", "--output", "name")(...).WithEnv("KUBECONFIG", "/tmp/kubeconfig")
+func (a Action) WithEnv(key, value string) Action {
+	return ActionCallback(func(c Context) Action {
+		c.Setenv(key, value)
+		return a.Invoke(c).ToA()
+	})
+}
+
+// WithEnvF is like WithEnv but uses a function.
+func (a Action) WithEnvF(key string, f func(tc pkgtraverse.Context) (string, error)) Action {
+	return ActionCallback(func(c Context) Action {
+		value, err := f(c)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+		return a.WithEnv(key, value)
+	})
+}
@@ -2,15 +2,20 @@ package carapace
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/carapace-sh/carapace/internal/assert"
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/pkg/execlocation"
 	"github.com/carapace-sh/carapace/pkg/style"
+	pkgtraverse "github.com/carapace-sh/carapace/pkg/traverse"
+	"github.com/carapace-sh/carapace/pkg/types"
 	"github.com/carapace-sh/carapace/pkg/uid"
 )
 
@@ -76,6 +81,85 @@ func TestCache(t *testing.T) {
 	assertNotEqual(t, a1, a3)
 }
 
+func TestPrefetchable(t *testing.T) {
+	id := time.Now().String() // unique cache key
+	invocations := 0
+	a := ActionCallback(func(c Context) Action {
+		invocations++
+		return ActionValues("prefetched")
+	}).Cache(time.Minute, func() (string, error) { return id, nil }).Prefetchable()
+
+	if messages := prefetch.warm(Context{}); len(messages) != 0 {
+		t.Error(messages)
+	}
+	a.Invoke(Context{})
+	if invocations != 1 {
+		t.Errorf("expected cache to be populated: %v invocations", invocations)
+	}
+}
+
+func TestPrefetchableDuplicates(t *testing.T) {
+	count := len(prefetch.actions)
+	for i := 0; i < 3; i++ {
+		ActionValues("duplicate").Prefetchable()
+	}
+	if added := len(prefetch.actions) - count; added != 1 {
+		t.Errorf("expected a single registration per call site: %v", added)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	invoked := Batch(
+		ActionValues("a").Explain("first"),
+		ActionValues("b"),
+	).ToA().Invoke(Context{})
+	if invoked.action.meta.Explanation != "first" {
+		t.Errorf("expected explanation to be kept: %#v", invoked.action.meta.Explanation)
+	}
+
+	if a := ActionValues("a").Explain("registered"); a.explanation != "registered" {
+		t.Errorf("expected explanation to be stored on the action: %#v", a.explanation)
+	}
+
+	invoked = ActionValues("a").Explain("inner").Explain("outer").Invoke(Context{})
+	if invoked.action.meta.Explanation != "outer" {
+		t.Errorf("expected outer explanation: %#v", invoked.action.meta.Explanation)
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	id := time.Now().String() // unique cache key
+	invocations := 0
+	f := func() Action {
+		return ActionCallback(func(c Context) Action {
+			invocations++
+			return ActionValues("secret")
+		}).Sensitive().Cache(time.Minute, func() (string, error) { return id, nil })
+	}
+
+	f().Invoke(Context{})
+	f().Invoke(Context{})
+	if invocations != 2 {
+		t.Errorf("expected sensitive values not to be cached: %v invocations", invocations)
+	}
+}
+
+func TestWithEnv(t *testing.T) {
+	a := ActionCallback(func(c Context) Action {
+		return ActionValues(c.Getenv("example"))
+	})
+
+	assertEqual(t,
+		ActionValues("value").Invoke(Context{}),
+		a.WithEnv("example", "value").Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("/tmp").Invoke(Context{}),
+		a.WithEnvF("example", func(tc pkgtraverse.Context) (string, error) { return tc.Abs("/tmp") }).Invoke(Context{}),
+	)
+}
+
 func TestSkipCache(t *testing.T) {
 	a := ActionCallback(func(c Context) Action {
 		return ActionValues().Invoke(c).Merge(
@@ -172,6 +256,19 @@ func TestActionDirectories(t *testing.T) {
 		)),
 		ActionDirectories().Invoke(Context{Value: "example/cm"}),
 	)
+
+	assertEqual(t,
+		ActionStyledValues(
+			"cmd/", style.Of(style.Blue, style.Bold),
+			"cmd/_test/", style.Of(style.Blue, style.Bold),
+			"cmd/_test_files/", style.Of(style.Blue, style.Bold),
+		).NoSpace('/').Tag("directories").Invoke(Context{}).Prefix("example/").UidF(uid.Map(
+			"example/cmd/", "file://"+wd("")+"/example/cmd/",
+			"example/cmd/_test/", "file://"+wd("")+"/example/cmd/_test/",
+			"example/cmd/_test_files/", "file://"+wd("")+"/example/cmd/_test_files/",
+		)),
+		ActionDirectories().Invoke(Context{Value: "example/cmd"}), // unique directory is descended
+	)
 }
 
 func TestActionFiles(t *testing.T) {
@@ -214,6 +311,141 @@ func TestActionFiles(t *testing.T) {
 	)
 }
 
+func TestPathOpts(t *testing.T) {
+	assertEqual(t,
+		ActionValues().NoSpace('/').Tag("directories").Invoke(Context{}),
+		PathOpts{Depth: 1}.Directories().Invoke(Context{Value: "example/"}),
+	)
+
+	assertEqual(t,
+		ActionStyledValues(
+			"_test", style.Of(style.Blue, style.Bold),
+			"cmd", style.Of(style.Blue, style.Bold),
+		).NoSpace('/').Tag("directories").Invoke(Context{}).Prefix("example/").UidF(uid.Map(
+			"example/_test", "file://"+wd("")+"/example/_test",
+			"example/cmd", "file://"+wd("")+"/example/cmd",
+		)),
+		PathOpts{Depth: 2}.Directories().Invoke(Context{Value: "example/"}),
+	)
+
+	assertEqual(t,
+		ActionStyledValues(
+			"_test/", style.Of(style.Blue, style.Bold),
+			"cmd/", style.Of(style.Blue, style.Bold),
+			"main_test.go", style.Default,
+		).NoSpace('/').Tag("files").Invoke(Context{}).Prefix("example/").UidF(uid.Map(
+			"example/_test/", "file://"+wd("example")+"/_test/",
+			"example/cmd/", "file://"+wd("example")+"/cmd/",
+			"example/main_test.go", "file://"+wd("example")+"/main_test.go",
+		)),
+		PathOpts{Patterns: []string{"*_test.go"}}.Files().Invoke(Context{Value: "example/"}).Filter("example/example"),
+	)
+
+	if vals := (PathOpts{Depth: 1}).Directories().Invoke(Context{Value: wd("") + "/example/"}).action.rawValues; len(vals) == 0 {
+		t.Error("expected absolute paths not to be limited by depth")
+	}
+
+	assertEqual(t,
+		ActionValues().NoSpace('/').Tag("files").Invoke(Context{}),
+		PathOpts{Dotfiles: "never"}.Files().Invoke(Context{Value: ".git"}),
+	)
+}
+
+func TestPathOptsDotfilesEnv(t *testing.T) {
+	t.Setenv("CARAPACE_DOTFILES", "always")
+	if vals := ActionFiles().Invoke(Context{}).action.rawValues.Retain(".github/"); len(vals) != 1 {
+		t.Error("expected dotfiles to be included")
+	}
+
+	t.Setenv("CARAPACE_DOTFILES", "never")
+	assertEqual(t,
+		ActionValues().NoSpace('/').Tag("files").Invoke(Context{}),
+		ActionFiles().Invoke(Context{Value: ".git"}),
+	)
+}
+
+func TestActionFilesBackslash(t *testing.T) {
+	assertEqual(t,
+		ActionStyledValues(
+			"_test\\", style.Of(style.Blue, style.Bold),
+			"cmd\\", style.Of(style.Blue, style.Bold),
+		).NoSpace('/', '\\').Tag("directories").Invoke(Context{}).Prefix(`example\`).UidF(uid.Map(
+			`example\_test\`, "file://"+wd("")+"/example/_test/",
+			`example\cmd\`, "file://"+wd("")+"/example/cmd/",
+		)),
+		actionBackslashPath(ActionDirectories()).Invoke(Context{Value: `example\`}),
+	)
+}
+
+func TestActionFilesFold(t *testing.T) {
+	t.Setenv("CARAPACE_MATCH", "CASE_INSENSITIVE")
+
+	invoked := ActionFiles().Invoke(Context{Value: "EXAMPLE/MAIN_"})
+	if !invoked.action.meta.Fold {
+		t.Error("expected values to match case insensitive")
+	}
+	if vals := invoked.action.rawValues.FilterPrefixFold("EXAMPLE/MAIN_"); len(vals) != 1 || vals[0].Value != "example/main_test.go" {
+		t.Errorf("expected actual casing to be inserted: %#v", vals)
+	}
+}
+
+func TestActionFilesSymlink(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/file", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("file", dir+"/link"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing", dir+"/broken"); err != nil {
+		t.Fatal(err)
+	}
+
+	vals := ActionFiles().Invoke(Context{Dir: dir, Env: []string{"LS_COLORS=or=01;32"}}).action.rawValues
+	sort.Sort(common.ByValue(vals))
+	if len(vals) != 3 {
+		t.Fatalf("unexpected values: %#v", vals)
+	}
+	if vals[0].Value != "broken" || vals[0].Description != "-> missing" || vals[0].Style != style.Of(style.Green, style.Bold) {
+		t.Errorf("expected orphan style for broken symlink: %#v", vals[0])
+	}
+	if vals[2].Value != "link" || vals[2].Description != "-> file" || vals[2].Style == style.Red {
+		t.Errorf("unexpected symlink: %#v", vals[2])
+	}
+
+	vals = ActionFiles().Invoke(Context{Dir: dir, Env: []string{"LS_COLORS=di=34"}}).action.rawValues // no orphan style
+	sort.Sort(common.ByValue(vals))
+	if vals[0].Value != "broken" || vals[0].Style != style.Red {
+		t.Errorf("expected red as fallback for broken symlink: %#v", vals[0])
+	}
+}
+
+func TestActionFilesMaxEntries(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a1", "a2", "a3", "b1", "b2"} {
+		if err := os.WriteFile(dir+"/"+name, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Setenv("CARAPACE_MAX_ENTRIES", "")
+	invoked := ActionFiles().Invoke(Context{Dir: dir})
+	if len(invoked.action.rawValues) != 5 || !invoked.action.meta.Messages.IsEmpty() {
+		t.Errorf("expected listing to be unlimited by default: %#v", invoked.action.rawValues)
+	}
+
+	t.Setenv("CARAPACE_MAX_ENTRIES", "3")
+	invoked = ActionFiles().Invoke(Context{Dir: dir})
+	if len(invoked.action.rawValues) != 3 || invoked.action.meta.Messages.IsEmpty() {
+		t.Errorf("expected listing to be capped: %#v", invoked.action.rawValues)
+	}
+
+	invoked = ActionFiles().Invoke(Context{Dir: dir, Value: "a"})
+	if len(invoked.action.rawValues) != 3 || !invoked.action.meta.Messages.IsEmpty() {
+		t.Errorf("expected prefix to narrow the listing: %#v", invoked.action.rawValues)
+	}
+}
+
 func TestActionFilesChdir(t *testing.T) {
 	oldWd, _ := os.Getwd()
 
@@ -243,6 +475,36 @@ func TestActionFilesChdir(t *testing.T) {
 	}
 }
 
+func TestChdirRepoRoot(t *testing.T) {
+	repo := t.TempDir()
+	for _, dir := range []string{".git", "module/pkg/sub"} {
+		if err := os.MkdirAll(repo+"/"+dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"README.md", "module/go.mod"} {
+		if err := os.WriteFile(repo+"/"+file, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := Context{Dir: repo + "/module/pkg/sub"}
+	values := ActionFiles().ChdirRepoRoot().Invoke(c).action.rawValues
+	sort.Sort(common.ByValue(values))
+	if len(values) != 2 || values[0].Value != "go.mod" || values[1].Value != "pkg/" {
+		t.Errorf("expected nested module to be found first: %#v", values)
+	}
+
+	values = ActionFiles().ChdirRepoRoot(".git").Invoke(c).action.rawValues
+	sort.Sort(common.ByValue(values))
+	if len(values) != 2 || values[0].Value != "README.md" || values[1].Value != "module/" {
+		t.Errorf("expected repository root: %#v", values)
+	}
+	if messages := ActionFiles().ChdirRepoRoot("nonexistent.marker").Invoke(c).action.meta.Messages; messages.IsEmpty() {
+		t.Error("expected error for missing marker")
+	}
+}
+
 func TestActionMessage(t *testing.T) {
 	expected := ActionValues()
 	expected.meta.Messages.Add("example message")
@@ -263,6 +525,19 @@ func TestActionMessageSuppress(t *testing.T) {
 	)
 }
 
+func TestActionWarning(t *testing.T) {
+	expected := ActionValues("test")
+	expected.meta.Messages.AddLevel(common.LevelWarning, "stale cache")
+
+	assertEqual(t,
+		expected.Invoke(Context{}),
+		Batch(
+			ActionWarning("stale cache"),
+			ActionValues("test"),
+		).ToA().Invoke(Context{}),
+	)
+}
+
 func TestActionExecCommand(t *testing.T) {
 	context := NewContext()
 	context.Value = "docs/"
@@ -276,3 +551,207 @@ func TestActionExecCommand(t *testing.T) {
 		ActionExecCommand("head", "-n1", "go.mod")(func(output []byte) Action { return ActionValues(string(output)) }).Invoke(Context{}),
 	)
 }
+
+func TestExecOpts(t *testing.T) {
+	values := func(output []byte) Action { return ActionValues(strings.Fields(string(output))...) }
+
+	assertEqual(t,
+		ActionValues("from-stdin").Invoke(Context{}),
+		ExecOpts{Stdin: []byte("from-stdin")}.Command("cat")(values).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("from-env", "inherited").Invoke(Context{}),
+		ExecOpts{Env: []string{"EXEC_OPTS=from-env"}}.Command("sh", "-c", "echo $EXEC_OPTS ${PATH:+inherited}")(values).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("_test").Invoke(Context{}),
+		ExecOpts{Dir: "example"}.Command("ls", "-d", "_test")(values).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("partial").Invoke(Context{}),
+		ExecOpts{ExitCodes: []int{3}}.Command("sh", "-c", "echo partial; exit 3")(values).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("fallback").Invoke(Context{}),
+		ExecOpts{Timeout: 10 * time.Millisecond, Fallback: ActionValues("fallback")}.Command("sleep", "1")(values).Invoke(Context{}),
+	)
+
+	start := time.Now()
+	assertEqual(t,
+		ActionValues("fallback").Invoke(Context{}),
+		ExecOpts{Timeout: 10 * time.Millisecond, Fallback: ActionValues("fallback")}.Command("sh", "-c", "sleep 10 & sleep 10")(values).Invoke(Context{}),
+	)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout should not wait for descendants holding stdout [took: %v]", elapsed)
+	}
+}
+
+type echoLocation struct{}
+
+func (l echoLocation) Wrap(name string, args []string, env []string, dir string) (string, []string) {
+	return "echo", append(append(append([]string{"at-location", name}, args...), env...), dir)
+}
+
+func TestExecLocation(t *testing.T) {
+	values := func(output []byte) Action { return ActionValues(strings.Fields(string(output))...) }
+
+	assertEqual(t,
+		ActionValues("at-location", "ls", "-a").Invoke(Context{}),
+		ActionExecCommand("ls", "-a")(values).ExecLocation(echoLocation{}).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("at-location", "ls", "KEY=value", "/remote").Invoke(Context{}),
+		ExecOpts{Env: []string{"KEY=value"}, Dir: "/remote"}.Command("ls")(values).ExecLocation(echoLocation{}).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionMessage("no container").Invoke(Context{}),
+		ActionExecCommand("ls")(values).ExecLocationF(func(tc pkgtraverse.Context) (execlocation.Location, error) {
+			return nil, errors.New("no container")
+		}).Invoke(Context{}),
+	)
+}
+
+func TestUnique(t *testing.T) {
+	assertEqual(t,
+		ActionValues("C").Invoke(Context{}),
+		ActionValues("A", "B", "C").Unique().Invoke(Context{Args: []string{"A"}, Parts: []string{"B"}}),
+	)
+}
+
+func TestDocumentationF(t *testing.T) {
+	assertEqual(t,
+		InvokedAction{Action{rawValues: common.RawValues{
+			{Value: "A", Display: "A", Documentation: "documentation of A"},
+			{Value: "B", Display: "B", Documentation: "documentation of B"},
+		}}},
+		ActionValues("A", "B").DocumentationF(func(s string) string {
+			return "documentation of " + s
+		}).Invoke(Context{}),
+	)
+}
+
+func TestStyleValueF(t *testing.T) {
+	assertEqual(t,
+		InvokedAction{Action{rawValues: common.RawValues{
+			{Value: "one", Display: "one", Description: "deprecated: use two", Style: style.Dim, Tag: "numbers"},
+			{Value: "two", Display: "two", Style: style.Blue, Tag: "numbers"},
+		}}},
+		ActionValuesDescribed(
+			"one", "deprecated: use two",
+			"two", "",
+		).Style(style.Blue).Tag("numbers").StyleValueF(func(v types.RawValue, sc style.Context) string {
+			if strings.HasPrefix(v.Description, "deprecated") && v.Tag == "numbers" {
+				return style.Dim
+			}
+			return v.Style
+		}).Invoke(Context{}),
+	)
+}
+
+func TestSuggest(t *testing.T) {
+	assertEqual(t,
+		InvokedAction{Action{rawValues: common.RawValues{
+			{Value: "json", Display: "json", Default: true},
+			{Value: "yaml", Display: "yaml"},
+		}}},
+		ActionValues("json", "yaml").Suggest("json", "toml").Invoke(Context{}),
+	)
+}
+
+func TestLimit(t *testing.T) {
+	expected := ActionValues("a1", "a2").Invoke(Context{})
+	expected.action.meta.More = true
+	expected.action.meta.Messages.AddLevel(common.LevelInfo, "… 1 more (use narrower prefix)")
+	assertEqual(t,
+		expected,
+		ActionValues("a3", "b1", "a2", "a1").Limit(2).Invoke(Context{Value: "a"}),
+	)
+
+	t.Setenv("CARAPACE_PAGE", "2")
+	assertEqual(t,
+		ActionValues("a3").Invoke(Context{}),
+		ActionValues("a3", "b1", "a2", "a1").Limit(2).Invoke(Context{Value: "a"}),
+	)
+}
+
+func TestDirectoriesFirst(t *testing.T) {
+	expected := ActionValues("a", "b/").Invoke(Context{})
+	expected.action.meta.Order = "directories"
+	assertEqual(t,
+		expected,
+		ActionValues("a", "b/").DirectoriesFirst().Invoke(Context{}),
+	)
+}
+
+func TestSplitQuote(t *testing.T) {
+	a := ActionCallback(func(c Context) Action {
+		return ActionValuesDescribed(
+			"file", c.Quote,
+			"sub dir/", c.Quote,
+		).NoSpace('/')
+	}).Split()
+
+	for value, expected := range map[string][]string{
+		`pos1 `:  {`pos1 file `, `pos1 sub\ dir/`},
+		`pos1 "`: {`pos1 "file" `, `pos1 "sub dir/`},
+		`pos1 '`: {`pos1 'file' `, `pos1 'sub dir/`},
+	} {
+		invoked := a.Invoke(Context{Value: value})
+		sort.Sort(common.ByValue(invoked.action.rawValues))
+
+		actual := make([]string, 0)
+		for _, v := range invoked.action.rawValues {
+			actual = append(actual, v.Value)
+			if expectedQuote := strings.TrimSpace(strings.TrimPrefix(value, "pos1")); v.Description != expectedQuote {
+				t.Errorf("%#v: expected quote %#v [was: %#v]", value, expectedQuote, v.Description)
+			}
+		}
+		assert.Equal(t, strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestSplitAssignments(t *testing.T) {
+	a := ActionCallback(func(c Context) Action {
+		return ActionValues(strings.Join(c.Args, ","))
+	}).Split()
+
+	if actual := a.Invoke(Context{Value: "FOO=bar pos1 pos2 "}).action.rawValues[0].Display; actual != "pos1,pos2" {
+		t.Errorf("expected %#v [was: %#v]", "pos1,pos2", actual)
+	}
+}
+
+func TestSource(t *testing.T) {
+	a := Batch(
+		ActionValues("a"),
+		ActionExecCommand("echo", "b")(func(output []byte) Action {
+			return ActionValues(strings.TrimSpace(string(output)))
+		}),
+	).ToA()
+
+	assertEqual(t,
+		ActionValues("a", "b").Invoke(Context{}),
+		a.source("flag:--example").Invoke(Context{}),
+	)
+
+	t.Setenv("CARAPACE_PROVENANCE", "1")
+	a = Batch(
+		ActionValues("a"),
+		ActionExecCommand("echo", "b")(func(output []byte) Action {
+			return ActionValues(strings.TrimSpace(string(output)))
+		}),
+	).ToA()
+
+	expected := ActionValues("a", "b").Invoke(Context{})
+	expected.action.rawValues[0].Source = "flag:--example > batch[0]"
+	expected.action.rawValues[1].Source = "flag:--example > batch[1] > exec:echo"
+	assertEqual(t,
+		expected,
+		a.source("flag:--example").Invoke(Context{}),
+	)
+}
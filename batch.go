@@ -1,6 +1,10 @@
 package carapace
 
-import "sync"
+import (
+	"fmt"
+	"sync"
+	"time"
+)
 
 type (
 	batch        []Action
@@ -19,7 +23,7 @@ func (b batch) Invoke(c Context) invokedBatch {
 
 	for index, action := range b {
 		localIndex := index
-		localAction := action
+		localAction := action.source(fmt.Sprintf("batch[%v]", index))
 		functions[index] = func() {
 			invokedActions[localIndex] = localAction.Invoke(c)
 		}
@@ -28,6 +32,21 @@ func (b batch) Invoke(c Context) invokedBatch {
 	return invokedActions
 }
 
+// Timeout limits the duration each Action of the batch may take.
+// Slow ones are replaced with a warning so that the results of the others are still returned.
+//
+//	carapace.Batch(
+//		carapace.ActionValues("fast"),
+//		carapace.ActionExecCommand("slow")(...),
+//	).Timeout(1*time.Second).ToA() // ["fast"] and warning "batch[1] timed out after 1s"
+func (b batch) Timeout(d time.Duration) batch {
+	actions := make(batch, len(b))
+	for index, action := range b {
+		actions[index] = action.Timeout(d, ActionWarning("batch[%v] timed out after %v", index, d))
+	}
+	return actions
+}
+
 // ToA converts the batch to an implicitly merged action which is a shortcut for:
 //
 //	ActionCallback(func(c Context) Action {
@@ -51,6 +70,21 @@ func (b invokedBatch) Merge() InvokedAction {
 	}
 }
 
+// MergeWith is like Merge but uses given policy for duplicate values.
+//
+//	carapace.Batch(
+//		carapace.ActionValuesDescribed("B", "from first"),
+//		carapace.ActionValuesDescribed("B", "from second"),
+//	).Invoke(c).MergeWith(carapace.MergeConcatDescriptions) // ["B (from first, from second)"]
+func (b invokedBatch) MergeWith(policy MergePolicy) InvokedAction {
+	switch len(b) {
+	case 0:
+		return ActionValues().Invoke(Context{})
+	default:
+		return b[0].MergeWith(policy, b[1:]...)
+	}
+}
+
 // Parallelize parallelizes the function calls (https://stackoverflow.com/a/44402936)
 func parallelize(functions ...func()) {
 	var waitGroup sync.WaitGroup
@@ -2,8 +2,10 @@ package carapace
 
 import (
 	"testing"
+	"time"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/pkg/style"
 )
 
 func TestBatch(t *testing.T) {
@@ -59,3 +61,51 @@ func TestBatchToA(t *testing.T) {
 	actual := b.ToA().Invoke(Context{})
 	assertEqual(t, expected, actual)
 }
+
+func TestBatchMergeWith(t *testing.T) {
+	b := Batch(
+		ActionValuesDescribed("A", "", "B", "first"),
+		ActionStyledValuesDescribed("A", "second", style.Red, "B", "second", style.Blue),
+		ActionValuesDescribed("B", "first"),
+	)
+
+	assertEqual(t,
+		ActionStyledValuesDescribed("A", "second", style.Red, "B", "first", style.Default).Invoke(Context{}),
+		b.Invoke(Context{}).MergeWith(MergeKeepLast),
+	)
+
+	assertEqual(t,
+		ActionValuesDescribed("A", "", "B", "first").Invoke(Context{}),
+		b.Invoke(Context{}).MergeWith(MergeKeepFirst),
+	)
+
+	assertEqual(t,
+		ActionStyledValuesDescribed("A", "second", style.Red, "B", "first", style.Default).Invoke(Context{}),
+		b.Invoke(Context{}).MergeWith(MergePreferDescribed),
+	)
+
+	assertEqual(t,
+		ActionStyledValuesDescribed("A", "second", style.Red, "B", "first, second", style.Blue).Invoke(Context{}),
+		b.Invoke(Context{}).MergeWith(MergeConcatDescriptions),
+	)
+}
+
+func TestBatchTimeout(t *testing.T) {
+	b := Batch(
+		ActionValues("A", "B"),
+		ActionCallback(func(c Context) Action {
+			time.Sleep(time.Second)
+			return ActionValues("C")
+		}),
+		ActionValues("D"),
+	).Timeout(50 * time.Millisecond)
+
+	expected := ActionValues("A", "B", "D").Invoke(Context{})
+	expected.action.meta.Messages.AddLevel(common.LevelWarning, "batch[1] timed out after 50ms")
+
+	start := time.Now()
+	assertEqual(t, expected, b.Invoke(Context{}).Merge())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("batch should not wait for slow action [took: %v]", elapsed)
+	}
+}
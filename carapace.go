@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/carapace-sh/carapace/internal/shell"
+	"github.com/carapace-sh/carapace/internal/shell/export"
+	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -52,6 +54,7 @@ func (c Carapace) PreInvoke(f func(cmd *cobra.Command, flag *pflag.Flag, action
 
 // PositionalCompletion defines completion for positional arguments using a list of Actions.
 func (c Carapace) PositionalCompletion(action ...Action) {
+	checkPositional(c.cmd, "positional", action)
 	storage.get(c.cmd).positional = action
 }
 
@@ -62,6 +65,7 @@ func (c Carapace) PositionalAnyCompletion(action Action) {
 
 // DashCompletion defines completion for positional arguments after dash (`--`) using a list of Actions.
 func (c Carapace) DashCompletion(action ...Action) {
+	checkPositional(c.cmd, "dash", action)
 	storage.get(c.cmd).dash = action
 }
 
@@ -72,6 +76,10 @@ func (c Carapace) DashAnyCompletion(action Action) {
 
 // FlagCompletion defines completion for flags using a map consisting of name and Action.
 func (c Carapace) FlagCompletion(actions ActionMap) {
+	for name := range actions {
+		checkFlag(c.cmd, name)
+	}
+
 	e := storage.get(c.cmd)
 	e.flagMutex.Lock()
 	defer e.flagMutex.Unlock()
@@ -111,9 +119,55 @@ func (c Carapace) Standalone() {
 	c.cmd.SetHelpCommand(&cobra.Command{Use: "_carapace_help", Hidden: true, Deprecated: "fake help command to prevent default"})
 }
 
+const annotation_singledash = "carapace_singledash"
+
+// SingleDashLonghand allows longhand flags to be passed with a single dash (e.g. `-name value` for Java/Go-style tools).
+// Shorthand flags with attached values (`-Dkey=value`) are still supported, but shorthand series (`-abc`) are not.
+func (c Carapace) SingleDashLonghand() {
+	if c.cmd.Annotations == nil {
+		c.cmd.Annotations = make(map[string]string)
+	}
+	c.cmd.Annotations[annotation_singledash] = "true"
+}
+
+const annotation_icons = "carapace_icons"
+
+// Icons prefixes displays with nerd font icons unless disabled with `CARAPACE_ICONS=0`.
+// Needs to be set on the root command.
+func (c Carapace) Icons() {
+	if c.cmd.Annotations == nil {
+		c.cmd.Annotations = make(map[string]string)
+	}
+	c.cmd.Annotations[annotation_icons] = "true"
+}
+
 // Snippet creates completion script for given shell.
 func (c Carapace) Snippet(name string) (string, error) {
-	return shell.Snippet(c.cmd, name)
+	return shell.Snippet(c.cmd, name, uid.Executable())
+}
+
+// SnippetFromExport creates completion script for given shell from an exported command structure
+// (`_carapace export`) so that it can be generated without invoking the executable (e.g. when packaging).
+// The executable is assumed to be named like the root command.
+//
+//	content, _ := os.ReadFile("example.json")
+//	snippet, err := carapace.SnippetFromExport(content, "bash")
+func SnippetFromExport(content []byte, name string) (string, error) {
+	cmd, err := export.Parse(content)
+	if err != nil {
+		return "", err
+	}
+	return shell.Snippet(cmd, name, cmd.Name())
+}
+
+// Shells returns the names of shells a completion script can be created for (sorted).
+// Useful for a custom `completion <shell>` command or to validate user input.
+//
+//	for _, name := range carapace.Shells() {
+//		snippet, _ := carapace.Gen(cmd).Snippet(name)
+//	}
+func Shells() []string {
+	return shell.Supported()
 }
 
 // IsCallback returns true if current program invocation is a callback.
@@ -121,7 +175,21 @@ func IsCallback() bool {
 	return len(os.Args) > 1 && os.Args[1] == "_carapace"
 }
 
-// Test verifies the configuration (e.g. flag name exists)
+// Validate detects common mistakes in the completion definitions of the command tree
+// (e.g. completion for an unknown flag or gaps in positional completion).
+// Only the tree below the root command is checked, so registrations on commands that
+// weren't added to it are not reported.
+//
+//	func TestCarapace(t *testing.T) {
+//	    for _, err := range carapace.Gen(rootCmd).Validate() {
+//	        t.Error(err)
+//	    }
+//	}
+func (c Carapace) Validate() []error {
+	return storage.validate(c.cmd.Root())
+}
+
+// Test verifies the configuration of all registered commands like Validate (e.g. flag name exists)
 //
 //	func TestCarapace(t *testing.T) {
 //	    carapace.Test(t)
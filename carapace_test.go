@@ -3,10 +3,14 @@ package carapace
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/carapace-sh/carapace/internal/assert"
+	"github.com/carapace-sh/carapace/internal/record"
+	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
@@ -83,6 +87,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"")
 
@@ -92,6 +97,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"pos1", "")
 
@@ -101,6 +107,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"pos1", "pos2", "po")
 
@@ -110,6 +117,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"--multiparts", "")
 
@@ -119,6 +127,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"--multiparts", "fir")
 
@@ -128,6 +137,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{"first"},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"pos1", "--multiparts", "first,seco")
 
@@ -137,6 +147,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"pos")
 
@@ -146,6 +157,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{"first"},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"first:sec")
 
@@ -155,6 +167,7 @@ func TestContext(t *testing.T) {
 		Parts: []string{},
 		Env:   []string{},
 		Dir:   wd(""),
+		Shell: "elvish",
 	},
 		"first:second", "thi")
 }
@@ -198,6 +211,10 @@ func TestSnippet(t *testing.T) {
 		t.Error("fish failed")
 	}
 
+	if s, _ := Gen(cmd).Snippet("ksh"); !strings.Contains(s, "KEYBD") {
+		t.Error("ksh failed")
+	}
+
 	if s, _ := Gen(cmd).Snippet("oil"); !strings.Contains(s, "#!/bin/osh") {
 		t.Error("oil failed")
 	}
@@ -210,6 +227,10 @@ func TestSnippet(t *testing.T) {
 		t.Error("xonsh failed")
 	}
 
+	if s, _ := Gen(cmd).Snippet("ysh"); !strings.Contains(s, "json read") {
+		t.Error("ysh failed")
+	}
+
 	if s, _ := Gen(cmd).Snippet("zsh"); !strings.Contains(s, "compdef") {
 		t.Error("zsh")
 	}
@@ -217,6 +238,14 @@ func TestSnippet(t *testing.T) {
 	if _, err := Gen(cmd).Snippet("unknown"); err == nil {
 		t.Error("zsh")
 	}
+
+	os.Setenv("CARAPACE_FZF", "1")
+	defer os.Unsetenv("CARAPACE_FZF")
+	for _, shell := range []string{"bash", "zsh"} {
+		if s, _ := Gen(cmd).Snippet(shell); !strings.Contains(s, "_carapace fzf") {
+			t.Errorf("%v fzf failed", shell)
+		}
+	}
 }
 
 func TestTest(t *testing.T) {
@@ -230,7 +259,24 @@ func TestComplete(t *testing.T) {
 	cmd.Flags().BoolP("a", "1", false, "")
 	cmd.Flags().BoolP("b", "2", false, "")
 
-	if s, err := complete(cmd, []string{"elvish", "_", "test", "-1"}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"-12","Display":"2","Description":"","CodeSuffix":"","Style":"default"},{"Value":"-1h","Display":"h","Description":"help for test","CodeSuffix":"","Style":"default"}]}` {
+	if s, err := complete(cmd, []string{"elvish", "_", "test", "-1"}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"-12","Display":[{"Text":"2","Style":"default"}],"CodeSuffix":""},{"Value":"-1h","Display":[{"Text":"h","Style":"default"},{"Text":" ","Style":"dim bg-default"},{"Text":"(help for test)","Style":"dim"}],"CodeSuffix":""}]}` {
+		t.Error(s)
+	}
+}
+
+func TestCompleteHighlight(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionValues("first", "second"),
+	)
+
+	style.Carapace.Match = style.Underlined
+	defer func() { style.Carapace.Match = "" }()
+
+	if s, err := complete(cmd, []string{"elvish", "_", "fi"}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"first","Display":[{"Text":"fi","Style":"default underlined"},{"Text":"rst","Style":"default"}],"CodeSuffix":" "}]}` {
 		t.Error(s)
 	}
 }
@@ -246,7 +292,7 @@ func TestCompleteOptarg(t *testing.T) {
 		"opt": ActionValuesDescribed("value", "description"),
 	})
 
-	if s, err := complete(cmd, []string{"elvish", "_", "test", "--opt="}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"--opt=value","Display":"value","Description":"description","CodeSuffix":" ","Style":"default"}]}` {
+	if s, err := complete(cmd, []string{"elvish", "_", "test", "--opt="}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"--opt=value","Display":[{"Text":"value","Style":"default"},{"Text":" ","Style":"dim bg-default"},{"Text":"(description)","Style":"dim"}],"CodeSuffix":" "}]}` {
 		t.Error(s)
 	}
 }
@@ -261,6 +307,54 @@ func TestCompleteSnippet(t *testing.T) {
 	}
 }
 
+func TestCompleteSnippetCache(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("CARAPACE_SNIPPET_CACHE", "1")
+
+	expected, err := complete(cmd, []string{"bash"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	file, err := snippetFile(cmd, "bash")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if content, err := os.ReadFile(file); err != nil || string(content) != expected {
+		t.Fatalf("snippet should be cached: %v", err)
+	}
+
+	stale := filepath.Dir(file) + "/0000000000000000000000000000000000000000_0000000000000000000000000000000000000000"
+	if err := os.WriteFile(stale, []byte("stale"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := complete(cmd, []string{"zsh"}); err != nil {
+		t.Fatal(err.Error())
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("snippet of previous build should be pruned: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("snippet of current build should be kept: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("cached"), 0600); err != nil {
+		t.Fatal(err.Error())
+	}
+	if s, err := complete(cmd, []string{"bash"}); err != nil || s != "cached" {
+		t.Errorf("cached snippet should be used [was: %#v]", s)
+	}
+
+	t.Setenv("CARAPACE_SNIPPET_CACHE", "0")
+	if s, err := complete(cmd, []string{"bash"}); err != nil || s != expected {
+		t.Errorf("cache should be bypassed [was: %#v]", s)
+	}
+}
+
 func TestCompletePositionalWithSpace(t *testing.T) {
 	cmd := &cobra.Command{
 		Use: "test",
@@ -270,7 +364,561 @@ func TestCompletePositionalWithSpace(t *testing.T) {
 		ActionValues("positional with space"),
 	)
 
-	if s, err := complete(cmd, []string{"elvish", "_", "positional "}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"positional with space","Display":"positional with space","Description":"","CodeSuffix":" ","Style":"default"}]}` {
+	if s, err := complete(cmd, []string{"elvish", "_", "positional "}); err != nil || s != `{"Usage":"","Messages":[],"DescriptionStyle":"dim","Candidates":[{"Value":"positional with space","Display":[{"Text":"positional with space","Style":"default"}],"CodeSuffix":" "}]}` {
+		t.Error(s)
+	}
+}
+
+func TestCompleteMenu(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionValuesDescribed(
+			"first", "first value",
+			"second", "",
+		),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "_", ""}); err != nil || s != "first\tfirst value\nsecond" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteShell(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(c.Shell)
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "_", ""}); err != nil || s != "menu" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteShellEnv(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(c.Getenv("SHELLVAR"), c.Getenv("EXPORTED"), os.Getenv("CARAPACE_SHELL_ENV"))
+		}),
+	)
+
+	t.Setenv("EXPORTED", "exported")
+	t.Setenv("CARAPACE_SHELL_ENV", "SHELLVAR=shell\nEXPORTED=shell\ninvalid")
+	if s, err := complete(cmd, []string{"menu", "_", ""}); err != nil || s != "exported\nshell" {
+		t.Errorf("%q", s)
+	}
+}
+
+func TestCompleteAssignments(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalAnyCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(strings.Join(c.Args, ","))
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "FOO=bar", "BAR=baz", "test", "pos1", "FOO=bar", ""}); err != nil || s != "pos1,FOO=bar" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteWrappers(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalAnyCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(strings.Join(c.Args, ","))
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "FOO=bar", "nohup", "command", "-p", "test", "pos1", "nohup", ""}); err != nil || s != "pos1,nohup" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteWrapperNamedProgram(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "nohup",
+	}
+
+	Gen(cmd).PositionalAnyCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(strings.Join(c.Args, ","))
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "nohup", "pos1", ""}); err != nil || s != "pos1" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteSubstitution(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalAnyCompletion(
+		ActionCallback(func(c Context) Action {
+			return ActionValues(strings.Join(c.Args, ","))
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"menu", "echo", "outer", "$(test", "pos1", ""}); err != nil || s != "pos1" {
+		t.Error(s)
+	}
+
+	if s, err := complete(cmd, []string{"fish", "echo", "(cd", "dir;", "nohup", "test", "pos1", "pos2", ""}); err != nil || s != "pos1,pos2\t" {
+		t.Error(s)
+	}
+
+	if s, err := complete(cmd, []string{"menu", "test", "(cd", "pos2", ""}); err != nil || s != "(cd,pos2" {
+		t.Error(s) // bare parenthesis is only a substitution in fish
+	}
+}
+
+func TestCompleteFlagGroups(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	cmd.Flags().Bool("one", false, "")
+	cmd.Flags().Bool("two", false, "")
+	cmd.Flags().Bool("three", false, "")
+	cmd.MarkFlagsMutuallyExclusive("one", "two")
+	cmd.MarkFlagsRequiredTogether("one", "three")
+
+	s, err := complete(cmd, []string{"export", "test", "--one", "--t"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if strings.Contains(s, `"--two"`) {
+		t.Errorf("mutually exclusive flag should be skipped: %v", s)
+	}
+	if !strings.Contains(s, `"value":"--three","display":"--three","style":"underlined"`) {
+		t.Errorf("flag required together should be highlighted: %v", s)
+	}
+}
+
+func TestCompleteDash(t *testing.T) {
+	t.Setenv("CARAPACE_PLAIN", "1") // stable styles
+
+	for shell, expected := range map[string]string{
+		"bash":       "false\x01-first",
+		"bash-ble":   "-first\t-first\x1c\x1c \x1c",
+		"elvish":     `{"Usage":"","Messages":[],"DescriptionStyle":"default","Candidates":[{"Value":"-first","Display":[{"Text":"-first","Style":"default"}],"CodeSuffix":" "}]}`,
+		"export":     `{"version":"unknown","messages":[],"nospace":"","usage":"","prefix":"-first","values":[{"value":"-first","display":"-first"}]}`,
+		"fish":       "-first\t",
+		"fzf":        "-first\t0\t\x1b[m-first\x1b[0m\t",
+		"ion":        `[{"Value":"-first ","Display":"-first"}]`,
+		"menu":       "-first",
+		"nushell":    `[{"value":"-first ","display":"-first"}]`,
+		"oil":        "-first",
+		"powershell": "[{\"CompletionText\":\"-first\",\"ListItemText\":\"`e[21;22;23;24;25;29m`e[39;49m-first`e[21;22;23;24;25;29;39;49m`e[0m\",\"ResultType\":\"ParameterValue\",\"ToolTip\":\" \",\"NoSpace\":false}]",
+		"tcsh":       "-first",
+		"xonsh":      `[{"Value":"-first ","Display":"-first","Description":"","Style":"bg:default fg:default"}]`,
+		"ysh":        `[{"value":"-first","display":"-first"}]`,
+		"zsh":        "=(#b)(-first)([ ]## -- *)=0==:=(#b)(-first)=0=:=(#b)(-- *)=0=\x01\x01\x01values\x03\x03-first\x03-first \x02\x01",
+	} {
+		for value, args := range map[string][]string{
+			"-first":  {"test", "--", "-f"},
+			"-second": {"test", "--bool", "--", "dash1", "-s"},
+		} {
+			cmd := &cobra.Command{
+				Use: "test",
+			}
+			cmd.Flags().BoolP("bool", "b", false, "")
+
+			Gen(cmd).DashCompletion(
+				ActionValues("-first"),
+				ActionValues("-second"),
+			)
+
+			// flags must not be completed after dash and the argument must not be escaped
+			if s, err := complete(cmd, append([]string{shell}, args...)); err != nil {
+				t.Errorf("%v %#v: %v", shell, args, err.Error())
+			} else if expected := strings.ReplaceAll(expected, "-first", value); s != expected {
+				t.Errorf("%v %#v: expected %#v [was: %#v]", shell, args, expected, s)
+			}
+		}
+	}
+}
+
+func TestCompleteRecord(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	subCmd := &cobra.Command{
+		Use: "sub",
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.AddCommand(subCmd)
+
+	Gen(subCmd).PositionalCompletion(
+		ActionValues("first", "second").Tag("values"),
+	)
+
+	path := filepath.Join(t.TempDir(), "record.jsonl")
+	t.Setenv("CARAPACE_RECORD", path)
+
+	s, err := complete(cmd, []string{"export", "test", "sub", "fi"})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	var entry record.Entry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		t.Fatal(err.Error())
+	}
+	if entry.Shell != "export" || entry.Line != "test sub fi" || entry.Command != "test sub" || strings.Join(entry.Tags, ",") != "values" || entry.Values != 2 || entry.Hash != record.Hash(s) {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+}
+
+func TestCompleteTcsh(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionValuesDescribed(
+			"first", "first value",
+			"second", "",
+			"third/", "",
+		).NoSpace('/'),
+	)
+
+	if s, err := complete(cmd, []string{"tcsh", "_", ""}); err != nil || s != "first_(first_value)\nsecond\nthird/" {
+		t.Error(s)
+	}
+
+	if s, err := complete(cmd, []string{"tcsh", "_", "th"}); err != nil || s != "third/" {
+		t.Error(s)
+	}
+
+	t.Setenv("CARAPACE_TCSH_NODESC", "1")
+	if s, err := complete(cmd, []string{"tcsh", "_", ""}); err != nil || s != "first\nsecond\nthird/" {
+		t.Error(s)
+	}
+}
+
+func TestCompleteFzf(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	Gen(cmd).PositionalCompletion(
+		ActionValues("a").DocumentationF(func(s string) string {
+			return "first\tline\nsecond \\ line"
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"fzf", "test", ""}); err != nil || !strings.HasSuffix(s, "\tfirst\\tline\\nsecond \\\\ line") {
+		t.Errorf("expected escaped documentation as last field [was: %#v, %v]", s, err)
+	}
+}
+
+func TestCompleteZshGroupHeader(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	cmd.AddGroup(&cobra.Group{ID: "main", Title: "Main Commands:"})
+	cmd.AddCommand(&cobra.Command{Use: "sub", GroupID: "main", Run: func(cmd *cobra.Command, args []string) {}})
+	Gen(cmd)
+
+	s, err := complete(cmd, []string{"zsh", "test", ""})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(s, "\003Main Commands\003") {
+		t.Errorf("expected group title as header [was: %#v]", s)
+	}
+}
+
+func TestShells(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	Gen(cmd)
+
+	s, err := complete(cmd, []string{"menu", "test", "_carapace", ""})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	completed := "\n" + s + "\n"
+	for _, name := range Shells() {
+		if !strings.Contains(completed, "\n"+name+"\n") {
+			t.Errorf("expected %#v to be completed [was: %#v]", name, s)
+		}
+	}
+}
+
+func TestSnippetFromExport(t *testing.T) {
+	cmd := &cobra.Command{
+		Use:     "test",
+		Short:   "test command",
+		Aliases: []string{"t"},
+	}
+	cmd.Flags().BoolP("bool", "b", false, "bool flag")
+	cmd.Flags().StringSlice("slice", nil, "slice flag")
+	cmd.Flags().String("optarg", "", "optarg flag")
+	cmd.Flag("optarg").NoOptDefVal = "default"
+	cmd.PersistentFlags().CountP("count", "c", "count flag")
+
+	subCmd := &cobra.Command{
+		Use:   "sub",
+		Short: "subcommand",
+		Run:   func(cmd *cobra.Command, args []string) {},
+	}
+	subCmd.Flags().Int("int", 0, "int flag")
+	cmd.AddCommand(subCmd)
+
+	exported, err := Gen(cmd).Snippet("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err := SnippetFromExport([]byte(exported), "export"); err != nil || s != exported {
+		t.Errorf("expected %v [was: %v]", exported, s)
+	}
+
+	if s, err := SnippetFromExport([]byte(exported), "bash"); err != nil || !strings.Contains(s, "xargs test _carapace bash") {
+		t.Errorf("expected executable to be the root command: %v", s)
+	}
+
+	if _, err := SnippetFromExport([]byte("{"), "bash"); err == nil {
+		t.Error("expected error for invalid export")
+	}
+}
+
+func TestCompleteFish(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		Batch(
+			ActionValuesDescribed("b", "first value").Tag("first"),
+			ActionValues("a", "c").Tag("second"),
+			ActionValues("d*", "e/").Tag("first"),
+		).ToA().NoSpace('*', '/'),
+	)
+
+	if s, err := complete(cmd, []string{"fish", "_", ""}); err != nil || s != "a\t\nb\tfirst value\nc\t\nd*\t\ne/\t" {
+		t.Errorf("%#v", s)
+	}
+
+	if s, err := complete(cmd, []string{"fish", "_", "d"}); err != nil || s != "d*\t" {
+		t.Errorf("%#v", s)
+	}
+
+	t.Setenv("CARAPACE_FISH_NOSORT", "1")
+	if s, err := complete(cmd, []string{"fish", "_", ""}); err != nil || s != "b\tfirst value\nd*\t\ne/\t\na\t\nc\t" {
+		t.Errorf("%#v", s)
+	}
+
+	t.Setenv("CARAPACE_ORDER", "directories")
+	if s, err := complete(cmd, []string{"fish", "_", ""}); err != nil || s != "e/\t\na\t\nb\tfirst value\nc\t\nd*\t" {
+		t.Errorf("%#v", s)
+	}
+}
+
+func TestCompleteYsh(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionValuesDescribed(
+			"first", "first value",
+			"second", "",
+			"third/", "",
+		).NoSpace('/'),
+	)
+
+	if s, err := complete(cmd, []string{"ysh", "_", ""}); err != nil || s != `[{"value":"first","display":"first (first value)","description":"first value"},{"value":"second","display":"second"},{"value":"third/","display":"third/","nospace":true}]` {
+		t.Error(s)
+	}
+}
+
+func TestSingleDashLonghand(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "singledash",
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.Flags().String("name", "", "name flag")
+	cmd.Flags().StringP("define", "D", "", "define flag")
+
+	Gen(cmd).SingleDashLonghand()
+	Gen(cmd).FlagCompletion(ActionMap{
+		"name":   ActionValues("one", "two"),
+		"define": ActionValues("key=value"),
+	})
+
+	action, context := traverse(cmd, []string{"-name", ""})
+	assertEqual(t, ActionValues("one", "two").Usage("name flag").Invoke(context), action.Invoke(context))
+
+	action, context = traverse(cmd, []string{"-name=t"})
+	assertEqual(t, ActionValues("one", "two").Usage("name flag").Invoke(context).Prefix("-name="), action.Invoke(context))
+
+	action, context = traverse(cmd, []string{"-D"})
+	assertEqual(t, ActionValues("key=value").Usage("define flag").Invoke(context).Prefix("-D"), action.Invoke(context))
+
+	action, context = traverse(cmd, []string{"-name", "one", "-"})
+	if values := action.Invoke(context).Retain("-name", "--name").action.rawValues; len(values) != 0 {
+		t.Errorf("should not complete changed flag: %#v", values)
+	}
+	if values := action.Invoke(context).Retain("-define").action.rawValues; len(values) != 1 {
+		t.Errorf("should complete longhand with single dash: %#v", values)
+	}
+}
+
+func TestSelftest(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	t.Setenv("CARAPACE_PLAIN", "") // restore after `--plain`
+	t.Setenv("NO_COLOR", "")
+
+	for _, shellName := range selftestShells {
+		if err := selftest(cmd, shellName); err != nil {
+			t.Errorf("%v: %v", shellName, err.Error())
+		}
+	}
+
+	if err := selftest(cmd, "ion"); err == nil || err.Error() != "empty snippet" {
+		t.Errorf("expected empty snippet error [was: %v]", err)
+	}
+
+	if _, err := exec.LookPath("bash"); err == nil {
+		if err := checkSyntax("bash", "if then"); err == nil || !strings.HasPrefix(err.Error(), "invalid snippet: ") {
+			t.Errorf("expected syntax error [was: %v]", err)
+		}
+	}
+}
+
+func TestCompleteAccessible(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	cmd.Flags().Bool("bool", false, "bool flag")
+
+	t.Setenv("CARAPACE_ACCESSIBLE", "1")
+	t.Setenv("CARAPACE_ICONS", "1")
+	if s, err := complete(cmd, []string{"export", "test", "--b"}); err != nil || !strings.Contains(s, `"display":"[flag] --bool"`) || strings.Contains(s, `"style"`) {
+		t.Error(s)
+	}
+}
+
+func TestCompleteDeprecatedFlags(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	cmd.Flags().Bool("old", false, "old flag")
+	cmd.Flags().Bool("secret", false, "secret flag")
+	cmd.Flags().BoolP("short", "s", false, "short flag")
+	_ = cmd.Flags().MarkDeprecated("old", "use --new instead")
+	_ = cmd.Flags().MarkHidden("secret")
+	_ = cmd.Flags().MarkShorthandDeprecated("short", "use --short instead")
+
+	if s, err := complete(cmd, []string{"export", "test", "-"}); err != nil || strings.Contains(s, "--old") || strings.Contains(s, "--secret") || strings.Contains(s, `"value":"-s"`) {
+		t.Error(s)
+	}
+
+	t.Setenv("CARAPACE_HIDDEN", "1")
+	s, err := complete(cmd, []string{"export", "test", "-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"value":"--old","display":"--old","description":"deprecated: use --new instead","style":"dim italic"`,
+		`"value":"--secret","display":"--secret","description":"secret flag","style":"dim"`,
+		`"value":"-s","display":"-s","description":"deprecated: use --short instead","style":"dim italic"`,
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected %v in %v", expected, s)
+		}
+	}
+}
+
+func TestCompleteOpenQuote(t *testing.T) {
+	var value, quote string
+	cmd := &cobra.Command{
+		Use: "test",
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.Flags().String("message", "", "commit message")
+	Gen(cmd).FlagCompletion(ActionMap{
+		"message": ActionCallback(func(c Context) Action {
+			value, quote = c.Value, c.Quote
+			return ActionValues("fix the bug", "fix the build", "it's fixed", "other")
+		}),
+	})
+
+	for _, tc := range []struct {
+		quote    string
+		value    string
+		expected string
+	}{
+		{`"`, "fix the ", "fix the bug\nfix the build\x02"},
+		{`'`, "fix the b", "fix the bug\nfix the build\x02"},
+		{`'`, "it", "it'\\\\''s fixed\x02"},
+		{"", "fix the b", "fix\\\\ the\\\\ bug \nfix\\\\ the\\\\ build \x02"},
+	} {
+		t.Setenv("CARAPACE_ZSH_QUOTE", tc.quote)
+		if s, err := complete(cmd, []string{"zsh", "test", "--message", tc.value}); err != nil || !strings.HasSuffix(s, "\x03"+tc.expected+"\001") {
+			t.Errorf("%#v: expected suffix %#v [was: %#v]", tc.value, tc.expected, s)
+		}
+		if value != tc.value || quote != tc.quote {
+			t.Errorf("expected value %#v and quote %#v [was: %#v and %#v]", tc.value, tc.quote, value, quote)
+		}
+	}
+
+	t.Setenv("CARAPACE_FISH_QUOTE", `'`)
+	if s, err := complete(cmd, []string{"fish", "test", "--message", "it"}); err != nil || s != "it's fixed\t" { // fish escapes the value on its own
+		t.Errorf("expected unescaped value [was: %#v]", s)
+	}
+	if value != "it" || quote != `'` {
+		t.Errorf("expected value %#v and quote %#v [was: %#v and %#v]", "it", `'`, value, quote)
+	}
+}
+
+func TestCompleteFlagDefault(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.Flags().String("format", "json", "output format")
+
+	Gen(cmd).FlagCompletion(ActionMap{
+		"format": ActionValues("json", "yaml"),
+	})
+
+	if s, err := complete(cmd, []string{"export", "test", "--format", ""}); err != nil || !strings.Contains(s, `{"value":"json","display":"json","default":true}`) || strings.Contains(s, `"style"`) {
+		t.Error(s)
+	}
+
+	if s, err := complete(cmd, []string{"elvish", "test", "--format", ""}); err != nil || strings.Contains(s, `"Style":"underlined"`) {
+		t.Errorf("default style should be opt-in: %v", s)
+	}
+
+	defer func(s string) { style.Carapace.Default = s }(style.Carapace.Default)
+	style.Carapace.Default = style.Underlined
+	if s, err := complete(cmd, []string{"elvish", "test", "--format", ""}); err != nil || !strings.Contains(s, `"Text":"json","Style":"underlined"`) {
 		t.Error(s)
 	}
 }
@@ -5,7 +5,12 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/carapace-sh/carapace/internal/cache"
+	"github.com/carapace-sh/carapace/internal/env"
+	"github.com/carapace-sh/carapace/internal/log"
+	"github.com/carapace-sh/carapace/internal/lsp"
 	"github.com/carapace-sh/carapace/internal/spec"
 	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/spf13/cobra"
@@ -22,8 +27,7 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 		Use:    "_carapace",
 		Hidden: true,
 		Run: func(cmd *cobra.Command, args []string) {
-			LOG.Print(strings.Repeat("-", 80))
-			LOG.Printf("%#v", os.Args)
+			Log().Infof("invoked with %#v", os.Args)
 
 			if len(args) > 2 && strings.HasPrefix(args[2], "_") {
 				cmd.Hidden = false
@@ -40,9 +44,9 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 			}
 
 			if s, err := complete(parentCmd, args); err != nil {
-				fmt.Fprintln(io.MultiWriter(parentCmd.OutOrStderr(), LOG.Writer()), err.Error())
+				fmt.Fprintln(io.MultiWriter(parentCmd.OutOrStderr(), Log().Writer(log.LevelError)), err.Error())
 			} else {
-				fmt.Fprintln(io.MultiWriter(parentCmd.OutOrStdout(), LOG.Writer()), s)
+				fmt.Fprintln(io.MultiWriter(parentCmd.OutOrStdout(), Log().Writer(log.LevelTrace)), s)
 			}
 		},
 		FParseErrWhitelist: cobra.FParseErrWhitelist{
@@ -54,20 +58,7 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 	targetCmd.AddCommand(carapaceCmd)
 
 	Carapace{carapaceCmd}.PositionalCompletion(
-		ActionStyledValues(
-			"bash", "#d35673",
-			"bash-ble", "#c2039a",
-			"elvish", "#ffd6c9",
-			"export", style.Default,
-			"fish", "#7ea8fc",
-			"ion", "#0e5d6d",
-			"nushell", "#29d866",
-			"oil", "#373a36",
-			"powershell", "#e8a16f",
-			"tcsh", "#412f09",
-			"xonsh", "#a8ffa9",
-			"zsh", "#efda53",
-		),
+		actionShells(),
 		ActionValues(targetCmd.Root().Name()),
 	)
 	Carapace{carapaceCmd}.PositionalAnyCompletion(
@@ -89,6 +80,179 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 		}),
 	)
 
+	cacheCmd := &cobra.Command{
+		Use: "cache",
+	}
+	carapaceCmd.AddCommand(cacheCmd)
+
+	cacheListCmd := &cobra.Command{
+		Use:  "list",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			entries, err := cache.Entries()
+			if err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+				return
+			}
+			for _, entry := range entries {
+				fmt.Fprintf(cmd.OutOrStdout(), "%v\t%v\t%v\n", entry.Path, entry.Size, entry.ModTime.Format(time.RFC3339))
+			}
+		},
+	}
+	cacheCmd.AddCommand(cacheListCmd)
+
+	cacheClearCmd := &cobra.Command{
+		Use:  "clear",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cache.Clear(); err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+			}
+		},
+	}
+	cacheCmd.AddCommand(cacheClearCmd)
+
+	cachePathCmd := &cobra.Command{
+		Use:  "path",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dir, err := cache.Dir()
+			if err != nil {
+				fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+				return
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), dir)
+		},
+	}
+	cacheCmd.AddCommand(cachePathCmd)
+
+	configCmd := &cobra.Command{
+		Use: "config",
+	}
+	carapaceCmd.AddCommand(configCmd)
+
+	configGetCmd := &cobra.Command{
+		Use: "get",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				for _, setting := range env.GetSettings() {
+					args = append(args, setting.Name)
+				}
+			}
+			for _, arg := range args {
+				value, err := env.GetSetting(arg)
+				if err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%v=%v\n", arg, value)
+			}
+		},
+	}
+	configCmd.AddCommand(configGetCmd)
+	Carapace{configGetCmd}.PositionalAnyCompletion(
+		actionSettings().FilterArgs(),
+	)
+
+	configSetCmd := &cobra.Command{
+		Use:  "set",
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, arg := range args {
+				if splitted := strings.SplitN(arg, "=", 2); len(splitted) == 2 {
+					if err := env.SetSetting(splitted[0], splitted[1]); err != nil {
+						fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+					}
+				} else {
+					fmt.Fprintf(cmd.ErrOrStderr(), "invalid format: '%v'\n", arg)
+				}
+			}
+		},
+	}
+	configCmd.AddCommand(configSetCmd)
+	Carapace{configSetCmd}.PositionalAnyCompletion(
+		ActionMultiParts("=", func(c Context) Action {
+			switch len(c.Parts) {
+			case 0:
+				return actionSettings().Suffix("=")
+			default:
+				return ActionValues()
+			}
+		}),
+	)
+
+	diagnoseCmd := &cobra.Command{
+		Use: "diagnose",
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, path := range []struct {
+				name string
+				f    func() (string, error)
+			}{
+				{"cache", cache.Dir},
+				{"log", log.File},
+				{"settings", env.SettingsFile},
+			} {
+				value, err := path.f()
+				if err != nil {
+					fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%v=%v\n", path.name, value)
+			}
+		},
+	}
+	carapaceCmd.AddCommand(diagnoseCmd)
+
+	lspCmd := &cobra.Command{
+		Use:  "lsp",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := lsp.Serve(cmd.InOrStdin(), cmd.OutOrStdout(), newLspServer(targetCmd).handle); err != nil {
+				fmt.Fprintln(io.MultiWriter(cmd.ErrOrStderr(), Log().Writer(log.LevelError)), err.Error())
+			}
+		},
+	}
+	carapaceCmd.AddCommand(lspCmd)
+
+	macroCmd := &cobra.Command{
+		Use:  "macro",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, name := range Macros() {
+				fmt.Fprintln(cmd.OutOrStdout(), "$"+name)
+			}
+		},
+	}
+	carapaceCmd.AddCommand(macroCmd)
+
+	selftestCmd := &cobra.Command{
+		Use:          "selftest",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = selftestShells
+			}
+
+			failed := 0
+			for _, shellName := range args {
+				if err := selftest(targetCmd, shellName); err != nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "FAIL %v: %v\n", shellName, err.Error())
+					failed++
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "PASS %v\n", shellName)
+			}
+			if failed > 0 {
+				return fmt.Errorf("%v of %v shells failed", failed, len(args))
+			}
+			return nil
+		},
+	}
+	carapaceCmd.AddCommand(selftestCmd)
+	Carapace{selftestCmd}.PositionalAnyCompletion(
+		ActionValues(selftestShells...).FilterArgs(),
+	)
+
 	specCmd := &cobra.Command{
 		Use: "spec",
 		Run: func(cmd *cobra.Command, args []string) {
@@ -97,6 +261,16 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 	}
 	carapaceCmd.AddCommand(specCmd)
 
+	warmCmd := &cobra.Command{
+		Use: "warm",
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, message := range prefetch.warm(NewContext()) {
+				fmt.Fprintln(cmd.ErrOrStderr(), message)
+			}
+		},
+	}
+	carapaceCmd.AddCommand(warmCmd)
+
 	styleCmd := &cobra.Command{
 		Use:  "style",
 		Args: cobra.ExactArgs(1),
@@ -111,10 +285,10 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 			for _, arg := range args {
 				if splitted := strings.SplitN(arg, "=", 2); len(splitted) == 2 {
 					if err := style.Set(splitted[0], splitted[1]); err != nil {
-						fmt.Fprint(cmd.ErrOrStderr(), err.Error())
+						fmt.Fprintln(cmd.ErrOrStderr(), err.Error())
 					}
 				} else {
-					fmt.Fprintf(cmd.ErrOrStderr(), "invalid format: '%v'", arg)
+					fmt.Fprintf(cmd.ErrOrStderr(), "invalid format: '%v'\n", arg)
 				}
 			}
 		},
@@ -124,3 +298,29 @@ func addCompletionCommand(targetCmd *cobra.Command) {
 		ActionStyleConfig(),
 	)
 }
+
+// shellStyles are the styles of shells completed by actionShells.
+var shellStyles = map[string]string{
+	"bash":       "#d35673",
+	"bash-ble":   "#c2039a",
+	"elvish":     "#ffd6c9",
+	"fish":       "#7ea8fc",
+	"ion":        "#0e5d6d",
+	"ksh":        "#5a7d9a",
+	"nushell":    "#29d866",
+	"oil":        "#373a36",
+	"powershell": "#e8a16f",
+	"tcsh":       "#412f09",
+	"xonsh":      "#a8ffa9",
+	"ysh":        "#373a36",
+	"zsh":        "#efda53",
+}
+
+// actionShells completes the shells a completion script can be created for.
+func actionShells() Action {
+	vals := make([]string, 0)
+	for _, shell := range Shells() {
+		vals = append(vals, shell, shellStyles[shell]) // empty for shells without a style (e.g. export)
+	}
+	return ActionStyledValues(vals...)
+}
@@ -35,7 +35,7 @@ func registerFlagCompletion(cmd *cobra.Command) {
 			return cobraValuesFor(action), cobraDirectiveFor(action)
 		})
 		if err != nil {
-			LOG.Printf("failed to register flag completion func: %v", err.Error())
+			Log().Errorf("failed to register flag completion func: %v", err.Error())
 		}
 	})
 }
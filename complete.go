@@ -1,48 +1,227 @@
 package carapace
 
 import (
+	"crypto/sha1"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/carapace-sh/carapace/internal/cache"
 	"github.com/carapace-sh/carapace/internal/config"
+	"github.com/carapace-sh/carapace/internal/env"
+	"github.com/carapace-sh/carapace/internal/log"
+	"github.com/carapace-sh/carapace/internal/profile"
+	"github.com/carapace-sh/carapace/internal/record"
 	"github.com/carapace-sh/carapace/internal/shell/bash"
+	"github.com/carapace-sh/carapace/internal/shell/fish"
 	"github.com/carapace-sh/carapace/internal/shell/nushell"
+	"github.com/carapace-sh/carapace/internal/shell/zsh"
+	"github.com/carapace-sh/carapace/pkg/cache/key"
 	"github.com/carapace-sh/carapace/pkg/ps"
+	pkgshlex "github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/spf13/cobra"
 )
 
 func complete(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) > 1 && args[1] == "--plain" { // `_carapace {shell} --plain ...`
+		os.Setenv(env.CARAPACE_PLAIN, "1")
+		os.Setenv(env.NO_COLOR, "1") // also prevents terminal capability queries
+		args = append(args[:1], args[2:]...)
+	}
+	if len(args) > 2 && args[0] == "export" && args[1] == "--page" { // `_carapace export --page {n} ...`
+		os.Setenv(env.CARAPACE_PAGE, args[2])
+		args = append(args[:1], args[3:]...)
+	}
+
 	switch len(args) {
 	case 0:
-		return Gen(cmd).Snippet(ps.DetermineShell())
+		return snippet(cmd, ps.DetermineShell())
 	case 1:
-		return Gen(cmd).Snippet(args[0])
+		return snippet(cmd, args[0])
 	default:
 		initHelpCompletion(cmd)
 
-		switch ps.DetermineShell() {
+		shellEnv := env.ShellEnv()
+		os.Unsetenv(env.CARAPACE_SHELL_ENV) // not passed to executed commands
+
+		line := pkgshlex.Join(args[1:])
+		if compline, ok := bash.CompLine(); ok {
+			line = compline // before it is unset by the patch
+		}
+
+		shell := ""
+		if !env.Plain() { // arguments are passed as is by integrators
+			shell = ps.DetermineShell()
+		}
+
+		switch shell {
 		case "nushell":
 			args = nushell.Patch(args) // handle open quotes
-			LOG.Printf("patching args to %#v", args)
+			Log().Debugf("patching args to %#v", args)
 		case "bash": // TODO what about oil and such?
-			LOG.Printf("COMP_LINE is %#v", os.Getenv("COMP_LINE"))
-			LOG.Printf("COMP_POINT is %#v", os.Getenv("COMP_POINT"))
+			Log().Debugf("COMP_LINE is %#v", os.Getenv("COMP_LINE"))
+			Log().Debugf("COMP_POINT is %#v", os.Getenv("COMP_POINT"))
 			var err error
 			args, err = bash.Patch(args) // handle redirects
-			LOG.Printf("patching args to %#v", args)
+			Log().Debugf("patching args to %#v", args)
 			if err != nil {
 				context := NewContext(args...)
+				context.Shell = args[0]
 				if _, ok := err.(bash.RedirectError); ok {
-					LOG.Printf("completing redirect target for %#v", args)
-					return ActionFiles().Invoke(context).value(args[0], args[len(args)-1]), nil
+					Log().Debugf("completing redirect target for %#v", args)
+					return ActionFiles().Invoke(context).value(context, args[0], args[len(args)-1]), nil
 				}
-				return ActionMessage(err.Error()).Invoke(context).value(args[0], args[len(args)-1]), nil
+				return ActionMessage(err.Error()).Invoke(context).value(context, args[0], args[len(args)-1]), nil
 			}
 		}
 
+		args = append(args[:1], pkgshlex.SkipSubstitutions(args[1:], args[0] == "fish")...) // `echo $(example [TAB]`
+		args = append(args[:1], pkgshlex.SkipWrappers(args[1:], cmd.Root().Name())...)      // `FOO=bar nohup example [TAB]`
+
+		var settingsErr error
+		if !env.Plain() {
+			settingsErr = env.LoadSettingsEnv() // needs to happen before traverse as settings like `lenient` affect it
+		}
+		if _, ok := os.LookupEnv(env.CARAPACE_ICONS); !ok && cmd.Root().Annotations[annotation_icons] == "true" {
+			os.Setenv(env.CARAPACE_ICONS, "1")
+		}
 		action, context := traverse(cmd, args[2:])
-		if err := config.Load(); err != nil {
-			action = ActionMessage("failed to load config: " + err.Error())
+		context.Shell = args[0]
+		context.shellEnv = shellEnv
+		switch shell {
+		case "nushell":
+			context.Quote = nushell.Quote()
+		case "bash":
+			context.Quote = bash.Quote()
+		}
+		switch args[0] { // passed by the snippet
+		case "fish":
+			context.Quote = fish.Quote()
+		case "zsh":
+			context.Quote = zsh.Quote()
+		}
+		if catalog := storage.get(cmd.Root()).catalog; catalog != nil {
+			action = action.translate(catalog)
+		}
+		if settingsErr != nil {
+			action = ActionMessage("failed to load settings: %v", settingsErr.Error())
+		}
+		if !env.Plain() { // user styles don't apply
+			if err := config.Load(); err != nil {
+				action = ActionMessage("failed to load config: %v", err.Error())
+			}
+		}
+		invoked := action.Invoke(context)
+		stopProfile := profile.Start(profile.KindSerialize, args[0])
+		output := invoked.value(context, args[0], args[len(args)-1])
+		stopProfile()
+		for _, entry := range profile.Flush() {
+			Log().Infof("profile: %v", entry)
+		}
+		if path := env.Record(); path != "" {
+			recordInvocation(path, line, context, invoked, output)
+		}
+		return output, nil
+	}
+}
+
+// recordInvocation appends a transcript entry for the current invocation (`CARAPACE_RECORD`).
+func recordInvocation(path, line string, context Context, invoked InvokedAction, output string) {
+	entry := record.Entry{
+		Time:   time.Now().Format(time.RFC3339),
+		ID:     log.Default.ID(),
+		Shell:  context.Shell,
+		Line:   line,
+		Values: len(invoked.action.rawValues),
+		Hash:   record.Hash(output),
+	}
+	if context.cmd != nil {
+		entry.Command = context.cmd.CommandPath()
+	}
+
+	tags := make(map[string]bool)
+	for _, value := range invoked.action.rawValues {
+		if value.Tag != "" && !tags[value.Tag] {
+			tags[value.Tag] = true
+			entry.Tags = append(entry.Tags, value.Tag)
+		}
+	}
+	sort.Strings(entry.Tags)
+
+	if err := record.Append(path, entry); err != nil {
+		Log().Errorf("failed to record invocation: %v", err.Error())
+	}
+}
+
+// snippet returns the completion script for given shell.
+// It is cached based on the executable so that repeated shell startups don't need to generate it again.
+func snippet(cmd *cobra.Command, shell string) (string, error) {
+	if !env.SnippetCache() || shell == "export" { // export depends on the environment (e.g. hidden flags)
+		return Gen(cmd).Snippet(shell)
+	}
+
+	file, err := snippetFile(cmd, shell)
+	if err != nil {
+		Log().Errorf("failed to determine snippet cache file: %v", err.Error())
+		return Gen(cmd).Snippet(shell)
+	}
+
+	if content, err := cache.Load(file, -1); err == nil {
+		Log().Debugf("using cached snippet %#v", file)
+		return string(content), nil
+	}
+
+	s, err := Gen(cmd).Snippet(shell)
+	if err == nil {
+		if err := cache.Write(file, []byte(s)); err != nil {
+			Log().Errorf("failed to cache snippet: %v", err.Error())
+		}
+		pruneSnippets(file)
+	}
+	return s, err
+}
+
+// snippetFile returns the cache file for given shell keyed on path, size and modification time of the executable.
+// The name is prefixed with the hash of the executable stats so that entries of previous builds can be pruned.
+func snippetFile(cmd *cobra.Command, shell string) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	stats, err := key.FileStats(executable)()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := cache.CacheDir("snippets")
+	if err != nil {
+		return "", err
+	}
+
+	id, _ := key.String(cmd.Root().Name(), shell, strconv.FormatBool(env.Fzf()))()
+	return fmt.Sprintf("%v/%x_%x", dir, sha1.Sum([]byte(stats)), sha1.Sum([]byte(id))), nil
+}
+
+// pruneSnippets removes cached snippets of previous builds of the executable (those not sharing the stats prefix of given file).
+func pruneSnippets(file string) {
+	dir, name := filepath.Split(file)
+	prefix := strings.SplitN(name, "_", 2)[0] + "_"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		Log().Errorf("failed to prune snippet cache: %v", err.Error())
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				Log().Errorf("failed to prune snippet cache: %v", err.Error())
+			}
 		}
-		return action.Invoke(context).value(args[0], args[len(args)-1]), nil
 	}
 }
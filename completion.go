@@ -0,0 +1,147 @@
+package carapace
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/carapace-sh/carapace/pkg/ps"
+	"github.com/spf13/cobra"
+)
+
+// AddCompletionCommand adds a `completion [shell]` subcommand to the root command (replacing the default one of cobra).
+// It prints the completion script for given shell (detected by parent process name if omitted)
+// and lists how to install it on the current platform in its help.
+// With `--uninstall` it prints how to remove it instead.
+//
+//	carapace.Gen(rootCmd).AddCompletionCommand()
+func (c Carapace) AddCompletionCommand() {
+	root := c.cmd.Root()
+	root.CompletionOptions.DisableDefaultCmd = true
+	for _, subcmd := range root.Commands() {
+		if subcmd.Name() == "completion" {
+			return // keep an explicit one
+		}
+	}
+
+	name := root.Name()
+	var uninstall bool
+	completionCmd := &cobra.Command{
+		Use:   "completion [shell]",
+		Short: "Generate the autocompletion script for the specified shell",
+		Long: fmt.Sprintf(`Generate the autocompletion script for %v for the specified shell.
+The shell is detected by parent process name if omitted.
+
+Install:
+%v
+Remove with: %v completion [shell] --uninstall`, name, completionInstructions(name, runtime.GOOS), name),
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: Shells(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell := ""
+			if len(args) > 0 {
+				shell = args[0]
+			}
+
+			if uninstall {
+				if shell == "" {
+					shell = ps.DetermineShell()
+				}
+				hint, ok := completionHints(name, runtime.GOOS)[shell]
+				if !ok {
+					return fmt.Errorf("no uninstall instructions for shell: %#v", shell)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), hint.uninstall())
+				return nil
+			}
+
+			snippet, err := (Carapace{cmd: root}).Snippet(shell)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), snippet)
+			return nil
+		},
+	}
+	completionCmd.Flags().BoolVar(&uninstall, "uninstall", false, "print how to remove the completion script")
+	root.AddCommand(completionCmd)
+
+	Carapace{completionCmd}.PositionalCompletion(actionShells())
+}
+
+// completionHint describes how the completion script is installed for a shell.
+type completionHint struct {
+	file  string // script is written to this file
+	write string // format writing the output of the command to file (defaults to `%v > %v`)
+	line  string // line added to the init script (loading file if set)
+	rc    string // init script the line is added to
+}
+
+func (h completionHint) install(command string) string {
+	steps := make([]string, 0, 2)
+	if h.file != "" {
+		write := h.write
+		if write == "" {
+			write = "%v > %v"
+		}
+		steps = append(steps, fmt.Sprintf(write, command, h.file))
+	}
+	if h.line != "" {
+		steps = append(steps, fmt.Sprintf("add '%v' to %v", h.line, h.rc))
+	}
+	return strings.Join(steps, " and ")
+}
+
+func (h completionHint) uninstall() string {
+	steps := make([]string, 0, 2)
+	if h.file != "" {
+		steps = append(steps, "rm "+h.file)
+	}
+	if h.line != "" {
+		steps = append(steps, fmt.Sprintf("remove '%v' from %v", h.line, h.rc))
+	}
+	return strings.Join(steps, " and ")
+}
+
+// completionHints returns how the completion script of given command is installed for each shell on given platform.
+func completionHints(name, goos string) map[string]completionHint {
+	bashCompletions := "~/.local/share/bash-completion/completions/" + name
+	elvishRc := "~/.config/elvish/rc.elv"
+	switch goos {
+	case "darwin":
+		bashCompletions = fmt.Sprintf(`"$(brew --prefix)/etc/bash_completion.d/%v"`, name)
+	case "windows":
+		elvishRc = `%AppData%\elvish\rc.elv`
+	}
+
+	return map[string]completionHint{
+		"bash":   {file: bashCompletions},
+		"elvish": {line: fmt.Sprintf("eval (%v completion elvish | slurp)", name), rc: elvishRc},
+		"fish":   {file: fmt.Sprintf("~/.config/fish/completions/%v.fish", name)},
+		"ksh":    {line: fmt.Sprintf(`eval "$(%v completion ksh)"`, name), rc: "~/.kshrc"},
+		"nushell": {
+			file:  fmt.Sprintf("($nu.default-config-dir | path join %v.nu)", name),
+			write: "%v | save --force %v",
+			line:  fmt.Sprintf("source ($nu.default-config-dir | path join %v.nu); $env.config.completions.external.completer = $%v_completer", name, name),
+			rc:    "$nu.config-path",
+		},
+		"oil":        {line: fmt.Sprintf("source <(%v completion oil)", name), rc: "~/.config/oils/oshrc"},
+		"powershell": {line: fmt.Sprintf("%v completion powershell | Out-String | Invoke-Expression", name), rc: "$PROFILE"},
+		"tcsh":       {line: fmt.Sprintf("eval `%v completion tcsh`", name), rc: "~/.tcshrc"},
+		"xonsh":      {line: fmt.Sprintf("exec($(%v completion xonsh))", name), rc: "~/.xonshrc"},
+		"ysh":        {line: fmt.Sprintf("eval $(%v completion ysh)", name), rc: "~/.config/oils/yshrc"},
+		"zsh":        {file: fmt.Sprintf(`"${fpath[1]}/_%v"`, name)},
+	}
+}
+
+// completionInstructions lists the install instructions for each shell.
+func completionInstructions(name, goos string) string {
+	hints := completionHints(name, goos)
+	lines := make([]string, 0, len(hints))
+	for _, shell := range Shells() {
+		if hint, ok := hints[shell]; ok {
+			lines = append(lines, fmt.Sprintf("  %-12v%v", shell, hint.install(fmt.Sprintf("%v completion %v", name, shell))))
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
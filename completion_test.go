@@ -0,0 +1,69 @@
+package carapace
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestAddCompletionCommand(t *testing.T) {
+	execute := func(args ...string) (string, error) {
+		cmd := &cobra.Command{Use: "example", Run: func(cmd *cobra.Command, args []string) {}}
+		Gen(cmd).AddCompletionCommand()
+
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	if s, err := execute("completion", "fish"); err != nil || !strings.Contains(s, "complete -c 'example'") {
+		t.Errorf("expected fish snippet [was: %#v, %v]", s, err)
+	}
+
+	if s, err := execute("completion", "zsh", "--uninstall"); err != nil || s != "rm \"${fpath[1]}/_example\"\n" {
+		t.Errorf("unexpected uninstall instructions: %#v, %v", s, err)
+	}
+
+	if s, err := execute("completion", "powershell", "--uninstall"); err != nil || s != "remove 'example completion powershell | Out-String | Invoke-Expression' from $PROFILE\n" {
+		t.Errorf("unexpected uninstall instructions: %#v, %v", s, err)
+	}
+
+	if s, err := execute("completion", "--help"); err != nil || !strings.Contains(s, "  fish        example completion fish > ~/.config/fish/completions/example.fish\n") {
+		t.Errorf("expected install instructions in help: %v", s)
+	}
+
+	if _, err := execute("completion", "unknown"); err == nil {
+		t.Error("expected error for unknown shell")
+	}
+
+	cmd := &cobra.Command{Use: "example"}
+	Gen(cmd).AddCompletionCommand()
+	if s, err := complete(cmd, []string{"menu", "example", "completion", "zs"}); err != nil || s != "zsh" {
+		t.Errorf("expected shell completion [was: %#v]", s)
+	}
+}
+
+func TestCompletionHints(t *testing.T) {
+	if hint := completionHints("example", "darwin")["bash"]; hint.install("example completion bash") != `example completion bash > "$(brew --prefix)/etc/bash_completion.d/example"` {
+		t.Errorf("unexpected bash hint on darwin: %#v", hint)
+	}
+
+	if hint := completionHints("example", "linux")["nushell"]; hint.install("example completion nushell") != "example completion nushell | save --force ($nu.default-config-dir | path join example.nu) and add 'source ($nu.default-config-dir | path join example.nu); $env.config.completions.external.completer = $example_completer' to $nu.config-path" {
+		t.Errorf("unexpected nushell hint: %#v", hint.install("example completion nushell"))
+	}
+
+	for _, shell := range Shells() {
+		switch shell {
+		case "bash-ble", "export", "ion":
+		default:
+			if _, ok := completionHints("example", "linux")[shell]; !ok {
+				t.Errorf("missing hint for %#v", shell)
+			}
+		}
+	}
+}
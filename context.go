@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/env"
 	"github.com/carapace-sh/carapace/internal/shell/zsh"
+	"github.com/carapace-sh/carapace/pkg/execlocation"
 	"github.com/carapace-sh/carapace/pkg/execlog"
+	pkgshlex "github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/carapace-sh/carapace/pkg/util"
 	"github.com/carapace-sh/carapace/third_party/github.com/drone/envsubst"
 	"github.com/spf13/cobra"
@@ -27,9 +30,18 @@ type Context struct {
 	Env []string
 	// Dir contains the working directory for current context.
 	Dir string
+	// Shell contains the shell values are completed for (e.g. `bash`, `export`).
+	// Only use it when absolutely necessary as it is empty outside of actual completion.
+	Shell string
+	// Quote contains the quote left open in the current word (e.g. `"` for `"some fi`).
+	// Value is already unquoted, the quote is only needed to decide how values get closed.
+	Quote string
 
 	mockedReplies map[string]string
-	cmd           *cobra.Command // needed for ActionCobra
+	dryRun        bool                  // external commands are replaced with empty output and nothing is cached (Lint)
+	cmd           *cobra.Command        // needed for ActionCobra
+	location      execlocation.Location // where external commands are executed (nil for local)
+	shellEnv      []string              // snapshot of shell variables passed by the snippet (not passed to commands)
 }
 
 // NewContext creates a new context for given arguments.
@@ -56,11 +68,21 @@ func NewContext(args ...string) Context {
 }
 
 // LookupEnv retrieves the value of the environment variable named by the key.
+// Shell variables that aren't exported are looked up in the snapshot passed by the snippet.
 func (c Context) LookupEnv(key string) (string, bool) {
+	for _, env := range [][]string{c.Env, c.shellEnv} {
+		if value, ok := lookupEnv(env, key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func lookupEnv(env []string, key string) (string, bool) {
 	prefix := key + "="
-	for i := len(c.Env) - 1; i >= 0; i-- {
-		if env := c.Env[i]; strings.HasPrefix(env, prefix) {
-			return strings.SplitN(env, "=", 2)[1], true
+	for i := len(env) - 1; i >= 0; i-- {
+		if strings.HasPrefix(env[i], prefix) {
+			return strings.SplitN(env[i], "=", 2)[1], true
 		}
 	}
 	return "", false
@@ -77,7 +99,7 @@ func (c *Context) Setenv(key, value string) {
 	if c.Env == nil {
 		c.Env = []string{}
 	}
-	c.Env = append(c.Env, fmt.Sprintf("%v=%v", key, value))
+	c.Env = append(c.Env[:len(c.Env):len(c.Env)], fmt.Sprintf("%v=%v", key, value)) // copy to not affect contexts sharing the slice
 }
 
 // Envsubst replaces ${var} in the string based on environment variables in current context.
@@ -89,12 +111,12 @@ func (c Context) Envsubst(s string) (string, error) {
 // Env and Dir are set using the Context.
 // See exec.Command for most details.
 func (c Context) Command(name string, arg ...string) *execlog.Cmd {
-	if c.mockedReplies != nil {
-		if m, err := json.Marshal(append([]string{name}, arg...)); err == nil {
-			if reply, exists := c.mockedReplies[string(m)]; exists {
-				return execlog.Command("echo", reply) // TODO use mock
-			}
-		}
+	if reply, ok := c.mockedReply(name, arg); ok {
+		return execlog.Command("echo", reply) // TODO use mock
+	}
+
+	if c.location != nil {
+		return c.remoteCommand(name, arg, nil, "")
 	}
 
 	cmd := execlog.Command(name, arg...)
@@ -103,6 +125,31 @@ func (c Context) Command(name string, arg ...string) *execlog.Cmd {
 	return cmd
 }
 
+// mockedReply returns the reply mocked by the sandbox for given command line.
+func (c Context) mockedReply(name string, arg []string) (string, bool) {
+	if c.dryRun {
+		return "", true
+	}
+	if c.mockedReplies == nil {
+		return "", false
+	}
+	m, err := json.Marshal(append([]string{name}, arg...))
+	if err != nil {
+		return "", false
+	}
+	reply, exists := c.mockedReplies[string(m)]
+	return reply, exists
+}
+
+// remoteCommand creates a command executed at the location of the context.
+// Environment variables and working directory are passed to the location as the local ones don't apply.
+func (c Context) remoteCommand(name string, arg []string, env []string, dir string) *execlog.Cmd {
+	name, arg = c.location.Wrap(name, arg, env, dir)
+	cmd := execlog.Command(name, arg...)
+	cmd.Env = c.Env // needed by the local client (e.g. `DOCKER_HOST`, `SSH_AUTH_SOCK`)
+	return cmd
+}
+
 func expandHome(s string) (string, error) {
 	if strings.HasPrefix(s, "~") {
 		if zsh.NamedDirectories.Matches(s) {
@@ -124,6 +171,19 @@ func expandHome(s string) (string, error) {
 	return s, nil
 }
 
+// AbsExpanded is like Abs but also expands variables (`$VAR`, `${VAR}`) and a leading `~user`.
+func (c Context) AbsExpanded(path string) (string, error) {
+	path = filepath.ToSlash(pkgshlex.ExpandVariables(path, c.LookupEnv))
+	if prefix := pkgshlex.ExpansionPrefix(path); strings.HasPrefix(prefix, "~") && prefix != "~" && prefix != "~/" && !zsh.NamedDirectories.Matches(path) { // `~user`
+		u, err := user.Lookup(strings.TrimSuffix(strings.TrimPrefix(prefix, "~"), "/"))
+		if err != nil {
+			return "", err
+		}
+		path = strings.Replace(path, strings.TrimSuffix(prefix, "/"), filepath.ToSlash(u.HomeDir), 1)
+	}
+	return c.Abs(path)
+}
+
 // Abs returns an absolute representation of path.
 func (c Context) Abs(path string) (string, error) {
 	path = filepath.ToSlash(path)
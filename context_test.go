@@ -2,6 +2,7 @@ package carapace
 
 import (
 	"os"
+	"os/user"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -68,6 +69,32 @@ func TestContextAbs(t *testing.T) {
 	}
 }
 
+func TestContextAbsExpansion(t *testing.T) {
+	c := Context{Env: []string{"PROJECT=/opt/project"}}
+	for path, expected := range map[string]string{
+		"$PROJECT/file":   "/opt/project/file",
+		"${PROJECT}/dir/": "/opt/project/dir/",
+	} {
+		if actual, err := c.AbsExpanded(path); err != nil || actual != expected {
+			t.Errorf("expected %v [was: %v, %v]", expected, actual, err)
+		}
+		if actual, err := c.Abs(path); err != nil || strings.HasPrefix(actual, "/opt/project") {
+			t.Errorf("expected %v to stay unexpanded [was: %v, %v]", path, actual, err)
+		}
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		t.Skip(err.Error())
+	}
+	if actual, err := c.AbsExpanded("~" + u.Username + "/file"); err != nil || actual != filepath.ToSlash(u.HomeDir)+"/file" {
+		t.Errorf("expected homedir of %v [was: %v, %v]", u.Username, actual, err)
+	}
+	if actual, err := c.Abs("~" + u.Username + "/file"); err != nil || !strings.HasSuffix(actual, "/~"+u.Username+"/file") {
+		t.Errorf("expected ~%v to stay unexpanded [was: %v, %v]", u.Username, actual, err)
+	}
+}
+
 func TestEnv(t *testing.T) {
 	c := Context{}
 	if c.Getenv("example") != "" {
@@ -94,6 +121,16 @@ func TestEnv(t *testing.T) {
 	}
 }
 
+func TestEnvShared(t *testing.T) {
+	c := Context{Env: make([]string, 0, 2)}
+	first, second := c, c
+	first.Setenv("example", "first")
+	second.Setenv("example", "second")
+	if first.Getenv("example") != "first" || second.Getenv("example") != "second" {
+		t.Errorf("contexts should not affect each other: %#v %#v", first.Env, second.Env)
+	}
+}
+
 func TestEnvsubst(t *testing.T) {
 	c := Context{}
 
@@ -3,29 +3,51 @@ package carapace
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/carapace-sh/carapace/internal/cache"
 	"github.com/carapace-sh/carapace/internal/common"
 	"github.com/carapace-sh/carapace/internal/config"
 	"github.com/carapace-sh/carapace/internal/env"
 	"github.com/carapace-sh/carapace/internal/export"
+	"github.com/carapace-sh/carapace/internal/history"
 	"github.com/carapace-sh/carapace/internal/man"
+	"github.com/carapace-sh/carapace/internal/profile"
+	"github.com/carapace-sh/carapace/internal/ssh"
+	"github.com/carapace-sh/carapace/internal/toml"
+	"github.com/carapace-sh/carapace/pkg/cache/key"
+	"github.com/carapace-sh/carapace/pkg/etc"
+	"github.com/carapace-sh/carapace/pkg/execlog"
 	"github.com/carapace-sh/carapace/pkg/match"
+	"github.com/carapace-sh/carapace/pkg/mount"
+	"github.com/carapace-sh/carapace/pkg/ps"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/carapace-sh/carapace/pkg/style"
+	pkgtraverse "github.com/carapace-sh/carapace/pkg/traverse"
 	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/util"
 	"github.com/carapace-sh/carapace/third_party/github.com/acarl005/stripansi"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
 )
 
 // ActionCallback invokes a go function during completion.
 func ActionCallback(callback CompletionCallback) Action {
-	return Action{callback: callback}
+	return Action{callback: callback, location: profile.Caller("github.com/carapace-sh/carapace")}
 }
 
 // ActionExecCommand executes an external command.
@@ -35,19 +57,7 @@ func ActionCallback(callback CompletionCallback) Action {
 //	  return carapace.ActionValues(lines[:len(lines)-1]...)
 //	})
 func ActionExecCommand(name string, arg ...string) func(f func(output []byte) Action) Action {
-	return func(f func(output []byte) Action) Action {
-		return ActionExecCommandE(name, arg...)(func(output []byte, err error) Action {
-			if err != nil {
-				if exitErr, ok := err.(*exec.ExitError); ok {
-					if firstLine := strings.SplitN(string(exitErr.Stderr), "\n", 2)[0]; strings.TrimSpace(firstLine) != "" {
-						err = errors.New(firstLine)
-					}
-				}
-				return ActionMessage(err.Error())
-			}
-			return f(output)
-		})
-	}
+	return ExecOpts{}.Command(name, arg...)
 }
 
 // ActionExecCommandE is like ActionExecCommand but with custom error handling.
@@ -62,20 +72,121 @@ func ActionExecCommand(name string, arg ...string) func(f func(output []byte) Ac
 //		return carapace.ActionValues("success")
 //	})
 func ActionExecCommandE(name string, arg ...string) func(f func(output []byte, err error) Action) Action {
+	return ExecOpts{}.CommandE(name, arg...)
+}
+
+// ExecOpts configures the execution of ActionExecCommand.
+//
+//	carapace.ExecOpts{
+//		Env:       []string{"NO_COLOR=1"},
+//		Timeout:   2 * time.Second,
+//		ExitCodes: []int{1},
+//	}.Command("git", "branch")(func(output []byte) carapace.Action {
+//		lines := strings.Split(string(output), "\n")
+//		return carapace.ActionValues(lines[:len(lines)-1]...)
+//	})
+type ExecOpts struct {
+	Env       []string      // additional environment variables (`key=value`)
+	Dir       string        // working directory (relative to Context.Dir)
+	Stdin     []byte        // payload passed to stdin
+	Timeout   time.Duration // maximum duration before the command is killed (0 for unlimited)
+	Fallback  Action        // action used when the timeout is exceeded (defaults to a message)
+	ExitCodes []int         // non-zero exit codes treated as success
+}
+
+// Command executes an external command.
+func (o ExecOpts) Command(name string, arg ...string) func(f func(output []byte) Action) Action {
+	return func(f func(output []byte) Action) Action {
+		return o.CommandE(name, arg...)(func(output []byte, err error) Action {
+			if err != nil {
+				if exitErr, ok := err.(*exec.ExitError); ok {
+					if firstLine := strings.SplitN(string(exitErr.Stderr), "\n", 2)[0]; strings.TrimSpace(firstLine) != "" {
+						err = errors.New(firstLine)
+					}
+				}
+				return ActionMessage(err.Error())
+			}
+			return f(output)
+		})
+	}
+}
+
+// CommandE is like Command but with custom error handling.
+func (o ExecOpts) CommandE(name string, arg ...string) func(f func(output []byte, err error) Action) Action {
 	return func(f func(output []byte, err error) Action) Action {
 		return ActionCallback(func(c Context) Action {
 			var stdout, stderr bytes.Buffer
 			cmd := c.Command(name, arg...)
+			switch _, mocked := c.mockedReply(name, arg); {
+			case c.location != nil && !mocked:
+				cmd = c.remoteCommand(name, arg, o.Env, o.Dir)
+			default:
+				if cmd.Env == nil && len(o.Env) > 0 {
+					cmd.Env = os.Environ() // nil would inherit the environment, but not once extended
+				}
+				cmd.Env = append(cmd.Env, o.Env...)
+				if o.Dir != "" {
+					dir, err := c.Abs(o.Dir)
+					if err != nil {
+						return ActionMessage(err.Error())
+					}
+					cmd.Dir = dir
+				}
+			}
+			if o.Stdin != nil {
+				cmd.Stdin = bytes.NewReader(o.Stdin)
+			}
 			cmd.Stdout = &stdout
 			cmd.Stderr = &stderr
-			if err := cmd.Run(); err != nil {
+
+			if err := o.run(cmd); err != nil {
+				if _, ok := err.(timeoutError); ok {
+					if o.Fallback.callback == nil && o.Fallback.rawValues == nil {
+						return ActionMessage("timeout exceeded: %v", o.Timeout)
+					}
+					return o.Fallback
+				}
 				if exitErr, ok := err.(*exec.ExitError); ok {
 					exitErr.Stderr = stderr.Bytes() // seems this needs to be set manually due to stdout being collected?
+					for _, code := range o.ExitCodes {
+						if exitErr.ExitCode() == code {
+							return f(stdout.Bytes(), nil)
+						}
+					}
 				}
 				return f(stdout.Bytes(), err)
 			}
 			return f(stdout.Bytes(), nil)
-		})
+		}).source("exec:" + name)
+	}
+}
+
+type timeoutError struct{ time.Duration }
+
+func (t timeoutError) Error() string { return fmt.Sprintf("timeout exceeded: %v", t.Duration) }
+
+func (o ExecOpts) run(cmd *execlog.Cmd) error {
+	if o.Timeout <= 0 {
+		return cmd.Run()
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(o.Timeout):
+		_ = cmd.Process.Kill()
+		select {
+		case <-done:
+		case <-time.After(time.Second): // descendants still holding stdout keep Wait from returning (exec.Cmd.WaitDelay needs go1.20)
+		}
+		return timeoutError{o.Timeout}
 	}
 }
 
@@ -132,36 +243,296 @@ func ActionExecute(cmd *cobra.Command) Action {
 	})
 }
 
-// ActionDirectories completes directories.
-func ActionDirectories() Action {
+// ActionCommandLine completes the arguments as a separate command line (e.g. for `sudo` or `xargs`).
+// Executables are completed for the first word and the remaining ones are delegated to the completion
+// of the executable if it is based on carapace or cobra (files otherwise).
+//
+//	carapace.Gen(sudoCmd).PositionalAnyCompletion(
+//		carapace.ActionCommandLine(),
+//	)
+func ActionCommandLine() Action {
 	return ActionCallback(func(c Context) Action {
-		return actionPath([]string{""}, true).
-			MultiParts("/").
-			StyleF(style.ForPath).
-			UidF(func(s string, uc uid.Context) (*url.URL, error) { // TODO duplicated from ActionFiles
-				abs, err := c.Abs(s)
-				if err != nil {
-					return nil, err
-				}
-				return url.Parse("file://" + abs)
+		if len(c.Args) == 0 {
+			if util.HasPathPrefix(c.Value) {
+				return ActionFiles()
+			}
+			return ActionExecutables()
+		}
+
+		path, err := exec.LookPath(c.Args[0])
+		if err != nil {
+			return ActionFiles()
+		}
+
+		args := c.Args[1:]
+		switch completionBridge(path) {
+		case "carapace":
+			return ActionExecCommand(path, append(append([]string{"_carapace", "export", ""}, args...), c.Value)...)(func(output []byte) Action {
+				return ActionImport(output)
 			})
-	}).Tag("directories")
+		case "cobra":
+			return ActionExecCommand(path, append(append([]string{"__complete"}, args...), c.Value)...)(actionCobraComplete)
+		default:
+			return ActionFiles()
+		}
+	})
+}
+
+// ActionDirectories completes directories.
+func ActionDirectories() Action {
+	return PathOpts{}.Directories()
 }
 
 // ActionFiles completes files with optional suffix filtering.
 func ActionFiles(suffix ...string) Action {
+	return PathOpts{}.Files(suffix...)
+}
+
+// PathOpts configures the traversal of ActionFiles and ActionDirectories.
+// Dotfiles are only included when the value starts with `.` unless
+// overridden by the user with `CARAPACE_DOTFILES` (always, never).
+//
+//	carapace.PathOpts{Depth: 2, Dotfiles: "never", Patterns: []string{"*.go"}}.Files()
+type PathOpts struct {
+	Depth            int      // maximum amount of path segments below the working directory (0 for unlimited, absolute and `~` paths aren't limited)
+	Dotfiles         string   // `always` or `never` include dotfiles (takes precedence over `CARAPACE_DOTFILES`)
+	Expand           bool     // expand variables and `~user` for the lookup (see Context.AbsExpanded)
+	NoFollowSymlinks bool     // don't treat symlinks to directories as directories
+	Patterns         []string // glob patterns files must match (see filepath.Match)
+}
+
+// Directories completes directories.
+func (o PathOpts) Directories() Action {
+	return actionWindowsPath(actionDescend(func(c Context) Action {
+		return actionStyledPath(actionPath(o, []string{""}, true).MultiParts("/")).
+			UidF(o.uid(c))
+	})).Tag("directories")
+}
+
+// Files completes files with optional suffix filtering.
+func (o PathOpts) Files(suffix ...string) Action {
+	return actionWindowsPath(actionDescend(func(c Context) Action {
+		return actionStyledPath(actionPath(o, suffix, false).MultiParts("/")).
+			UidF(o.uid(c))
+	})).Tag("files")
+}
+
+func (o PathOpts) uid(c Context) func(s string, uc uid.Context) (*url.URL, error) {
+	return func(s string, uc uid.Context) (*url.URL, error) {
+		abs, err := o.abs(c, s)
+		if err != nil {
+			return nil, err
+		}
+		return url.Parse("file://" + abs)
+	}
+}
+
+func (o PathOpts) abs(c Context, path string) (string, error) {
+	if o.Expand {
+		return c.AbsExpanded(path)
+	}
+	return c.Abs(path)
+}
+
+func (o PathOpts) matches(name string) bool {
+	if len(o.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range o.Patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionSshHosts completes hosts from the ssh config (`~/.ssh/config`).
+//
+//	example (example.com)
+//	other (other.example.com)
+func ActionSshHosts() Action {
 	return ActionCallback(func(c Context) Action {
-		return actionPath(suffix, false).
-			MultiParts("/").
-			StyleF(style.ForPath).
-			UidF(func(s string, uc uid.Context) (*url.URL, error) {
-				abs, err := c.Abs(s)
-				if err != nil {
-					return nil, err
+		path, err := c.Abs("~/.ssh/config")
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return ActionValues()
+			}
+			return ActionMessage(err.Error())
+		}
+		defer file.Close()
+
+		hosts, err := ssh.Hosts(file)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(hosts)*2)
+		for host, hostname := range hosts {
+			vals = append(vals, host, hostname)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("hosts")
+}
+
+// ActionRemoteFiles completes files on a remote host in `[user@]host:path` notation (e.g. for scp and rsync).
+//
+//	example:/tmp/file.txt
+//	user@example:~/.config/
+func ActionRemoteFiles() Action {
+	return ActionMultiPartsN(":", 2, func(c Context) Action {
+		switch len(c.Parts) {
+		case 0:
+			if index := strings.Index(c.Value, "@"); index >= 0 {
+				return ActionSshHosts().Prefix(c.Value[:index+1]).Suffix(":").NoSpace()
+			}
+			return ActionSshHosts().Suffix(":").NoSpace()
+		default:
+			return ActionRemotePath(c.Parts[0])
+		}
+	})
+}
+
+// ActionRemotePath completes paths on given host (`[user@]host`) by listing the remote directory with `ssh host ls`.
+// Connections are shared using ssh multiplexing and listings are cached for a short duration.
+//
+//	carapace.ActionMultiPartsN(":", 2, func(c carapace.Context) carapace.Action {
+//		switch len(c.Parts) {
+//		case 0:
+//			return carapace.ActionSshHosts().Suffix(":").NoSpace()
+//		default:
+//			return carapace.ActionRemotePath(c.Parts[0])
+//		}
+//	})
+func ActionRemotePath(host string) Action {
+	return ActionCallback(func(c Context) Action {
+		if strings.HasPrefix(host, "-") {
+			return ActionValues() // would be passed as option to ssh (e.g. `-oProxyCommand=...`)
+		}
+
+		dir := ""
+		if index := strings.LastIndex(c.Value, "/"); index >= 0 {
+			dir = c.Value[:index+1]
+		}
+
+		args := []string{"-o", "BatchMode=yes"}
+		if controlDir, err := cache.CacheDir("ssh"); err == nil {
+			args = append(args,
+				"-o", "ControlMaster=auto",
+				"-o", "ControlPath="+filepath.Join(controlDir, "%C"),
+				"-o", "ControlPersist=60",
+			)
+		}
+		args = append(args, "--", host, "ls", "-1", "-a", "-p", "--", remoteQuote(dir))
+
+		return ActionExecCommand("ssh", args...)(func(output []byte) Action {
+			vals := make([]string, 0)
+			for _, line := range strings.Split(string(output), "\n") {
+				switch line {
+				case "", "./", "../":
+				default:
+					vals = append(vals, dir+line)
 				}
-				return url.Parse("file://" + abs)
-			})
-	}).Tag("files")
+			}
+			return ActionValues(vals...)
+		}).Cache(time.Minute, key.String(host, dir))
+	}).MultiParts("/").StyleF(func(s string, sc style.Context) string {
+		if strings.HasSuffix(s, "/") {
+			return style.Of(style.Blue, style.Bold)
+		}
+		return style.ForPathExt(s, sc)
+	}).NoSpace('/').Tag("remote files")
+}
+
+// remoteQuote quotes given path for the remote shell while keeping the home directory expandable.
+func remoteQuote(path string) string {
+	switch {
+	case path == "":
+		return "."
+	case path == "~" || path == "~/":
+		return "~/"
+	case strings.HasPrefix(path, "~/"):
+		return "~/" + shlex.Quote(path[2:])
+	default:
+		return shlex.Quote(path)
+	}
+}
+
+// ActionUrls completes urls for given schemes (default: file, http, https, ssh).
+// The scheme is completed first and the remainder is delegated by scheme:
+// local files for `file`, hosts from the ssh config for `ssh` and urls found in the shell history otherwise.
+//
+//	file:///tmp/file.txt
+//	https://example.com/path
+//	ssh://user@example
+func ActionUrls(schemes ...string) Action {
+	if len(schemes) == 0 {
+		schemes = []string{"file", "http", "https", "ssh"}
+	}
+	known := make(map[string]bool)
+	for _, scheme := range schemes {
+		known[scheme] = true
+	}
+	return ActionMultiPartsN("://", 2, func(c Context) Action {
+		switch len(c.Parts) {
+		case 0:
+			return ActionValues(schemes...).Suffix("://").NoSpace()
+		default:
+			switch scheme := c.Parts[0]; {
+			case !known[scheme]:
+				return ActionValues()
+			case scheme == "file":
+				return ActionFiles()
+			case scheme == "ssh":
+				if index := strings.Index(c.Value, "@"); index >= 0 {
+					return ActionSshHosts().Prefix(c.Value[:index+1])
+				}
+				return ActionSshHosts()
+			default:
+				return actionHistoryUrls(scheme)
+			}
+		}
+	}).NoSpace('/', ':').Tag("urls")
+}
+
+// actionHistoryUrls completes urls with given scheme found in the shell history (without the scheme prefix).
+func actionHistoryUrls(scheme string) Action {
+	return ActionCallback(func(c Context) Action {
+		paths := []string{"~/.bash_history", "~/.zsh_history", "~/.local/share/fish/fish_history"}
+		if histfile := c.Getenv("HISTFILE"); histfile != "" {
+			paths = append([]string{histfile}, paths...)
+		}
+
+		vals := make([]string, 0)
+		for _, path := range paths {
+			abs, err := c.Abs(path)
+			if err != nil {
+				return ActionMessage(err.Error())
+			}
+
+			file, err := os.Open(abs)
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return ActionMessage(err.Error())
+			}
+			urls, err := history.Urls(file, scheme)
+			file.Close()
+			if err != nil {
+				return ActionMessage(err.Error())
+			}
+
+			for _, url := range urls {
+				vals = append(vals, strings.TrimPrefix(url, scheme+"://"))
+			}
+		}
+		return ActionValues(vals...).MultiParts("/")
+	})
 }
 
 // ActionValues completes arbitrary keywords (values).
@@ -207,6 +578,31 @@ func ActionValuesDescribed(values ...string) Action {
 	})
 }
 
+// ActionValuesMultiColumn completes values with additional fields as columns in the description.
+// Shells listing descriptions as column align these (padded to the widest field of the listed values).
+//
+//	carapace.ActionValuesMultiColumn([][]string{
+//		{"1", "root", "init"},
+//		{"1234", "user", "bash"},
+//	})
+func ActionValuesMultiColumn(rows [][]string) Action {
+	return ActionCallback(func(c Context) Action {
+		vals := make([]common.RawValue, 0, len(rows))
+		for _, row := range rows {
+			if len(row) == 0 {
+				continue
+			}
+			vals = append(vals, common.RawValue{
+				Value:       row[0],
+				Display:     row[0],
+				Description: strings.Join(row[1:], "  "),
+				Columns:     row[1:],
+			})
+		}
+		return Action{rawValues: vals}
+	})
+}
+
 // ActionStyledValuesDescribed is like ActionValues but also accepts a style.
 func ActionStyledValuesDescribed(values ...string) Action {
 	return ActionCallback(func(c Context) Action {
@@ -224,12 +620,32 @@ func ActionStyledValuesDescribed(values ...string) Action {
 
 // ActionMessage displays a help messages in places where no completions can be generated.
 func ActionMessage(msg string, args ...interface{}) Action {
+	return actionMessage(common.LevelError, msg, args...)
+}
+
+// ActionWarning displays a warning without preventing values from being inserted.
+//
+//	carapace.Batch(
+//		carapace.ActionValues("cached", "values"),
+//		carapace.ActionWarning("cache is stale, showing old results"),
+//	).ToA()
+func ActionWarning(msg string, args ...interface{}) Action {
+	return actionMessage(common.LevelWarning, msg, args...)
+}
+
+// ActionInfo is like ActionWarning but for informational messages.
+func ActionInfo(msg string, args ...interface{}) Action {
+	return actionMessage(common.LevelInfo, msg, args...)
+}
+
+func actionMessage(level, msg string, args ...interface{}) Action {
 	return ActionCallback(func(c Context) Action {
+		text := c.translate(msg)
 		if len(args) > 0 {
-			msg = fmt.Sprintf(msg, args...)
+			text = fmt.Sprintf(text, args...)
 		}
 		a := ActionValues()
-		a.meta.Messages.Add(stripansi.Strip(msg))
+		a.meta.Messages.AddLevel(level, stripansi.Strip(text))
 		return a
 	})
 }
@@ -286,6 +702,73 @@ func ActionMultiPartsN(sep string, n int, callback func(c Context) Action) Actio
 	})
 }
 
+var templatePlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// ActionMultiPartsTemplate completes the placeholders of given template separately.
+// Literal text between placeholders acts as divider and is added automatically.
+//
+//	carapace.ActionMultiPartsTemplate("{owner}/{repo}@{rev}", func(placeholder string, matches map[string]string) carapace.Action {
+//		switch placeholder {
+//		case "owner":
+//			return carapace.ActionValues("carapace-sh", "rsteube")
+//		case "repo":
+//			return carapace.ActionValues(matches["owner"] + "-example")
+//		default:
+//			return carapace.ActionValues("main", "v1.0.0")
+//		}
+//	})
+func ActionMultiPartsTemplate(template string, f func(placeholder string, matches map[string]string) Action) Action {
+	return ActionCallback(func(c Context) Action {
+		indexes := templatePlaceholder.FindAllStringSubmatchIndex(template, -1)
+		if len(indexes) == 0 {
+			return ActionMessage("template contains no placeholder: %v", template)
+		}
+
+		placeholders := make([]string, len(indexes))
+		literals := make([]string, len(indexes)+1) // literals[i] precedes placeholders[i]
+		start := 0
+		for index, match := range indexes {
+			literals[index] = template[start:match[0]]
+			placeholders[index] = template[match[2]:match[3]]
+			start = match[1]
+			if index > 0 && literals[index] == "" {
+				return ActionMessage("placeholders need to be divided: %v", template)
+			}
+		}
+		literals[len(indexes)] = template[start:]
+
+		if !strings.HasPrefix(c.Value, literals[0]) {
+			if strings.HasPrefix(literals[0], c.Value) {
+				return ActionValues(literals[0]).NoSpace()
+			}
+			return ActionValues()
+		}
+
+		prefix := literals[0]
+		matches := make(map[string]string)
+		c.Parts = []string{}
+		for index, placeholder := range placeholders {
+			rest := strings.TrimPrefix(c.Value, prefix)
+			divider := literals[index+1]
+
+			if segment := strings.SplitN(rest, divider, 2); index < len(placeholders)-1 && len(segment) == 2 {
+				matches[placeholder] = segment[0]
+				c.Parts = append(c.Parts, segment[0])
+				prefix += segment[0] + divider
+				continue
+			}
+
+			c.Value = rest
+			invoked := f(placeholder, matches).Invoke(c).Prefix(prefix).Suffix(divider)
+			if index < len(placeholders)-1 {
+				return invoked.ToA().NoSpace([]rune(divider)[len([]rune(divider))-1])
+			}
+			return invoked.ToA()
+		}
+		return ActionValues() // unreachable
+	})
+}
+
 // ActionStyleConfig completes style configuration
 //
 //	carapace.Value=blue
@@ -442,56 +925,809 @@ func ActionStyles(styles ...string) Action {
 	}).Tag("styles")
 }
 
-// ActionExecutables completes executables either from PATH or given directories
+// ActionRegexSyntax completes building blocks of a regular expression (RE2 syntax).
+// Blocks are appended to the value currently being completed.
 //
-//	nvim
-//	chmod
-func ActionExecutables(dirs ...string) Action {
+//	carapace.Gen(cmd).FlagCompletion(carapace.ActionMap{
+//		"pattern": carapace.ActionRegexSyntax(),
+//	})
+func ActionRegexSyntax() Action {
 	return ActionCallback(func(c Context) Action {
-		if len(dirs) == 0 {
-			dirs = strings.Split(os.Getenv("PATH"), string(os.PathListSeparator))
-		}
-		manDescriptions := man.Descriptions(c.Value) // TODO allow additional descriptions to be registered somewhere for carapace-bin (key, value,...)
-		batch := Batch()
-		for i := len(dirs) - 1; i >= 0; i-- {
-			batch = append(batch, actionDirectoryExecutables(dirs[i], c.Value, manDescriptions))
+		batch := Batch(
+			ActionValuesDescribed(
+				`.`, "any character",
+				`\d`, "digit",
+				`\D`, "not a digit",
+				`\s`, "whitespace",
+				`\S`, "not whitespace",
+				`\w`, "word character",
+				`\W`, "not a word character",
+				`[`, "character class",
+				`[^`, "negated character class",
+				`[[:alnum:]]`, "alphanumeric",
+				`[[:alpha:]]`, "alphabetic",
+				`[[:digit:]]`, "digit",
+				`[[:lower:]]`, "lower case",
+				`[[:punct:]]`, "punctuation",
+				`[[:space:]]`, "whitespace",
+				`[[:upper:]]`, "upper case",
+			).Tag("character classes"),
+			ActionValuesDescribed(
+				`$`, "end of text or line",
+				`\b`, "word boundary",
+				`\B`, "not a word boundary",
+				`\z`, "end of text",
+			).Tag("anchors"),
+			ActionValuesDescribed(
+				`*`, "zero or more",
+				`+`, "one or more",
+				`?`, "zero or one",
+				`{`, "repetition count",
+				`*?`, "zero or more (non-greedy)",
+				`+?`, "one or more (non-greedy)",
+				`??`, "zero or one (non-greedy)",
+			).Tag("repetitions"),
+			ActionValuesDescribed(
+				`(`, "capturing group",
+				`(?:`, "non-capturing group",
+				`(?P<`, "named capturing group",
+				`|`, "alternation",
+			).Tag("groups"),
+		)
+
+		if c.Value == "" { // only at the start of the expression
+			batch = append(batch,
+				ActionValuesDescribed(
+					`^`, "beginning of text or line",
+					`\A`, "beginning of text",
+				).Tag("anchors"),
+				ActionValuesDescribed(
+					`(?i)`, "case-insensitive",
+					`(?m)`, "multi-line mode",
+					`(?s)`, "let . match newline",
+					`(?U)`, "ungreedy",
+				).Tag("flags"),
+			)
 		}
-		return batch.ToA().
-			UidF(func(s string, uc uid.Context) (*url.URL, error) {
-				return &url.URL{Scheme: "cmd", Host: s}, nil
-			})
-	}).Tag("executables")
+		return batch.Invoke(c).Merge().Prefix(c.Value).ToA().NoSpace()
+	})
 }
 
-func actionDirectoryExecutables(dir string, prefix string, manDescriptions map[string]string) Action {
+// ActionJsonPath completes jq-style paths of given JSON document segment by segment.
+//
+//	carapace.ActionJsonPath([]byte(`{"items": [{"name": "first"}]}`)) // .items[0].name
+func ActionJsonPath(doc []byte) Action {
 	return ActionCallback(func(c Context) Action {
-		abs, err := c.Abs(dir)
-		if err != nil {
+		var v interface{}
+		if err := json.Unmarshal(doc, &v); err != nil {
 			return ActionMessage(err.Error())
 		}
 
-		if files, err := os.ReadDir(abs); err == nil {
-			vals := make([]string, 0)
-			for _, f := range files {
-				if match.HasPrefix(f.Name(), prefix) {
-					if info, err := f.Info(); err == nil && !f.IsDir() && isExecAny(info.Mode()) {
-						vals = append(vals, f.Name(), manDescriptions[f.Name()], style.ForPath(abs+"/"+f.Name(), c))
-					}
-				}
-			}
-			return ActionStyledValuesDescribed(vals...).UidF(func(s string, uc uid.Context) (*url.URL, error) {
-				return url.Parse(fmt.Sprintf("file://%v/%v", dir, s)) // TODO trim slash suffix from dir | backslash path possible? (windows)
-			})
-		}
-		return ActionValues()
-	})
+		vals := make([]string, 0)
+		walkJsonPath("", v, func(path, description string) {
+			vals = append(vals, path, description)
+		})
+		return ActionValuesDescribed(vals...).Invoke(c).toMultiPartsA(tokenizeJsonPath, '.')
+	}).Tag("paths")
 }
 
-func isExecAny(mode os.FileMode) bool {
-	return mode&0o111 != 0
+// tokenizeJsonPath splits given path after each `.` outside of quoted keys (`."a.b".c` -> `.`, `"a.b".`, `c`).
+func tokenizeJsonPath(s string) []string {
+	tokens := make([]string, 0)
+	start := 0
+	quoted := false
+	escaped := false
+	for index, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && r == '.':
+			tokens = append(tokens, s[start:index+1])
+			start = index + 1
+		}
+	}
+	return append(tokens, s[start:])
 }
 
-// ActionPositional completes positional arguments for given command ignoring `--` (dash).
+var jsonPathIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func walkJsonPath(path string, v interface{}, f func(path, description string)) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			child := path + "." + key
+			if !jsonPathIdentifier.MatchString(key) {
+				child = fmt.Sprintf("%v.%#v", path, key)
+			}
+			f(child, jsonPathDescription(value))
+			walkJsonPath(child, value, f)
+		}
+	case []interface{}:
+		for index, value := range v {
+			child := fmt.Sprintf("%v[%v]", path, index)
+			if path == "" {
+				child = "." + child
+			}
+			f(child, jsonPathDescription(value))
+			walkJsonPath(child, value, f)
+		}
+	}
+}
+
+func jsonPathDescription(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ActionYamlKeys completes nested key paths of given YAML document (the reader is consumed on invocation).
+//
+//	carapace.ActionCallback(func(c carapace.Context) carapace.Action {
+//		f, err := os.Open("config.yaml")
+//		if err != nil {
+//			return carapace.ActionMessage(err.Error())
+//		}
+//		defer f.Close()
+//		return carapace.ActionYamlKeys(f).Invoke(c).ToA()
+//	})
+func ActionYamlKeys(r io.Reader) Action {
+	return ActionCallback(func(c Context) Action {
+		var v interface{}
+		if err := yaml.NewDecoder(r).Decode(&v); err != nil && err != io.EOF {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0)
+		walkYamlKeys("", v, func(path, description string) {
+			vals = append(vals, path, description)
+		})
+		return ActionValuesDescribed(vals...).MultiParts(".")
+	}).Tag("keys")
+}
+
+func walkYamlKeys(path string, v interface{}, f func(path, description string)) {
+	if m, ok := v.(map[string]interface{}); ok {
+		for key, value := range m {
+			child := key
+			if path != "" {
+				child = path + "." + key
+			}
+
+			switch value := value.(type) {
+			case map[string]interface{}:
+				f(child, "mapping")
+				walkYamlKeys(child, value, f)
+			case []interface{}:
+				f(child, "sequence")
+			case nil:
+				f(child, "null")
+			default:
+				f(child, fmt.Sprint(value))
+			}
+		}
+	}
+}
+
+// ActionTomlKeys completes nested key paths of given TOML document (the reader is consumed on invocation).
+//
+//	carapace.ActionTomlKeys(strings.NewReader("[server]\nhost = \"localhost\""))
+func ActionTomlKeys(r io.Reader) Action {
+	return ActionCallback(func(c Context) Action {
+		keys, err := toml.Keys(r)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(keys)*2)
+		for key, description := range keys {
+			vals = append(vals, key, description)
+		}
+		return ActionValuesDescribed(vals...).MultiParts(".")
+	}).Tag("keys")
+}
+
+type installedKey struct {
+	path   string
+	binary string
+}
+
+var (
+	installed      = make(map[installedKey]bool) // results of the PATH lookups for the current invocation
+	installedMutex sync.Mutex
+)
+
+// ActionIfInstalled invokes given action if binary is found in PATH and fallback otherwise.
+// The lookup is only done once per invocation and PATH of the context.
+//
+//	carapace.ActionIfInstalled("git",
+//		carapace.ActionExecCommand("git", "branch", "--format=%(refname:short)")(func(output []byte) carapace.Action {
+//			lines := strings.Split(string(output), "\n")
+//			return carapace.ActionValues(lines[:len(lines)-1]...)
+//		}),
+//		carapace.ActionValues("main", "master"),
+//	)
+func ActionIfInstalled(binary string, a Action, fallback Action) Action {
+	return ActionCallback(func(c Context) Action {
+		path, ok := c.LookupEnv("PATH")
+		if !ok {
+			path = os.Getenv("PATH")
+		}
+		key := installedKey{path, binary}
+
+		installedMutex.Lock()
+		found, ok := installed[key]
+		if !ok {
+			found = lookPath(path, binary)
+			installed[key] = found
+		}
+		installedMutex.Unlock()
+
+		if found {
+			return a
+		}
+		return fallback
+	})
+}
+
+// lookPath checks whether binary is an executable file in one of the directories of given PATH.
+func lookPath(path, binary string) bool {
+	if path == os.Getenv("PATH") {
+		_, err := execlog.LookPath(binary)
+		return err == nil
+	}
+
+	extensions := []string{""}
+	if runtime.GOOS == "windows" {
+		extensions = append(extensions, strings.Split(strings.ToLower(os.Getenv("PATHEXT")), ";")...)
+	}
+	for _, dir := range filepath.SplitList(path) {
+		for _, extension := range extensions {
+			if info, err := os.Stat(filepath.Join(dir, binary+extension)); err == nil && !info.IsDir() && (runtime.GOOS == "windows" || isExecAny(info.Mode())) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ActionExecutables completes executables either from PATH or given directories
+//
+//	nvim
+//	chmod
+func ActionExecutables(dirs ...string) Action {
+	return ActionCallback(func(c Context) Action {
+		if len(dirs) == 0 {
+			dirs = strings.Split(os.Getenv("PATH"), string(os.PathListSeparator))
+		}
+		manDescriptions := man.Descriptions(c.Value) // TODO allow additional descriptions to be registered somewhere for carapace-bin (key, value,...)
+		batch := Batch()
+		for i := len(dirs) - 1; i >= 0; i-- {
+			batch = append(batch, actionDirectoryExecutables(dirs[i], c.Value, manDescriptions))
+		}
+		return batch.ToA().
+			UidF(func(s string, uc uid.Context) (*url.URL, error) {
+				return uid.New(s), nil
+			})
+	}).Tag("executables")
+}
+
+func actionDirectoryExecutables(dir string, prefix string, manDescriptions map[string]string) Action {
+	return ActionCallback(func(c Context) Action {
+		abs, err := c.Abs(dir)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		if files, err := os.ReadDir(abs); err == nil {
+			m := match.Current()
+			vals := make([]string, 0)
+			for _, f := range files {
+				if m.HasPrefix(f.Name(), prefix) {
+					if info, err := f.Info(); err == nil && !f.IsDir() && isExecAny(info.Mode()) {
+						vals = append(vals, f.Name(), manDescriptions[f.Name()], style.ForPath(abs+"/"+f.Name(), c))
+					}
+				}
+			}
+			return ActionStyledValuesDescribed(vals...).UidF(func(s string, uc uid.Context) (*url.URL, error) {
+				return url.Parse(fmt.Sprintf("file://%v/%v", dir, s)) // TODO trim slash suffix from dir | backslash path possible? (windows)
+			})
+		}
+		return ActionValues()
+	})
+}
+
+func isExecAny(mode os.FileMode) bool {
+	return mode&0o111 != 0
+}
+
+// ActionGoPlatforms completes GOOS/GOARCH pairs supported by the installed go toolchain.
+//
+//	linux/amd64
+//	windows/arm64
+func ActionGoPlatforms() Action {
+	return ActionExecCommand("go", "tool", "dist", "list")(func(output []byte) Action {
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		return ActionValues(lines...)
+	}).Cache(24 * time.Hour).MultiParts("/").Tag("platforms")
+}
+
+var goBuildTagRegex = regexp.MustCompile(`[A-Za-z0-9_.]+`)
+
+// ActionGoBuildTags completes build tags used by files of the current module described by the number of files.
+//
+//	integration (3 files)
+//	windows (12 files)
+func ActionGoBuildTags() Action {
+	return ActionCallback(func(c Context) Action {
+		return ActionCallback(func(c Context) Action {
+			counts := make(map[string]int)
+			err := filepath.WalkDir(c.Dir, func(path string, d os.DirEntry, err error) error {
+				switch {
+				case err != nil:
+					return nil
+				case d.IsDir():
+					if path != c.Dir && (d.Name() == "vendor" || d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+						return filepath.SkipDir
+					}
+				case strings.HasSuffix(d.Name(), ".go"):
+					for tag := range goBuildTags(path) {
+						counts[tag]++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return ActionMessage(err.Error())
+			}
+
+			vals := make([]string, 0, len(counts)*2)
+			for tag, count := range counts {
+				description := "1 file"
+				if count > 1 {
+					description = fmt.Sprintf("%v files", count)
+				}
+				vals = append(vals, tag, description)
+			}
+			return ActionValuesDescribed(vals...)
+		}).Cache(time.Minute, key.String(c.Dir))
+	}).ChdirF(pkgtraverse.Parent("go.mod")).Tag("build tags")
+}
+
+// goBuildTags returns the identifiers of `//go:build` and `// +build` constraints in the header of given file.
+func goBuildTags(path string) map[string]bool {
+	tags := make(map[string]bool)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return tags
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		var expr string
+		switch {
+		case strings.HasPrefix(line, "//go:build "):
+			expr = strings.TrimPrefix(line, "//go:build ")
+		case strings.HasPrefix(line, "// +build "):
+			expr = strings.TrimPrefix(line, "// +build ")
+		case line == "", strings.HasPrefix(line, "//"):
+			continue
+		default:
+			return tags // constraints must precede the package clause
+		}
+		for _, tag := range goBuildTagRegex.FindAllString(expr, -1) {
+			tags[tag] = true
+		}
+	}
+	return tags
+}
+
+// ActionGoPackages completes import paths of packages in the current module described by their documentation.
+//
+//	github.com/carapace-sh/carapace/pkg/style (Package style provide styling functions)
+//	github.com/carapace-sh/carapace/pkg/cache/key
+func ActionGoPackages() Action {
+	return ActionCallback(func(c Context) Action {
+		return ActionExecCommand("go", "list", "-f", "{{.ImportPath}}\t{{.Doc}}", "./...")(func(output []byte) Action {
+			lines := strings.Split(string(output), "\n")
+			vals := make([]string, 0, len(lines)*2)
+			for _, line := range lines {
+				if splitted := strings.SplitN(line, "\t", 2); len(splitted) == 2 {
+					vals = append(vals, splitted[0], splitted[1])
+				}
+			}
+			return ActionValuesDescribed(vals...)
+		}).Cache(time.Minute, key.String(c.Dir), key.FileStats(filepath.Join(c.Dir, "go.mod")))
+	}).ChdirF(pkgtraverse.Parent("go.mod")).MultiParts("/").Tag("packages")
+}
+
+// ActionGoModuleVersions completes published versions of given module.
+//
+//	v1.2.0
+//	v1.1.3
+func ActionGoModuleVersions(module string) Action {
+	return ActionExecCommand("go", "list", "-m", "-versions", module)(func(output []byte) Action {
+		if fields := strings.Fields(string(output)); len(fields) > 1 {
+			return ActionValues(fields[1:]...)
+		}
+		return ActionValues()
+	}).Cache(time.Hour, key.String(module)).Tag("versions")
+}
+
+// ActionSignals completes signal names of the operating system described by their number.
+// Uses the signals of the etc.Default source (the embedded ones of the target platform by default).
+//
+//	KILL (9)
+//	TERM (15)
+func ActionSignals() Action {
+	return ActionCallback(func(c Context) Action {
+		signals, err := etc.Signals()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(signals)*2)
+		for _, signal := range signals {
+			vals = append(vals, signal.Name, strconv.Itoa(signal.Number))
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("signals")
+}
+
+// ActionUsers completes users described by their id and full name.
+// Uses NetUserEnum on windows, dscl on darwin and `/etc/passwd` or getent otherwise.
+// Falls back to the current user when none of these are available.
+//
+//	root (0)
+//	user (1000 Full Name)
+func ActionUsers() Action {
+	return ActionCallback(func(c Context) Action {
+		users, err := etc.Users()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(users)*3)
+		for _, user := range users {
+			description := user.Uid
+			if user.Description != "" && user.Description != user.Name {
+				description += " " + user.Description
+			}
+
+			s := style.Default
+			switch {
+			case user.Privileged():
+				s = style.Red
+			case user.System():
+				s = style.Dim
+			}
+			vals = append(vals, user.Name, description, s)
+		}
+		return ActionStyledValuesDescribed(vals...)
+	}).Tag("users")
+}
+
+// ActionGroups completes groups described by their id (and comment).
+// Uses NetLocalGroupEnum on windows, dscl on darwin and `/etc/group` or getent otherwise.
+// Falls back to the primary group of the current user when none of these are available.
+//
+//	root (0)
+//	wheel (10)
+func ActionGroups() Action {
+	return ActionCallback(func(c Context) Action {
+		groups, err := etc.Groups()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(groups)*3)
+		for _, group := range groups {
+			description := strings.TrimSpace(group.Gid + " " + group.Description)
+
+			s := style.Default
+			if group.Gid == "0" {
+				s = style.Red
+			}
+			vals = append(vals, group.Name, description, s)
+		}
+		return ActionStyledValuesDescribed(vals...)
+	}).Tag("groups")
+}
+
+// ActionServices completes network services described by their ports.
+// Falls back to well-known services when `/etc/services` is missing.
+//
+//	domain (53/tcp, 53/udp)
+//	ssh (22/tcp)
+func ActionServices() Action {
+	return ActionCallback(func(c Context) Action {
+		services, err := etc.Services()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(services)*2)
+		indexes := make(map[string]int)
+		for _, service := range services {
+			port := fmt.Sprintf("%v/%v", service.Port, service.Protocol)
+			if index, ok := indexes[service.Name]; ok {
+				vals[index+1] += ", " + port
+				continue
+			}
+			indexes[service.Name] = len(vals)
+			vals = append(vals, service.Name, port)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("services")
+}
+
+// ActionPIDs completes ids of running processes described by their command line and styled by state.
+//
+//	1 (/sbin/init)
+//	4242 (nvim main.go)
+func ActionPIDs() Action {
+	return ActionCallback(func(c Context) Action {
+		processes, err := ps.Processes()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(processes)*3)
+		for _, process := range processes {
+			description := process.Cmdline
+			if description == "" {
+				description = process.Executable
+			}
+
+			s := style.Default
+			switch process.State {
+			case "R":
+				s = style.Carapace.KeywordPositive
+			case "T", "t":
+				s = style.Carapace.KeywordAmbiguous
+			case "Z":
+				s = style.Carapace.KeywordNegative
+			}
+			vals = append(vals, strconv.Itoa(process.Pid), description, s)
+		}
+		return ActionStyledValuesDescribed(vals...)
+	}).Tag("processes")
+}
+
+// ActionProcessNames completes unique executable names of running processes.
+//
+//	bash
+//	nvim
+func ActionProcessNames() Action {
+	return ActionCallback(func(c Context) Action {
+		processes, err := ps.Processes()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(processes))
+		for _, process := range processes {
+			vals = append(vals, process.Executable)
+		}
+		return ActionValues(vals...).Unique()
+	}).Tag("process names")
+}
+
+// ActionMounts completes mount points described by filesystem type, device and usage.
+//
+//	/ (ext4 /dev/sda1, 42G free of 256G)
+//	/dev/shm (tmpfs)
+func ActionMounts() Action {
+	return ActionCallback(func(c Context) Action {
+		mounts, err := mount.Mounts()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(mounts)*2)
+		indexes := make(map[string]int)
+		for _, m := range mounts {
+			description := m.Type
+			if m.Device != m.Type {
+				description += " " + m.Device
+			}
+			if m.Size > 0 {
+				description += fmt.Sprintf(", %v free of %v", byteSize(m.Free), byteSize(m.Size))
+			}
+
+			if index, ok := indexes[m.Path]; ok {
+				vals[index+1] = description // last one shadows earlier mounts on the same path
+				continue
+			}
+			indexes[m.Path] = len(vals)
+			vals = append(vals, m.Path, description)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("mounts")
+}
+
+// ActionDevices completes block devices described by size and mount point.
+//
+//	/dev/sda (256G)
+//	/dev/sda1 (256G, mounted on /)
+func ActionDevices() Action {
+	return ActionCallback(func(c Context) Action {
+		devices, err := mount.Devices()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		mountpoints := make(map[string]string)
+		if mounts, err := mount.Mounts(); err == nil {
+			for _, m := range mounts {
+				if _, ok := mountpoints[m.Device]; !ok {
+					mountpoints[m.Device] = m.Path
+				}
+			}
+		}
+
+		vals := make([]string, 0, len(devices)*2)
+		for _, device := range devices {
+			descriptions := make([]string, 0, 2)
+			if device.Size > 0 {
+				descriptions = append(descriptions, byteSize(device.Size))
+			}
+			if path, ok := mountpoints[device.Path]; ok {
+				descriptions = append(descriptions, "mounted on "+path)
+			}
+			vals = append(vals, device.Path, strings.Join(descriptions, ", "))
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("devices")
+}
+
+// ActionFilesystems completes filesystem types supported by the kernel.
+//
+//	ext4
+//	tmpfs (virtual)
+func ActionFilesystems() Action {
+	return ActionCallback(func(c Context) Action {
+		filesystems, err := mount.Filesystems()
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(filesystems)*2)
+		for _, filesystem := range filesystems {
+			description := ""
+			if filesystem.Virtual {
+				description = "virtual"
+			}
+			vals = append(vals, filesystem.Name, description)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("filesystems")
+}
+
+// byteSize formats given size with binary units (e.g. `1.5K`).
+func byteSize(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%vB", size)
+	}
+
+	value, exponent := float64(size)/unit, 0
+	for value >= unit && exponent < 5 {
+		value /= unit
+		exponent++
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%.1f", value), ".0") + string("KMGTPE"[exponent])
+}
+
+var timeNow = time.Now // overridden in tests
+
+// ActionDateTime completes timestamps in given layout (defaults to time.RFC3339) around the current time.
+//
+//	2026-10-15T14:00:00+02:00 (in 1 hour)
+//	2026-10-14T00:00:00+02:00 (yesterday)
+func ActionDateTime(layout string) Action {
+	return ActionCallback(func(c Context) Action {
+		layout := layout // don't modify the captured parameter
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		now := timeNow()
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		hour := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+
+		vals := []string{
+			now.Format(layout), "now",
+			midnight.Format(layout), "today",
+			midnight.AddDate(0, 0, -1).Format(layout), "yesterday",
+			midnight.AddDate(0, 0, 1).Format(layout), "tomorrow",
+			hour.Add(time.Hour).Format(layout), "in 1 hour",
+		}
+		for i := 2; i <= 3; i++ {
+			vals = append(vals, hour.Add(time.Duration(i)*time.Hour).Format(layout), fmt.Sprintf("in %v hours", i))
+		}
+		return ActionValuesDescribed(vals...).Unique()
+	}).Tag("datetimes")
+}
+
+// ActionDate completes the dates of the current month in given layout (defaults to `2006-01-02`) described by their weekday.
+//
+//	2026-10-01 (Thursday)
+//	2026-10-15 (Thursday, today)
+func ActionDate(layout string) Action {
+	return ActionCallback(func(c Context) Action {
+		layout := layout // don't modify the captured parameter
+		if layout == "" {
+			layout = "2006-01-02"
+		}
+
+		now := timeNow()
+		vals := make([]string, 0, 31*2)
+		for date := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()); date.Month() == now.Month(); date = date.AddDate(0, 0, 1) {
+			description := date.Weekday().String()
+			if date.Day() == now.Day() {
+				description += ", today"
+			}
+			vals = append(vals, date.Format(layout), description)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("dates")
+}
+
+var durationUnits = []string{
+	"h", "hours",
+	"m", "minutes",
+	"s", "seconds",
+	"ms", "milliseconds",
+	"us", "microseconds",
+	"ns", "nanoseconds",
+}
+
+var (
+	durationPattern     = regexp.MustCompile(`^((?:\d+(?:\.\d+)?(?:h|ms|us|µs|ns|m|s))*)(\d+(?:\.\d*)?)?$`)
+	durationUnitPattern = regexp.MustCompile(`[a-zµ]*$`)
+)
+
+// ActionDuration completes durations as parsed by time.ParseDuration.
+// A unit suffix is completed after a number (e.g. `90` -> `90s`, `1h30` -> `1h30m`).
+//
+//	30s
+//	1h30m
+func ActionDuration() Action {
+	return ActionMultiParts("", func(c Context) Action {
+		matches := durationPattern.FindStringSubmatch(strings.Join(c.Parts, ""))
+		switch {
+		case matches == nil:
+			return ActionMessage("invalid duration: %#v", strings.Join(c.Parts, ""))
+		case matches[1] == "" && matches[2] == "":
+			return ActionValues("30s", "1m", "5m", "15m", "30m", "1h", "1h30m", "2h", "24h")
+		case matches[2] == "":
+			return ActionValues() // unit already completed
+		}
+
+		units := durationUnits
+		lastUnit := strings.Replace(durationUnitPattern.FindString(matches[1]), "µ", "u", 1)
+		for index := 0; lastUnit != "" && index < len(units); index += 2 {
+			if units[index] == lastUnit {
+				units = units[index+2:] // only units smaller than the ones already used
+				break
+			}
+		}
+		return ActionValuesDescribed(units...)
+	}).Tag("durations")
+}
+
+// ActionPositional completes positional arguments for given command ignoring `--` (dash).
 // TODO: experimental - likely gives issues with preinvoke (does not have the full args)
 //
 //	carapace.Gen(cmd).DashAnyCompletion(
@@ -518,6 +1754,50 @@ func ActionPositional(cmd *cobra.Command) Action {
 	})
 }
 
+// ActionPositionalAnyOrder completes positional arguments which can be given in any order.
+// An action is no longer offered once a previous argument matched one of its values.
+//
+//	carapace.Gen(cmd).PositionalAnyCompletion(
+//		carapace.ActionPositionalAnyOrder(
+//			carapace.ActionValues("start", "stop"),
+//			carapace.ActionValues("fast", "slow"),
+//		),
+//	)
+func ActionPositionalAnyOrder(actions ...Action) Action {
+	return ActionCallback(func(c Context) Action {
+		remaining := append([]Action{}, actions...)
+		for _, arg := range c.Args {
+			argContext := c
+			argContext.Value = arg
+			for index, a := range remaining {
+				if len(a.Invoke(argContext).action.rawValues.Retain(arg)) > 0 {
+					remaining = append(remaining[:index], remaining[index+1:]...)
+					break
+				}
+			}
+		}
+		return Batch(remaining...).ToA()
+	})
+}
+
+// ActionPositionalDestination completes sources followed by a final destination (e.g. `cp SRC... DEST`).
+// As it is unknown which positional argument is the final one the destination is offered in addition once a source was given.
+//
+//	carapace.Gen(cmd).PositionalAnyCompletion(
+//		carapace.ActionPositionalDestination(
+//			carapace.ActionFiles(),
+//			carapace.ActionDirectories(),
+//		),
+//	)
+func ActionPositionalDestination(source, destination Action) Action {
+	return ActionCallback(func(c Context) Action {
+		if len(c.Args) == 0 {
+			return source
+		}
+		return Batch(source, destination).ToA()
+	})
+}
+
 // ActionCommands completes (sub)commands of given command.
 // `Context.Args` is used to traverse the command tree further down. Use `Action.Shift` to avoid this.
 //
@@ -538,6 +1818,7 @@ func ActionCommands(cmd *cobra.Command) Action {
 		}
 
 		batch := Batch()
+		headers := make(map[string]string)
 		for _, subcommand := range cmd.Commands() {
 			if (!subcommand.Hidden || env.Hidden()) && subcommand.Deprecated == "" {
 				group := common.Group{Cmd: subcommand}
@@ -545,14 +1826,18 @@ func ActionCommands(cmd *cobra.Command) Action {
 				for _, alias := range subcommand.Aliases {
 					batch = append(batch, ActionStyledValuesDescribed(alias, subcommand.Short, group.Style()).Tag(group.Tag()))
 				}
+				if title := group.Title(); title != "" {
+					headers[group.Tag()] = title
+				}
 			}
 		}
-		return batch.ToA().UidF(func(s string, uc uid.Context) (*url.URL, error) {
-			uid := uid.Command(cmd)
-			if subCommand, _, err := cmd.Find([]string{s}); err == nil {
-				s = subCommand.Name() // alias -> actual name
+
+		invoked := batch.ToA().UidF(func(s string, uc uid.Context) (*url.URL, error) {
+			if subCommand, _, err := cmd.Find([]string{s}); err == nil && subCommand != cmd {
+				return uid.Command(subCommand), nil // alias -> actual name
 			}
 
+			uid := uid.Command(cmd)
 			switch uid.Path {
 			case "":
 				uid.Path = s
@@ -560,7 +1845,11 @@ func ActionCommands(cmd *cobra.Command) Action {
 				uid.Path = uid.Path + "/" + s
 			}
 			return uid, nil
-		})
+		}).Invoke(c)
+		for tag, header := range headers {
+			invoked.action.meta.Header(tag, header)
+		}
+		return invoked.ToA()
 	})
 }
 
@@ -571,7 +1860,7 @@ func ActionCobra(f func(cmd *cobra.Command, args []string, toComplete string) ([
 		case f == nil:
 			return ActionValues()
 		case c.cmd == nil: // ensure cmd is never nil even if context does not contain one
-			LOG.Print("cmd is nil [ActionCobra]")
+			Log().Errorf("cmd is nil [ActionCobra]")
 			c.cmd = &cobra.Command{Use: "_carapace_actioncobra", Hidden: true, Deprecated: "dummy command for ActionCobra"}
 		}
 
@@ -582,3 +1871,74 @@ func ActionCobra(f func(cmd *cobra.Command, args []string, toComplete string) ([
 		return compDirective(directive).ToA(values...)
 	})
 }
+
+// ActionXPath completes element and attribute steps of given XML document.
+//
+//	carapace.ActionXPath([]byte(`<catalog><book id="1"><title>Go</title></book></catalog>`)) // /catalog/book/@id
+func ActionXPath(doc []byte) Action {
+	return ActionCallback(func(c Context) Action {
+		steps, err := walkXPath(doc)
+		if err != nil {
+			return ActionMessage(err.Error())
+		}
+
+		vals := make([]string, 0, len(steps)*2)
+		for path, description := range steps {
+			vals = append(vals, path, description)
+		}
+		return ActionValuesDescribed(vals...).MultiParts("/")
+	}).Tag("paths")
+}
+
+// walkXPath returns the distinct element and attribute paths of given XML document along with a description.
+func walkXPath(doc []byte) (map[string]string, error) {
+	type element struct {
+		path     string
+		text     strings.Builder
+		children bool
+	}
+
+	steps := make(map[string]string)
+	stack := make([]*element, 0)
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return steps, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1].path
+				stack[len(stack)-1].children = true
+			}
+			e := &element{path: parent + "/" + token.Name.Local}
+			for _, attr := range token.Attr {
+				if _, ok := steps[e.path+"/@"+attr.Name.Local]; !ok {
+					steps[e.path+"/@"+attr.Name.Local] = attr.Value
+				}
+			}
+			stack = append(stack, e)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			description := strings.TrimSpace(e.text.String())
+			if e.children || description == "" {
+				description = "element"
+			}
+			if existing, ok := steps[e.path]; !ok || existing != "element" && description == "element" {
+				steps[e.path] = description
+			}
+		}
+	}
+}
@@ -1,9 +1,19 @@
 package carapace
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/pkg/etc"
+	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
@@ -81,3 +91,653 @@ func TestActionExecCommandEnv(t *testing.T) {
 		return ActionValues()
 	}).Invoke(c)
 }
+
+func TestActionRegexSyntax(t *testing.T) {
+	a := ActionRegexSyntax().Invoke(Context{Value: "foo"})
+	if values := a.Filter("foo$").action.rawValues; len(values) != len(a.action.rawValues)-1 {
+		t.Error("should contain end anchor")
+	}
+	if values := a.Retain("foo(?i)").action.rawValues; len(values) != 0 {
+		t.Error("flags should only be completed at the start")
+	}
+	if !a.action.meta.Nospace.Matches("foo") {
+		t.Error("should not add space suffix")
+	}
+}
+
+func TestActionValuesMultiColumn(t *testing.T) {
+	cmd := &cobra.Command{Use: "test"}
+	Gen(cmd).PositionalCompletion(
+		ActionValuesMultiColumn([][]string{
+			{"1", "root", "init"},
+			{"1234", "user", "/usr/bin/bash"},
+			{"56", "nobody"},
+		}),
+	)
+
+	if s, err := complete(cmd, []string{"zsh", "test", ""}); err != nil || !strings.Contains(s, "root    init") {
+		t.Errorf("expected aligned columns for zsh [was: %#v, %v]", s, err)
+	}
+	if s, err := complete(cmd, []string{"fish", "test", ""}); err != nil || !strings.Contains(s, "1\troot  init\n") {
+		t.Errorf("expected unaligned columns for fish [was: %#v, %v]", s, err)
+	}
+	if s, err := complete(cmd, []string{"export", "test", ""}); err != nil || !strings.Contains(s, `"columns":["user","/usr/bin/bash"]`) {
+		t.Errorf("expected columns in export [was: %#v, %v]", s, err)
+	}
+}
+
+func TestActionMultiPartsTemplate(t *testing.T) {
+	a := ActionMultiPartsTemplate("repo:{owner}/{repo}@{rev}", func(placeholder string, matches map[string]string) Action {
+		switch placeholder {
+		case "owner":
+			return ActionValues("carapace-sh", "rsteube")
+		case "repo":
+			return ActionValues(matches["owner"] + "-example")
+		default:
+			return ActionCallback(func(c Context) Action {
+				return ActionValues(strings.Join(c.Parts, "+"))
+			})
+		}
+	})
+
+	assertEqual(t,
+		ActionValues("repo:").NoSpace().Invoke(Context{}),
+		a.Invoke(Context{Value: "re"}),
+	)
+
+	assertEqual(t,
+		ActionValues("carapace-sh", "rsteube").Invoke(Context{}).Prefix("repo:").Suffix("/").ToA().NoSpace('/').Invoke(Context{}),
+		a.Invoke(Context{Value: "repo:"}),
+	)
+
+	assertEqual(t,
+		ActionValues("rsteube-example").Invoke(Context{}).Prefix("repo:rsteube/").Suffix("@").ToA().NoSpace('@').Invoke(Context{}),
+		a.Invoke(Context{Value: "repo:rsteube/rs"}),
+	)
+
+	assertEqual(t,
+		ActionValues("rsteube+rsteube-example").Invoke(Context{}).Prefix("repo:rsteube/rsteube-example@"),
+		a.Invoke(Context{Value: "repo:rsteube/rsteube-example@"}),
+	)
+
+	assertEqual(t,
+		ActionMessage("placeholders need to be divided: {a}{b}").Invoke(Context{}),
+		ActionMultiPartsTemplate("{a}{b}", nil).Invoke(Context{}),
+	)
+}
+
+func TestActionPositionalAnyOrder(t *testing.T) {
+	a := ActionPositionalAnyOrder(
+		ActionValues("start", "stop"),
+		ActionValues("fast", "slow"),
+		ActionValues("now", "later"),
+	)
+
+	assertEqual(t,
+		ActionValues("start", "stop", "now", "later").Invoke(Context{}),
+		a.Invoke(Context{Args: []string{"slow"}}),
+	)
+
+	assertEqual(t,
+		ActionValues("fast", "slow").Invoke(Context{}),
+		a.Invoke(Context{Args: []string{"later", "stop"}}),
+	)
+
+	assertEqual(t,
+		ActionValues().Invoke(Context{}),
+		a.Invoke(Context{Args: []string{"later", "stop", "fast"}}),
+	)
+}
+
+func TestActionPositionalDestination(t *testing.T) {
+	a := ActionPositionalDestination(
+		ActionValues("src1", "src2"),
+		ActionValues("dest"),
+	)
+
+	assertEqual(t,
+		ActionValues("src1", "src2").Invoke(Context{}),
+		a.Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("src1", "src2", "dest").Invoke(Context{}),
+		a.Invoke(Context{Args: []string{"src1"}}),
+	)
+}
+
+func TestActionJsonPath(t *testing.T) {
+	doc := []byte(`{"items": [{"name": "first"}], "with space": true, "a.b": {"c": 1}}`)
+
+	a := ActionJsonPath(doc).Invoke(Context{Value: ".items[0]."})
+	a.action.rawValues = a.action.rawValues.FilterPrefix(".items[0].")
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: ".items[0].name", Display: "name", Description: "first", Tag: "paths"},
+			},
+		}}.ToA().NoSpace('.').Invoke(Context{}),
+		a,
+	)
+
+	a = ActionJsonPath(doc).Invoke(Context{Value: "."})
+	a.action.rawValues = a.action.rawValues.FilterPrefix(`."`)
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: `."a.b"`, Display: `"a.b"`, Description: "object", Tag: "paths"},
+				{Value: `."a.b".`, Display: `"a.b".`, Tag: "paths"},
+				{Value: `."with space"`, Display: `"with space"`, Description: "true", Tag: "paths"},
+			},
+		}}.ToA().NoSpace('.').Invoke(Context{}),
+		a,
+	)
+
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: `."a.b".c`, Display: "c", Description: "1", Tag: "paths"},
+			},
+		}}.ToA().NoSpace('.').Invoke(Context{}),
+		ActionJsonPath(doc).Invoke(Context{Value: `."a.b".`}),
+	)
+}
+
+func TestActionXPath(t *testing.T) {
+	doc := []byte(`<catalog><book id="1"><title>Go</title></book><book id="2"/></catalog>`)
+
+	a := ActionXPath(doc).Invoke(Context{Value: "/catalog/book/"})
+	a.action.rawValues = a.action.rawValues.FilterPrefix("/catalog/book/")
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: "/catalog/book/@id", Display: "@id", Description: "1", Tag: "paths"},
+				{Value: "/catalog/book/title", Display: "title", Description: "Go", Tag: "paths"},
+			},
+		}}.ToA().NoSpace('/').Invoke(Context{}),
+		a,
+	)
+
+	a = ActionXPath(doc).Invoke(Context{Value: "/"})
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: "/catalog", Display: "catalog", Description: "element", Tag: "paths"},
+				{Value: "/catalog/", Display: "catalog/", Tag: "paths"},
+			},
+		}}.ToA().NoSpace('/').Invoke(Context{}),
+		a,
+	)
+}
+
+func TestActionYamlKeys(t *testing.T) {
+	a := ActionYamlKeys(strings.NewReader("server:\n  host: localhost\n  ports: [80]\n")).Invoke(Context{Value: "server."})
+	a.action.rawValues = a.action.rawValues.FilterPrefix("server.")
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: "server.host", Display: "host", Description: "localhost", Tag: "keys"},
+				{Value: "server.ports", Display: "ports", Description: "sequence", Tag: "keys"},
+			},
+		}}.ToA().NoSpace('.').Invoke(Context{}),
+		a,
+	)
+}
+
+func TestActionTomlKeys(t *testing.T) {
+	a := ActionTomlKeys(strings.NewReader("[server]\nhost = \"localhost\"\n")).Invoke(Context{Value: "server."})
+	a.action.rawValues = a.action.rawValues.FilterPrefix("server.")
+	assertEqual(t,
+		InvokedAction{Action{
+			rawValues: common.RawValues{
+				{Value: "server.host", Display: "host", Description: `"localhost"`, Tag: "keys"},
+			},
+		}}.ToA().NoSpace('.').Invoke(Context{}),
+		a,
+	)
+}
+
+func TestActionRemoteFiles(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(home+"/.ssh", 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(home+"/.ssh/config", []byte("Host example\n    HostName example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(bin+"/ssh", []byte("#!/bin/sh\nprintf './\\n../\\nfile.txt\\nsubdir/\\n'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	invoked := ActionRemoteFiles().Invoke(Context{Value: "user@ex"})
+	if values := invoked.action.rawValues; len(values) != 1 || values[0].Value != "user@example:" || values[0].Description != "example.com" || !invoked.action.meta.Nospace.Matches("user@example:") {
+		t.Errorf("unexpected hosts: %#v", values)
+	}
+
+	invoked = ActionRemoteFiles().Invoke(Context{Value: "example:/tmp/"})
+	values := invoked.action.rawValues
+	sort.Sort(common.ByValue(values))
+	if len(values) != 2 || values[0].Value != "example:/tmp/file.txt" || values[1].Value != "example:/tmp/subdir/" || values[1].Style != style.Of(style.Blue, style.Bold) {
+		t.Errorf("unexpected remote files: %#v", values)
+	}
+}
+
+func TestActionUrls(t *testing.T) {
+	home := t.TempDir()
+	if err := os.MkdirAll(home+"/.ssh", 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(home+"/.ssh/config", []byte("Host example\n    HostName example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(home+"/.bash_history", []byte("curl https://example.com/api/v1\ncurl http://other.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("HISTFILE", "")
+
+	invoked := ActionUrls().Invoke(Context{Value: "ht"})
+	values := invoked.action.rawValues.FilterPrefix("ht")
+	sort.Sort(common.ByValue(values))
+	if len(values) != 2 || values[0].Value != "http://" || values[1].Value != "https://" || !invoked.action.meta.Nospace.Matches("https://") {
+		t.Errorf("unexpected schemes: %#v", values)
+	}
+
+	invoked = ActionUrls().Invoke(Context{Value: "https://ex"})
+	if values := invoked.action.rawValues.FilterPrefix("https://ex"); len(values) != 1 || values[0].Value != "https://example.com/" || !invoked.action.meta.Nospace.Matches("https://example.com/") {
+		t.Errorf("unexpected history urls: %#v", values)
+	}
+
+	invoked = ActionUrls().Invoke(Context{Value: "ssh://user@ex"})
+	if values := invoked.action.rawValues.FilterPrefix("ssh://user@ex"); len(values) != 1 || values[0].Value != "ssh://user@example" || values[0].Description != "example.com" {
+		t.Errorf("unexpected hosts: %#v", values)
+	}
+
+	if values := ActionUrls("ssh").Invoke(Context{Value: "https://ex"}).action.rawValues; len(values) != 0 {
+		t.Errorf("expected no values for unknown scheme: %#v", values)
+	}
+}
+
+func TestActionIfInstalled(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(bin+"/carapace-installed", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	a := ActionIfInstalled("carapace-installed", ActionValues("installed"), ActionValues("fallback"))
+	if values := a.Invoke(Context{}).action.rawValues; len(values) != 1 || values[0].Value != "installed" {
+		t.Errorf("expected action: %#v", values)
+	}
+
+	a = ActionIfInstalled("carapace-missing", ActionValues("installed"), ActionValues("fallback"))
+	if values := a.Invoke(Context{}).action.rawValues; len(values) != 1 || values[0].Value != "fallback" {
+		t.Errorf("expected fallback: %#v", values)
+	}
+
+	if err := os.Remove(bin + "/carapace-installed"); err != nil {
+		t.Fatal(err)
+	}
+	a = ActionIfInstalled("carapace-installed", ActionValues("installed"), ActionValues("fallback"))
+	if values := a.Invoke(Context{}).action.rawValues; len(values) != 1 || values[0].Value != "installed" {
+		t.Errorf("expected lookup to be cached: %#v", values)
+	}
+
+	other := t.TempDir()
+	if err := os.WriteFile(other+"/carapace-missing", []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	a = ActionIfInstalled("carapace-missing", ActionValues("installed"), ActionValues("fallback"))
+	if values := a.Invoke(Context{Env: []string{"PATH=" + other}}).action.rawValues; len(values) != 1 || values[0].Value != "installed" {
+		t.Errorf("expected lookup in PATH of context: %#v", values)
+	}
+}
+
+func TestActionSignals(t *testing.T) {
+	invoked := ActionSignals().Invoke(Context{})
+	if values := invoked.action.rawValues.Retain("KILL"); len(values) != 1 || values[0].Description != "9" {
+		t.Errorf("expected KILL (9): %#v", values)
+	}
+}
+
+func TestActionPIDs(t *testing.T) {
+	invoked := ActionPIDs().Invoke(Context{})
+	if values := invoked.action.rawValues.Retain(strconv.Itoa(os.Getpid())); len(values) != 1 || values[0].Description == "" {
+		t.Errorf("expected current process: %#v", values)
+	}
+}
+
+func TestActionMounts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("needs /proc")
+	}
+	invoked := ActionMounts().Invoke(Context{})
+	if values := invoked.action.rawValues.Retain("/"); len(values) != 1 || values[0].Description == "" {
+		t.Errorf("expected root mount: %#v", values)
+	}
+}
+
+func TestActionCommandLine(t *testing.T) {
+	if tag := ActionCommandLine().Invoke(Context{Value: "./"}).action.rawValues.Retain("./README.md"); len(tag) != 1 || tag[0].Tag != "files" {
+		t.Errorf("expected files for path prefix: %#v", tag)
+	}
+
+	if vals := ActionCommandLine().Invoke(Context{Value: "s"}).action.rawValues.Retain("sh"); len(vals) != 1 || vals[0].Tag != "executables" {
+		t.Errorf("expected executables for first word: %#v", vals)
+	}
+
+	if vals := ActionCommandLine().Invoke(Context{Args: []string{"sh"}}).action.rawValues.Retain("README.md"); len(vals) != 1 {
+		t.Errorf("expected files for executable without completion: %#v", vals)
+	}
+}
+
+func TestCompletionBridge(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bridge := completionBridge(executable); bridge != "carapace" {
+		t.Errorf("expected carapace bridge for test binary [was: %#v]", bridge)
+	}
+
+	if path, err := exec.LookPath("sh"); err == nil {
+		if bridge := completionBridge(path); bridge != "" {
+			t.Errorf("expected no bridge for sh [was: %#v]", bridge)
+		}
+	}
+}
+
+func TestActionCobraComplete(t *testing.T) {
+	assertEqual(t,
+		ActionValuesDescribed("a", "first", "b", "").NoSpace().Invoke(Context{}),
+		actionCobraComplete([]byte("a\tfirst\nb\n_activeHelp_ some help\n:6\n")).Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionMessage("missing cobra directive").Invoke(Context{}),
+		actionCobraComplete([]byte("a\n")).Invoke(Context{}),
+	)
+}
+
+func TestActionServices(t *testing.T) {
+	defer func(source etc.Source) { etc.Default = source }(etc.Default)
+	etc.Default = etc.Embedded{}
+
+	assertEqual(t,
+		ActionValuesDescribed(
+			"domain", "53/tcp, 53/udp",
+			"ssh", "22/tcp",
+		).Tag("services").Invoke(Context{}),
+		ActionServices().Invoke(Context{}).Retain("domain", "ssh"),
+	)
+}
+
+type staticSource struct {
+	users   []etc.User
+	groups  []etc.Group
+	signals []etc.Signal
+}
+
+func (s staticSource) Users() ([]etc.User, error)       { return s.users, nil }
+func (s staticSource) Groups() ([]etc.Group, error)     { return s.groups, nil }
+func (s staticSource) Services() ([]etc.Service, error) { return nil, nil }
+func (s staticSource) Signals() ([]etc.Signal, error)   { return s.signals, nil }
+
+func TestActionUsersGroups(t *testing.T) {
+	defer func(source etc.Source) { etc.Default = source }(etc.Default)
+	etc.Default = staticSource{
+		users: []etc.User{
+			{Name: "root", Uid: "0", Description: "root", Shell: "/bin/bash"},
+			{Name: "daemon", Uid: "1", Description: "daemon", Shell: "/usr/sbin/nologin"},
+			{Name: "user", Uid: "1000", Description: "Full Name", Shell: "/bin/zsh"},
+		},
+		groups: []etc.Group{
+			{Name: "root", Gid: "0"},
+			{Name: "Users", Description: "Ordinary users"},
+		},
+		signals: []etc.Signal{
+			{Name: "KILL", Number: 9},
+		},
+	}
+
+	assertEqual(t,
+		ActionStyledValuesDescribed(
+			"root", "0", style.Red,
+			"daemon", "1", style.Dim,
+			"user", "1000 Full Name", style.Default,
+		).Tag("users").Invoke(Context{}),
+		ActionUsers().Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionStyledValuesDescribed(
+			"root", "0", style.Red,
+			"Users", "Ordinary users", style.Default,
+		).Tag("groups").Invoke(Context{}),
+		ActionGroups().Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValuesDescribed("KILL", "9").Tag("signals").Invoke(Context{}),
+		ActionSignals().Invoke(Context{}),
+	)
+}
+
+func TestActionFilesystems(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("needs /proc")
+	}
+	invoked := ActionFilesystems().Invoke(Context{})
+	if values := invoked.action.rawValues.Retain("proc"); len(values) != 1 || values[0].Description != "virtual" {
+		t.Errorf("expected virtual proc filesystem: %#v", values)
+	}
+}
+
+func TestByteSize(t *testing.T) {
+	for size, expected := range map[uint64]string{
+		0:                 "0B",
+		1023:              "1023B",
+		1024:              "1K",
+		1536:              "1.5K",
+		256 * 1024 * 1024: "256M",
+		1 << 40:           "1T",
+	} {
+		if actual := byteSize(size); actual != expected {
+			t.Errorf("expected %v for %v [was: %v]", expected, size, actual)
+		}
+	}
+}
+
+func TestActionProcessNames(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	invoked := ActionProcessNames().Invoke(Context{})
+	if values := invoked.action.rawValues.Retain(filepath.Base(executable)); len(values) != 1 {
+		t.Errorf("expected current executable: %#v", values)
+	}
+}
+
+func TestActionDateTime(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2026, 10, 15, 13, 37, 0, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	assertEqual(t,
+		ActionValuesDescribed(
+			"2026-10-15 13:37", "now",
+			"2026-10-15 00:00", "today",
+			"2026-10-14 00:00", "yesterday",
+			"2026-10-16 00:00", "tomorrow",
+			"2026-10-15 14:00", "in 1 hour",
+			"2026-10-15 15:00", "in 2 hours",
+			"2026-10-15 16:00", "in 3 hours",
+		).Tag("datetimes").Invoke(Context{}),
+		ActionDateTime("2006-01-02 15:04").Invoke(Context{}),
+	)
+}
+
+func TestActionDate(t *testing.T) {
+	timeNow = func() time.Time { return time.Date(2026, 2, 3, 13, 37, 0, 0, time.UTC) }
+	defer func() { timeNow = time.Now }()
+
+	dates := ActionDate("01/02").Invoke(Context{}).action.rawValues
+	if len(dates) != 28 {
+		t.Fatalf("expected 28 dates in february [was: %v]", len(dates))
+	}
+	if actual := dates[2]; actual.Value != "02/03" || actual.Description != "Tuesday, today" {
+		t.Errorf("unexpected date: %#v", actual)
+	}
+
+	if actual := ActionDate("").Invoke(Context{}).action.rawValues[0].Value; actual != "2026-02-01" {
+		t.Errorf("unexpected default layout: %v", actual)
+	}
+}
+
+func TestActionDuration(t *testing.T) {
+	assertEqual(t,
+		ActionValues("30s", "1m", "5m", "15m", "30m", "1h", "1h30m", "2h", "24h").NoSpace().Tag("durations").Invoke(Context{}),
+		ActionDuration().Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValuesDescribed(
+			"h", "hours",
+			"m", "minutes",
+			"s", "seconds",
+			"ms", "milliseconds",
+			"us", "microseconds",
+			"ns", "nanoseconds",
+		).Prefix("90").NoSpace().Tag("durations").Invoke(Context{}),
+		ActionDuration().Invoke(Context{Value: "90"}),
+	)
+
+	assertEqual(t,
+		ActionValuesDescribed(
+			"s", "seconds",
+			"ms", "milliseconds",
+			"us", "microseconds",
+			"ns", "nanoseconds",
+		).Prefix("1m30").NoSpace().Tag("durations").Invoke(Context{}),
+		ActionDuration().Invoke(Context{Value: "1m30"}),
+	)
+
+	if ActionDuration().Invoke(Context{Value: "1x"}).action.meta.Messages.IsEmpty() {
+		t.Error("invalid duration should result in a message")
+	}
+}
+
+func TestActionGoBuildTags(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/tags\n",
+		"main.go":              "package main\n",
+		"integration_test.go":  "//go:build integration && !windows\n// +build integration,!windows\n\npackage main\n",
+		"sub/windows.go":       "// Copyright\n\n//go:build windows || (linux && cgo)\n\npackage sub\n\n//go:build ignored\n",
+		"testdata/skipped.go":  "//go:build skipped\n\npackage testdata\n",
+		"sub/integration.go":   "//go:build integration\n\npackage sub\n",
+		"sub/noconstraints.go": "package sub\n",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	assertEqual(t,
+		ActionValuesDescribed(
+			"cgo", "1 file",
+			"integration", "2 files",
+			"linux", "1 file",
+			"windows", "2 files",
+		).Tag("build tags").Invoke(Context{}),
+		ActionGoBuildTags().Invoke(Context{Dir: dir + "/sub"}),
+	)
+}
+
+func TestActionGoExec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as mock")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/go.mod", []byte("module example.com/mock\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+  "tool dist list") printf 'linux/amd64\nlinux/arm64\nwindows/amd64\n' ;;
+  "list -m -versions example.com/dep") printf 'example.com/dep v0.1.0 v1.0.0 v1.1.0\n' ;;
+  "list -f "*) [ "$PWD" = "` + dir + `" ] && printf 'example.com/mock\tPackage mock is a mock.\nexample.com/mock/sub\t\n' ;;
+esac
+`
+	if err := os.WriteFile(bin+"/go", []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	assertEqual(t,
+		ActionValues("linux/", "windows/").NoSpace('/').Tag("platforms").Invoke(Context{}),
+		ActionGoPlatforms().Invoke(Context{Value: ""}),
+	)
+
+	assertEqual(t,
+		ActionValues("amd64", "arm64").NoSpace('/').Tag("platforms").Invoke(Context{}).Prefix("linux/"),
+		ActionGoPlatforms().Invoke(Context{Value: "linux/"}),
+	)
+
+	assertEqual(t,
+		ActionValues("v0.1.0", "v1.0.0", "v1.1.0").Tag("versions").Invoke(Context{}),
+		ActionGoModuleVersions("example.com/dep").Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValuesDescribed("mock", "Package mock is a mock.", "mock/", "").NoSpace('/').Tag("packages").Invoke(Context{}).Prefix("example.com/"),
+		ActionGoPackages().Invoke(Context{Dir: dir, Value: "example.com/"}),
+	)
+}
+
+func TestActionRemotePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as mock")
+	}
+
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+  *"-- host ls -1 -a -p -- ."|*"-- host ls -1 -a -p -- dir/") printf './\n../\nfile\nsub/\n' ;;
+  *) echo "$*" > "` + bin + `/unexpected" ;;
+esac
+`
+	if err := os.WriteFile(bin+"/ssh", []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	values := ActionRemotePath("host").Invoke(Context{}).action.rawValues
+	sort.Sort(common.ByValue(values))
+	if len(values) != 2 || values[0].Value != "file" || values[1].Value != "sub/" {
+		t.Errorf("unexpected values: %#v", values)
+	}
+
+	if values := ActionRemotePath("-oProxyCommand=false").Invoke(Context{}).action.rawValues; len(values) != 0 {
+		t.Errorf("expected no values for host starting with a dash: %#v", values)
+	}
+	if content, err := os.ReadFile(bin + "/unexpected"); err == nil {
+		t.Errorf("unexpected ssh invocation: %v", string(content))
+	}
+}
@@ -1,18 +1,29 @@
 #!/bin/bash
+_example_shell_env() {
+  local name
+  local -A exported
+  for name in $(compgen -e); do exported[${name}]=1; done
+  for name in $(compgen -v); do
+    [[ -n ${exported[${name}]} || ${!name} == *$'\n'* ]] || printf '%s=%s\n' "${name}" "${!name}"
+  done
+}
+
 _example_completion() {
   export COMP_LINE
   export COMP_POINT
   export COMP_TYPE
   export COMP_WORDBREAKS
 
-  local nospace data compline="${COMP_LINE:0:${COMP_POINT}}"
+  local nospace data compline="${COMP_LINE:0:${COMP_POINT}}" shellenv
+  shellenv="$(_example_shell_env)"
+  [ "${#shellenv}" -lt 65536 ] || shellenv='' # size of a single environment variable is limited
 
   if echo ${compline}"''" | xargs echo 2>/dev/null > /dev/null; then
-  	data=$(echo ${compline}"''" | xargs example _carapace bash)
+  	data=$(echo ${compline}"''" | CARAPACE_SHELL_ENV="${shellenv}" xargs example _carapace bash)
   elif echo ${compline} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
-  	data=$(echo ${compline} | sed "s/\$/'/" | xargs example _carapace bash)
+  	data=$(echo ${compline} | sed "s/\$/'/" | CARAPACE_SHELL_ENV="${shellenv}" xargs example _carapace bash)
   else
-  	data=$(echo ${compline} | sed 's/$/"/' | xargs example _carapace bash)
+  	data=$(echo ${compline} | sed 's/$/"/' | CARAPACE_SHELL_ENV="${shellenv}" xargs example _carapace bash)
   fi
 
   IFS=$'\001' read -r -d '' nospace data <<<"${data}"
@@ -11,9 +11,14 @@ function _example_quote_suffix
 end
 
 function _example_callback
-  commandline -cp | sed "s/\$/"(_example_quote_suffix)"/" | sed "s/ \$/ ''/" | xargs example _carapace fish
+  set -l quote (_example_quote_suffix)
+  commandline -cp | sed "s/\$/$quote/" | sed "s/ \$/ ''/" | env CARAPACE_FISH_QUOTE="$quote" xargs example _carapace fish
 end
 
 complete -c example -f
-complete -c 'example' -f -a '(_example_callback)' -r
+if contains -- "$CARAPACE_FISH_NOSORT" 1 true
+  complete -c 'example' -f -k -a '(_example_callback)' -r
+else
+  complete -c 'example' -f -a '(_example_callback)' -r
+end
 
@@ -0,0 +1,45 @@
+#!/bin/ksh
+function _example_completion {
+  typeset compline data value
+  compline="${COMP_LINE:0:${COMP_POINT}}"
+  data="$(print -r -- "${compline}" | sed -e "s/ \$/ ''/" -e 's/"/\"/g' | xargs example _carapace ksh)"
+  _example_nospace="${data%%$'\001'*}"
+  data="${data#*$'\001'}"
+
+  unset COMPREPLY
+  while IFS= read -r value; do
+    [[ -n "${value}" ]] && COMPREPLY[${#COMPREPLY[@]}]="${value}"
+  done <<<"${data}"
+  [[ "${_example_nospace}" == true ]] && command -v compopt >/dev/null 2>&1 && compopt -o nospace
+  return 0
+}
+
+function _example_keybd {
+  [[ "${.sh.edchar}" == $'\t' ]] || return 0
+  typeset line="${.sh.edtext:0:${.sh.edcol}}" current
+  [[ "${line%%[[:space:]]*}" == example && "${line}" == *[[:space:]]* ]] || return 0
+
+  COMP_LINE="${line}"
+  COMP_POINT="${#line}"
+  _example_completion
+  current="${line##*[[:space:]]}"
+  case ${#COMPREPLY[@]} in
+    0) .sh.edchar='' ;;
+    1) if [[ "${COMPREPLY[0]}" == "${current}"* ]]; then
+         .sh.edchar="${COMPREPLY[0]#"${current}"}"
+         [[ "${_example_nospace}" == true ]] || .sh.edchar+=' '
+       else
+         .sh.edchar=''
+       fi ;;
+    *) print -u2
+       printf '%s\n' "${COMPREPLY[@]}" >&2
+       .sh.edchar=$'\cL' ;; # redraw the current line
+  esac
+}
+
+if command -v complete >/dev/null 2>&1; then
+  complete -F _example_completion example
+elif [[ "${KSH_VERSION}" == *93* ]]; then
+  trap _example_keybd KEYBD
+fi
+
@@ -28,11 +28,19 @@ Function _example_completer {
       $elems += $t.replace('`,', ',') # quick fix
     }
 
+    $output = if (!$wordToComplete) {
+      example _carapace powershell $($elems| ForEach-Object {$_}) ''
+    } else {
+      example _carapace powershell $($elems| ForEach-Object {$_})
+    }
+
     $completions = @(
-      if (!$wordToComplete) {
-        example _carapace powershell $($elems| ForEach-Object {$_}) '' | ConvertFrom-Json | ForEach-Object { [CompletionResult]::new($_.CompletionText, $_.ListItemText.replace('`e[', "`e["), [CompletionResultType]::ParameterValue, $_.ToolTip.replace('`e[', "`e[")) }
-      } else {
-        example _carapace powershell $($elems| ForEach-Object {$_}) | ConvertFrom-Json | ForEach-Object { [CompletionResult]::new($_.CompletionText, $_.ListItemText.replace('`e[', "`e["), [CompletionResultType]::ParameterValue, $_.ToolTip.replace('`e[', "`e[")) }
+      $output | ConvertFrom-Json | ForEach-Object {
+        $completionText = $_.CompletionText
+        if (!$_.NoSpace) {
+          $completionText = $completionText + ' '
+        }
+        [CompletionResult]::new($completionText, $_.ListItemText.replace('`e[', "`e["), [CompletionResultType]$_.ResultType, $_.ToolTip.replace('`e[', "`e["))
       }
     )
 
@@ -0,0 +1,21 @@
+#!/usr/bin/env ysh
+proc _example_completion {
+  var compline = COMP_LINE[:int(COMP_POINT)]
+  var candidates = []
+  write -- $compline | sed -e "s/ \$/ ''/" -e 's/"/\"/g' | xargs example _carapace ysh | json read (&candidates)
+
+  setglobal COMPREPLY = []
+  for candidate in (candidates) {
+    if (len(candidates) === 1) {
+      call COMPREPLY->append(candidate.value)
+    } else {
+      call COMPREPLY->append(candidate.display)
+    }
+  }
+  if (len(candidates) === 1 and candidates[0].nospace) {
+    compopt -o nospace
+  }
+}
+
+complete -F _example_completion example
+
@@ -1,32 +1,48 @@
 #compdef example
+function _example_shell_env {
+  local name
+  for name in ${(k)parameters}; do
+    [[ ${parameters[${name}]} == (scalar|integer|float)* && ${parameters[${name}]} != *-(export|special)* && ${(P)name} != *$'\n'* ]] && printf '%s=%s\n' "${name}" "${(P)name}"
+  done
+}
+
 function _example_completion {
   local IFS=$'\n'
+  local shellenv="$(_example_shell_env)"
+  [ "${#shellenv}" -lt 65536 ] || shellenv='' # size of a single environment variable is limited
   
   # shellcheck disable=SC2086,SC2154,SC2155
   if echo ${words}"''" | xargs echo 2>/dev/null > /dev/null; then
-    local lines="$(echo ${words}"''" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs example _carapace zsh )"
+    local lines="$(echo ${words}"''" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs example _carapace zsh )"
   elif echo ${words} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
-    local lines="$(echo ${words} | sed "s/\$/'/" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs example _carapace zsh)"
+    local lines="$(echo ${words} | sed "s/\$/'/" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs example _carapace zsh)"
   else
-    local lines="$(echo ${words} | sed 's/$/"/' | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs example _carapace zsh)"
+    local lines="$(echo ${words} | sed 's/$/"/' | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs example _carapace zsh)"
   fi
 
-  local zstyle message data
-  IFS=$'\001' read -r -d '' zstyle message data <<<"${lines}"
+  local zstyle message format data configured header=0
+  IFS=$'\001' read -r -d '' zstyle message format data <<<"${lines}"
   # shellcheck disable=SC2154
   zstyle ":completion:${curcontext}:*" list-colors "${zstyle}"
-  zstyle ":completion:${curcontext}:*" group-name ''
+  zstyle -s ":completion:${curcontext}:*" group-name configured || zstyle ":completion:${curcontext}:*" group-name ''
+  if [ -n "$format" ] && ! zstyle -s ":completion:${curcontext}:descriptions" format configured; then
+    zstyle ":completion:${curcontext}:descriptions" format "${format}"
+    header=1
+  fi
   [ -z "$message" ] || _message -r "${message}"
   
-  local block tag displays values displaysArr valuesArr
+  local block tag description displays values displaysArr valuesArr list=''
+  [[ "${CARAPACE_ACCESSIBLE}" == (1|true) ]] && list='-l' # one candidate per line
   while IFS=$'\002' read -r -d $'\002' block; do
-    IFS=$'\003' read -r -d '' tag displays values <<<"${block}"
+    IFS=$'\003' read -r -d '' tag description displays values <<<"${block}"
     # shellcheck disable=SC2034
     IFS=$'\n' read -r -d $'\004' -A displaysArr <<<"${displays}"$'\004'
     IFS=$'\n' read -r -d $'\004' -A valuesArr <<<"${values}"$'\004'
   
-    [[ ${#valuesArr[@]} -gt 1 ]] && _describe -t "${tag}" "${tag}" displaysArr valuesArr -Q -S ''
+    [[ ${#valuesArr[@]} -gt 1 ]] && _describe -t "${tag}" "${description}" displaysArr valuesArr -Q -S '' ${list}
   done <<<"${data}"
+
+  [ "$header" -eq 0 ] || zstyle -d ":completion:${curcontext}:descriptions" format
 }
 compquote '' 2>/dev/null && _example_completion
 compdef _example_completion example
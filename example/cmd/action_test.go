@@ -390,7 +390,10 @@ func TestSymlink(t *testing.T) {
 				Usage("ActionFiles()"))
 
 		s.Run("action", "--files", "s").
-			Expect(carapace.ActionValues("symA/", "symB").
+			Expect(carapace.ActionValuesDescribed(
+				"symA/", "", // directories are completed as a part (see MultiParts)
+				"symB", "-> "+c.Dir+"/missing", // broken symlinks use the orphan style of LS_COLORS
+			).
 				Tag("files").
 				StyleF(style.ForPath).
 				NoSpace('/').
@@ -74,7 +74,7 @@ func init() {
 		).ToA(),
 		"cache": carapace.ActionCallback(func(c carapace.Context) carapace.Action {
 			return carapace.ActionValues(
-				time.Now().Format("15:04:05"),
+				time.Now().Format("15:04:05.000000000"),
 			)
 		}).Cache(5 * time.Second),
 		"cache-key": carapace.ActionMultiParts("/", func(c carapace.Context) carapace.Action {
@@ -84,7 +84,7 @@ func init() {
 			case 1:
 				return carapace.ActionCallback(func(c carapace.Context) carapace.Action {
 					return carapace.ActionValues(
-						time.Now().Format("15:04:05"),
+						time.Now().Format("15:04:05.000000000"),
 					)
 				}).Cache(10*time.Second, key.String(c.Parts[0]))
 			default:
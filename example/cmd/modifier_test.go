@@ -26,7 +26,17 @@ func TestBatch(t *testing.T) {
 func TestCache(t *testing.T) {
 	sandbox.Package(t, "github.com/carapace-sh/carapace/example")(func(s *sandbox.Sandbox) {
 		cached := s.Run("modifier", "--cache", "").Output()
-		time.Sleep(1 * time.Second)
+		s.AdvanceTime(4 * time.Second)
+		s.Run("modifier", "--cache", "").
+			Expect(cached.
+				Usage("Cache()"))
+
+		s.AdvanceTime(2 * time.Second) // expired
+		expired := s.Run("modifier", "--cache", "")
+		expired.ExpectNot(cached.
+			Usage("Cache()"))
+
+		cached = expired.Output()
 		s.Run("modifier", "--cache", "").
 			Expect(cached.
 				Usage("Cache()"))
@@ -329,7 +339,8 @@ func TestSplit(t *testing.T) {
 			Expect(carapace.ActionStyledValues(
 				"true", style.Green,
 				"false", style.Red,
-			).Prefix("pos1 --bool=").
+			).Suggest("false").
+				Prefix("pos1 --bool=").
 				Suffix(" ").
 				NoSpace('*').
 				Usage("bool flag"))
@@ -338,7 +349,8 @@ func TestSplit(t *testing.T) {
 			Expect(carapace.ActionStyledValues(
 				"true", style.Green,
 				"false", style.Red,
-			).Prefix("pos1 \"--bool=").
+			).Suggest("false").
+				Prefix("pos1 \"--bool=").
 				Suffix("\" ").
 				NoSpace('*').
 				Usage("bool flag"))
@@ -347,18 +359,26 @@ func TestSplit(t *testing.T) {
 			Expect(carapace.ActionStyledValues(
 				"true", style.Green,
 				"false", style.Red,
-			).Prefix("pos1 '--bool=").
+			).Suggest("false").
+				Prefix("pos1 '--bool=").
 				Suffix("' ").
 				NoSpace('*').
 				Usage("bool flag"))
 
-		t.Skip("skipping test that don't work yet") // TODO these need to work
 		s.Run("modifier", "--split", "pos1 \"").
 			Expect(carapace.ActionValues(
 				"subdir/",
 			).StyleF(style.ForPathExt).
 				Prefix("pos1 \"").
-				Suffix("\"").
+				NoSpace('*').
+				Usage("Split()").
+				Tag("files"))
+
+		s.Run("modifier", "--split", "pos1 \"subdir/").
+			Expect(carapace.ActionValues(
+				"file1.txt",
+			).Prefix("pos1 \"subdir/").
+				Suffix("\" ").
 				NoSpace('*').
 				Usage("Split()").
 				Tag("files"))
@@ -368,7 +388,6 @@ func TestSplit(t *testing.T) {
 				"subdir/",
 			).StyleF(style.ForPathExt).
 				Prefix("pos1 '").
-				Suffix("'").
 				NoSpace('*').
 				Usage("Split()").
 				Tag("files"))
@@ -36,6 +36,10 @@ func TestFish(t *testing.T) {
 	testScript(t, "fish", "./_test/fish.fish")
 }
 
+func TestKsh(t *testing.T) {
+	testScript(t, "ksh", "./_test/ksh.sh")
+}
+
 func TestNushell(t *testing.T) {
 	testScript(t, "nushell", "./_test/nushell.nu")
 }
@@ -52,6 +56,10 @@ func TestXonsh(t *testing.T) {
 	testScript(t, "xonsh", "./_test/xonsh.py")
 }
 
+func TestYsh(t *testing.T) {
+	testScript(t, "ysh", "./_test/ysh.sh")
+}
+
 func TestZsh(t *testing.T) {
 	testScript(t, "zsh", "./_test/zsh.sh")
 }
@@ -171,7 +179,8 @@ func TestOptarg(t *testing.T) {
 			Expect(carapace.ActionStyledValues(
 				"true", style.Green,
 				"false", style.Red,
-			).Prefix("--toggle=").
+			).Suggest("false").
+				Prefix("--toggle=").
 				Usage("Help message for toggle"))
 
 		s.Run("--toggle=tru").
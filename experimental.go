@@ -17,12 +17,13 @@ func init() {
 	x.Complete = func(cmd *cobra.Command, args ...string) (*export.Export, error) {
 		initHelpCompletion(cmd)
 		action, context := traverse(cmd, args[2:])
+		context.Shell = args[0]
 
 		if err := config.Load(); err != nil {
 			return nil, err
 		}
 
-		output := action.Invoke(context).value("export", "")
+		output := action.Invoke(context).value(context, "export", "")
 		var e export.Export
 		if err := json.Unmarshal([]byte(output), &e); err != nil {
 			return nil, err
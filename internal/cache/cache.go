@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -26,8 +27,29 @@ func WriteE(file string, e export.Export) (err error) {
 	return
 }
 
+// Write writes the content to given file (replaced atomically and only readable by the current user).
 func Write(file string, content []byte) (err error) {
-	return os.WriteFile(file, content, 0600)
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*") // created with 0600
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after rename
+
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if _, sandboxErr := env.Sandbox(); sandboxErr == nil {
+		now := Now() // stamp with the shifted clock so that fresh entries aren't already expired
+		if err = os.Chtimes(tmp.Name(), now, now); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), file)
 }
 
 func LoadE(file string, timeout time.Duration) (*export.Export, error) { // TODO reference
@@ -45,26 +67,53 @@ func LoadE(file string, timeout time.Duration) (*export.Export, error) { // TODO
 
 func Load(file string, timeout time.Duration) (b []byte, err error) {
 	var stat os.FileInfo
-	if stat, err = os.Stat(file); os.IsNotExist(err) || (timeout >= 0 && stat.ModTime().Add(timeout).Before(time.Now())) {
+	if stat, err = os.Stat(file); os.IsNotExist(err) || (timeout >= 0 && stat.ModTime().Add(timeout).Before(Now())) {
 		return nil, errors.New("not exists or timeout exceeded")
 	}
 	return os.ReadFile(file)
 }
 
-// CacheDir creates a cache folder for current user and returns the path.
-func CacheDir(name string) (dir string, err error) {
-	var userCacheDir string
-	userCacheDir, err = xdg.UserCacheDir()
+// Now returns the current time used to determine whether cache entries are expired.
+// Within the sandbox it is shifted by the offset of the mock (see sandbox.AdvanceTime).
+func Now() time.Time {
+	if m, err := env.Sandbox(); err == nil {
+		return m.Now()
+	}
+	return time.Now()
+}
+
+// Dir returns the cache directory for the current user and executable.
+func Dir() (string, error) {
+	userCacheDir, err := xdg.UserCacheDir()
 	if err != nil {
-		return
+		return "", err
 	}
 
 	if m, sandboxErr := env.Sandbox(); sandboxErr == nil {
 		userCacheDir = m.CacheDir()
 	}
+	return fmt.Sprintf("%v/carapace/%v", userCacheDir, uid.Executable()), nil
+}
+
+// CacheDir creates a cache folder for current user and returns the path.
+func CacheDir(name string) (dir string, err error) {
+	var executableDir string
+	if executableDir, err = Dir(); err != nil {
+		return
+	}
+
+	base := filepath.Dir(executableDir)
+	dir = fmt.Sprintf("%v/%v", executableDir, name)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return
+	}
 
-	dir = fmt.Sprintf("%v/carapace/%v/%v", userCacheDir, uid.Executable(), name)
-	err = os.MkdirAll(dir, 0700)
+	// restrict directories created by earlier versions (fails for directories owned by other users)
+	for _, d := range []string{base, executableDir, dir} {
+		if err = os.Chmod(d, 0700); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -86,6 +135,87 @@ func File(callerFile string, callerLine int, keys ...key.Key) (file string, err
 	return
 }
 
+// Entry is a file in the cache directory.
+type Entry struct {
+	Path    string // relative to Dir
+	Size    int64
+	ModTime time.Time
+}
+
+// Entries returns the files in the cache directory.
+func Entries() ([]Entry, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		switch {
+		case os.IsNotExist(err):
+			return nil // nothing cached yet
+		case err != nil:
+			return err
+		case info.IsDir():
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{
+			Path:    filepath.ToSlash(rel),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// Clear removes the cache directory.
+func Clear() error {
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// Invalidate removes the entries cached with given keys (regardless of where the cache was defined).
+// Keys are mandatory as entries cached without any would otherwise all be removed.
+func Invalidate(keys ...key.Key) error {
+	if len(keys) == 0 {
+		return errors.New("missing cache keys")
+	}
+
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		id, err := key()
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return err
+	}
+
+	files, err := filepath.Glob(fmt.Sprintf("%v/*/%v", dir, uidKeys(ids...)))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func uidKeys(keys ...string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(strings.Join(keys, "\001"))))
 }
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/carapace-sh/carapace/pkg/cache/key"
+)
+
+func TestWrite(t *testing.T) {
+	file := t.TempDir() + "/file"
+	if err := os.WriteFile(file, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write(file, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+
+	if content, err := os.ReadFile(file); err != nil || string(content) != "new" {
+		t.Errorf("unexpected content: %#v", string(content))
+	}
+
+	if info, err := os.Stat(file); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600: %v", info.Mode().Perm())
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	files := make([]string, 0)
+	for _, k := range []key.Key{key.String("a"), key.String("b")} {
+		for _, line := range []int{1, 2} {
+			file, err := File("caller.go", line, k)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Write(file, []byte("cached")); err != nil {
+				t.Fatal(err)
+			}
+			files = append(files, file)
+		}
+	}
+
+	if entries, err := Entries(); err != nil || len(entries) != 4 {
+		t.Errorf("expected 4 entries [was: %#v, %v]", entries, err)
+	}
+
+	if err := Invalidate(); err == nil {
+		t.Error("expected error for missing keys")
+	}
+
+	if err := Invalidate(key.String("a")); err != nil {
+		t.Fatal(err)
+	}
+	for index, file := range files {
+		if _, err := os.Stat(file); (index < 2) != os.IsNotExist(err) {
+			t.Errorf("unexpected state of %v: %v", file, err)
+		}
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := Entries(); err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries [was: %#v, %v]", entries, err)
+	}
+}
+
+func TestLoadOffset(t *testing.T) {
+	file := t.TempDir() + "/file"
+	if err := Write(file, []byte("cached")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CARAPACE_SANDBOX", `{"Dir":"`+t.TempDir()+`","Offset":4000000000}`)
+	if content, err := Load(file, 5*time.Second); err != nil || string(content) != "cached" {
+		t.Errorf("expected cached content [was: %#v, %v]", string(content), err)
+	}
+
+	t.Setenv("CARAPACE_SANDBOX", `{"Dir":"`+t.TempDir()+`","Offset":6000000000}`)
+	if _, err := Load(file, 5*time.Second); err == nil {
+		t.Error("expected cache entry to be expired")
+	}
+
+	if err := Write(file, []byte("rewritten")); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := Load(file, 5*time.Second); err != nil || string(content) != "rewritten" {
+		t.Errorf("expected entry written with offset to be fresh [was: %#v, %v]", string(content), err)
+	}
+}
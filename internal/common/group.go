@@ -25,6 +25,20 @@ func (g Group) Tag() string {
 	}
 }
 
+// Title returns the title of the group (without a trailing `:`) shown as header for its tag.
+func (g Group) Title() string {
+	if g.Cmd.GroupID == "" || !g.Cmd.HasParent() {
+		return ""
+	}
+
+	for _, group := range g.Cmd.Parent().Groups() {
+		if group.ID == g.Cmd.GroupID {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(group.Title), ":"))
+		}
+	}
+	return ""
+}
+
 func (g Group) Style() string {
 	if g.Cmd.Parent() == nil || g.Cmd.Parent().Groups() == nil {
 		return style.Default
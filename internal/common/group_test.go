@@ -0,0 +1,33 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestGroupTag(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.AddGroup(
+		&cobra.Group{ID: "management", Title: "Management Commands:"},
+		&cobra.Group{ID: "basic"},
+	)
+
+	management := &cobra.Command{Use: "management", GroupID: "management"}
+	basic := &cobra.Command{Use: "basic", GroupID: "basic"}
+	other := &cobra.Command{Use: "other"}
+	root.AddCommand(management, basic, other)
+
+	for cmd, expected := range map[*cobra.Command][2]string{
+		management: {"management commands", "Management Commands"},
+		basic:      {"basic commands", ""},
+		other:      {"other commands", ""},
+	} {
+		if tag := (Group{Cmd: cmd}).Tag(); tag != expected[0] {
+			t.Errorf("expected %#v, got %#v", expected[0], tag)
+		}
+		if title := (Group{Cmd: cmd}).Title(); title != expected[1] {
+			t.Errorf("expected %#v, got %#v", expected[1], title)
+		}
+	}
+}
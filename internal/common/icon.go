@@ -0,0 +1,79 @@
+package common
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	iconDirectory = "\uf07b" // nf-fa-folder
+	iconFile      = "\uf15b" // nf-fa-file
+)
+
+// iconsByExtension maps file extensions to nerd font icons.
+var iconsByExtension = map[string]string{
+	".c":    "\ue61e", // nf-custom-c
+	".go":   "\ue627", // nf-seti-go
+	".gz":   "\uf410", // nf-oct-file_zip
+	".html": "\ue60e", // nf-seti-html
+	".jpg":  "\uf1c5", // nf-fa-file_image_o
+	".js":   "\ue74e", // nf-dev-javascript
+	".json": "\ue60b", // nf-seti-json
+	".md":   "\ue609", // nf-dev-markdown
+	".png":  "\uf1c5", // nf-fa-file_image_o
+	".py":   "\ue606", // nf-seti-python
+	".rs":   "\ue7a8", // nf-dev-rust
+	".sh":   "\uf489", // nf-oct-terminal
+	".tar":  "\uf410", // nf-oct-file_zip
+	".toml": "\ue615", // nf-seti-config
+	".ts":   "\ue628", // nf-seti-typescript
+	".yaml": "\ue615", // nf-seti-config
+	".yml":  "\ue615", // nf-seti-config
+	".zip":  "\uf410", // nf-oct-file_zip
+}
+
+// iconsByTag maps tags (or their last word) to nerd font icons.
+var iconsByTag = map[string]string{
+	"commands":    "\uf120", // nf-fa-terminal
+	"executables": "\uf120", // nf-fa-terminal
+	"flags":       "\uf024", // nf-fa-flag
+	"groups":      "\uf0c0", // nf-fa-users
+	"hosts":       "\uf233", // nf-fa-server
+	"keys":        "\uf084", // nf-fa-key
+	"processes":   "\uf085", // nf-fa-cogs
+	"settings":    "\uf013", // nf-fa-cog
+	"urls":        "\uf0c1", // nf-fa-link
+	"users":       "\uf007", // nf-fa-user
+}
+
+// Icon returns the nerd font icon for given value based on its tag (or file type).
+func (r RawValue) Icon() string {
+	switch r.Tag {
+	case "files", "directories":
+		if strings.HasSuffix(r.Value, "/") {
+			return iconDirectory
+		}
+		if icon, ok := iconsByExtension[strings.ToLower(filepath.Ext(r.Value))]; ok {
+			return icon
+		}
+		return iconFile
+	}
+
+	fields := strings.Fields(r.Tag)
+	if len(fields) == 0 {
+		return ""
+	}
+	return iconsByTag[fields[len(fields)-1]]
+}
+
+// Iconify prefixes displays with their icon (if any).
+func (r RawValues) Iconify() RawValues {
+	iconified := make(RawValues, len(r))
+	for index, value := range r {
+		if icon := value.Icon(); icon != "" {
+			value.Display = icon + " " + value.Display
+		}
+		iconified[index] = value
+	}
+	return iconified
+}
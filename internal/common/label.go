@@ -0,0 +1,46 @@
+package common
+
+import "strings"
+
+// labelsByTag maps tags (or their last word) to textual labels.
+var labelsByTag = map[string]string{
+	"commands":    "cmd",
+	"executables": "cmd",
+	"flags":       "flag",
+	"groups":      "group",
+	"hosts":       "host",
+	"keys":        "key",
+	"processes":   "proc",
+	"settings":    "setting",
+	"urls":        "url",
+	"users":       "user",
+}
+
+// Label returns the textual label for given value based on its tag (used instead of colors and icons).
+func (r RawValue) Label() string {
+	switch r.Tag {
+	case "files", "directories":
+		if strings.HasSuffix(r.Value, "/") {
+			return "dir"
+		}
+		return "file"
+	}
+
+	fields := strings.Fields(r.Tag)
+	if len(fields) == 0 {
+		return ""
+	}
+	return labelsByTag[fields[len(fields)-1]]
+}
+
+// Labelize prefixes displays with their label (if any) like `[dir] docs/`.
+func (r RawValues) Labelize() RawValues {
+	labeled := make(RawValues, len(r))
+	for index, value := range r {
+		if label := value.Label(); label != "" {
+			value.Display = "[" + label + "] " + value.Display
+		}
+		labeled[index] = value
+	}
+	return labeled
+}
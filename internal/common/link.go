@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	hyperlinkStart = "\x1b]8;;"
+	hyperlinkEnd   = "\x1b\\"
+)
+
+// Hyperlink returns given text as terminal hyperlink (OSC 8).
+func Hyperlink(link, text string) string {
+	if link == "" {
+		return text
+	}
+	return fmt.Sprintf("%v%v%v%v%v%v", hyperlinkStart, link, hyperlinkEnd, text, hyperlinkStart, hyperlinkEnd)
+}
+
+// Hyperlink wraps the display of values with a link in a terminal hyperlink.
+func (r RawValues) Hyperlink() RawValues {
+	linked := make(RawValues, len(r))
+	for index, value := range r {
+		value.Display = Hyperlink(value.Link, value.Display)
+		linked[index] = value
+	}
+	return linked
+}
+
+// unlinked returns the display text without the hyperlink start sequence along with its offset.
+func unlinked(display string) (string, int) {
+	if !strings.HasPrefix(display, hyperlinkStart) {
+		return display, 0
+	}
+	if index := strings.Index(display, hyperlinkEnd); index >= 0 {
+		return display[index+len(hyperlinkEnd):], index + len(hyperlinkEnd)
+	}
+	return display, 0
+}
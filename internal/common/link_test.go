@@ -0,0 +1,18 @@
+package common
+
+import "testing"
+
+func TestHyperlinkHighlight(t *testing.T) {
+	values := RawValues{{Value: "main", Display: "main", Link: "https://example.com/main"}}.Highlight("ma").Hyperlink()
+
+	before, match, after := values[0].HighlightSplit()
+	if expected := "\x1b]8;;https://example.com/main\x1b\\"; before != expected {
+		t.Errorf("expected %#v [was: %#v]", expected, before)
+	}
+	if match != "ma" {
+		t.Errorf("expected %#v [was: %#v]", "ma", match)
+	}
+	if expected := "in\x1b]8;;\x1b\\"; after != expected {
+		t.Errorf("expected %#v [was: %#v]", expected, after)
+	}
+}
@@ -10,13 +10,66 @@ import (
 	"github.com/carapace-sh/carapace/pkg/style"
 )
 
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelInfo    = "info"
+)
+
+// severity of message levels (higher is more severe).
+var severity = map[string]int{
+	LevelInfo:    0,
+	LevelWarning: 1,
+	LevelError:   2,
+}
+
 type Messages struct {
-	messages map[string]bool
+	messages map[string]string // message -> level
+	links    map[string]string // message -> link
+}
+
+// Message is a message with its level.
+type Message struct {
+	Message string `json:"message"`
+	Level   string `json:"level"`
+	Link    string `json:"link,omitempty"` // url pointing to documentation
+}
+
+// Hyperlink returns given text as terminal hyperlink (OSC 8) to the link of the message.
+func (m Message) Hyperlink(text string) string {
+	return Hyperlink(m.Link, text)
+}
+
+// Style returns the style for the level of the message.
+func (m Message) Style() string {
+	switch m.Level {
+	case LevelWarning:
+		return style.Carapace.Warning
+	case LevelInfo:
+		return style.Carapace.Info
+	default:
+		return style.Carapace.Error
+	}
+}
+
+// Label returns the short label used to display the message as value.
+func (m Message) Label() string {
+	switch m.Level {
+	case LevelWarning:
+		return "WARN"
+	case LevelInfo:
+		return "INFO"
+	default:
+		return "ERR"
+	}
 }
 
 func (m *Messages) init() {
 	if m.messages == nil {
-		m.messages = make(map[string]bool)
+		m.messages = make(map[string]string)
+	}
+	if m.links == nil {
+		m.links = make(map[string]string)
 	}
 }
 
@@ -25,9 +78,37 @@ func (m Messages) IsEmpty() bool {
 	return len(m.messages) == 0
 }
 
+// HasErrors returns true if any message has the error level.
+func (m Messages) HasErrors() bool {
+	for _, level := range m.messages {
+		if level == LevelError {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds an error message.
 func (m *Messages) Add(s string) {
+	m.AddLevel(LevelError, s)
+}
+
+// AddLevel adds a message with given level (the most severe one is kept for duplicates).
+func (m *Messages) AddLevel(level, s string) {
 	m.init()
-	m.messages[s] = true
+	if existing, ok := m.messages[s]; !ok || severity[level] > severity[existing] {
+		m.messages[s] = level
+	}
+}
+
+// Link sets the link for messages without one.
+func (m *Messages) Link(link string) {
+	m.init()
+	for message := range m.messages {
+		if _, ok := m.links[message]; !ok {
+			m.links[message] = link
+		}
+	}
 }
 
 func (m Messages) Get() []string {
@@ -39,6 +120,18 @@ func (m Messages) Get() []string {
 	return messages
 }
 
+// GetLevels returns the messages along with their level sorted by severity.
+func (m Messages) GetLevels() []Message {
+	messages := make([]Message, 0, len(m.messages))
+	for _, message := range m.Get() {
+		messages = append(messages, Message{Message: message, Level: m.messages[message], Link: m.links[message]})
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return severity[messages[i].Level] > severity[messages[j].Level]
+	})
+	return messages
+}
+
 func (m *Messages) Suppress(expr ...string) error {
 	m.init()
 
@@ -51,6 +144,7 @@ func (m *Messages) Suppress(expr ...string) error {
 		for key := range m.messages {
 			if r.MatchString(key) {
 				delete(m.messages, key)
+				delete(m.links, key)
 			}
 		}
 	}
@@ -62,8 +156,11 @@ func (m *Messages) Merge(other Messages) {
 		return
 	}
 
-	for key := range other.messages {
-		m.Add(key)
+	for key, level := range other.messages {
+		m.AddLevel(level, key)
+		if link, ok := other.links[key]; ok && m.links[key] == "" {
+			m.links[key] = link
+		}
 	}
 }
 
@@ -74,12 +171,6 @@ func (m Messages) Integrate(values RawValues, prefix string) RawValues {
 		return values
 	}
 
-	sorted := make([]string, 0)
-	for message := range m.messages {
-		sorted = append(sorted, message)
-	}
-	sort.Strings(sorted)
-
 	switch {
 	case strings.HasSuffix(prefix, "ERR"):
 		prefix = strings.TrimSuffix(prefix, "ERR")
@@ -89,16 +180,17 @@ func (m Messages) Integrate(values RawValues, prefix string) RawValues {
 		prefix = strings.TrimSuffix(prefix, "E")
 	}
 
-	i := 0
-	for _, message := range sorted {
-		value := prefix + "ERR"
-		display := "ERR"
+	counter := make(map[string]int)
+	for _, message := range m.GetLevels() {
+		label := message.Label()
+		value := prefix + label
+		display := label
 		for {
-			if i > 0 {
-				value = fmt.Sprintf("%vERR%v", prefix, i)
-				display = fmt.Sprintf("ERR%v", i)
+			if i := counter[label]; i > 0 {
+				value = fmt.Sprintf("%v%v%v", prefix, label, i)
+				display = fmt.Sprintf("%v%v", label, i)
 			}
-			i += 1
+			counter[label] += 1
 
 			if !values.contains(value) {
 				break
@@ -106,10 +198,11 @@ func (m Messages) Integrate(values RawValues, prefix string) RawValues {
 		}
 
 		values = append(values, RawValue{
-			Value:       value,
-			Display:     display,
-			Description: message,
-			Style:       style.Carapace.Error,
+			Value:         value,
+			Display:       display,
+			Description:   message.Message,
+			Style:         message.Style(),
+			Documentation: message.Link,
 		})
 	}
 
@@ -125,22 +218,41 @@ func (m Messages) Integrate(values RawValues, prefix string) RawValues {
 	return values
 }
 
+// MarshalJSON encodes error messages as plain strings (wire format of earlier versions)
+// and only those with a different level or a link as objects.
 func (m Messages) MarshalJSON() ([]byte, error) {
-	var result []string = make([]string, 0, len(m.messages))
-	for key := range m.messages {
-		result = append(result, key)
+	messages := make([]interface{}, 0, len(m.messages))
+	for _, message := range m.GetLevels() {
+		if message.Level == LevelError && message.Link == "" {
+			messages = append(messages, message.Message)
+			continue
+		}
+		messages = append(messages, message)
 	}
-	sort.Strings(result)
-	return json.Marshal(&result)
+	return json.Marshal(messages)
 }
 
+// UnmarshalJSON decodes messages as objects as well as plain strings (errors of earlier versions).
 func (m *Messages) UnmarshalJSON(data []byte) (err error) {
-	var result []string
+	var result []json.RawMessage
 	if err = json.Unmarshal(data, &result); err != nil {
 		return err
 	}
 	for _, item := range result {
-		m.Add(item)
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			m.Add(s)
+			continue
+		}
+
+		var message Message
+		if err = json.Unmarshal(item, &message); err != nil {
+			return err
+		}
+		m.AddLevel(message.Level, message.Message)
+		if message.Link != "" {
+			m.links[message.Message] = message.Link
+		}
 	}
 	return
 }
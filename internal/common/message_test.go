@@ -0,0 +1,102 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMessagesLevel(t *testing.T) {
+	var m Messages
+	m.AddLevel(LevelInfo, "info")
+	m.AddLevel(LevelWarning, "warning")
+	if m.HasErrors() {
+		t.Error("should not have errors")
+	}
+
+	m.AddLevel(LevelInfo, "warning") // less severe duplicate is ignored
+	m.Add("error")
+	if !m.HasErrors() {
+		t.Error("should have errors")
+	}
+
+	expected := []Message{
+		{Message: "error", Level: LevelError},
+		{Message: "warning", Level: LevelWarning},
+		{Message: "info", Level: LevelInfo},
+	}
+	for index, message := range m.GetLevels() {
+		if message != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], message)
+		}
+	}
+}
+
+func TestMessagesIntegrate(t *testing.T) {
+	var m Messages
+	m.AddLevel(LevelWarning, "stale cache")
+
+	values := m.Integrate(RawValuesFrom("first"), "")
+	if len(values) != 2 || values[0].Display != "WARN" || values[1].Value != "first" {
+		t.Errorf("warning should be integrated next to values: %#v", values)
+	}
+}
+
+func TestMessagesJSON(t *testing.T) {
+	var m Messages
+	m.Add("error")
+	m.AddLevel(LevelWarning, "warning")
+
+	marshalled, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if expected := `["error",{"message":"warning","level":"warning"}]`; string(marshalled) != expected {
+		t.Errorf("expected %v [was: %v]", expected, string(marshalled))
+	}
+
+	var unmarshalled Messages
+	if err := json.Unmarshal(marshalled, &unmarshalled); err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(unmarshalled.GetLevels()) != 2 || unmarshalled.GetLevels()[1].Level != LevelWarning {
+		t.Errorf("levels should be preserved: %#v", unmarshalled.GetLevels())
+	}
+
+	var legacy Messages
+	if err := json.Unmarshal([]byte(`["error"]`), &legacy); err != nil {
+		t.Fatal(err.Error())
+	}
+	if levels := legacy.GetLevels(); len(levels) != 1 || levels[0].Level != LevelError {
+		t.Errorf("plain strings should be read as errors: %#v", levels)
+	}
+}
+
+func TestMessagesLink(t *testing.T) {
+	var m Messages
+	m.Add("missing token")
+	m.Link("https://example.com/auth")
+	m.AddLevel(LevelWarning, "unlinked")
+
+	var other Messages
+	other.Merge(m)
+	if err := other.Suppress("unlinked"); err != nil {
+		t.Fatal(err.Error())
+	}
+
+	marshalled, err := json.Marshal(other)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if expected := `[{"message":"missing token","level":"error","link":"https://example.com/auth"}]`; string(marshalled) != expected {
+		t.Errorf("expected %v [was: %v]", expected, string(marshalled))
+	}
+
+	var unmarshalled Messages
+	if err := json.Unmarshal(marshalled, &unmarshalled); err != nil {
+		t.Fatal(err.Error())
+	}
+	message := unmarshalled.GetLevels()[0]
+	if expected := "\x1b]8;;https://example.com/auth\x1b\\token\x1b]8;;\x1b\\"; message.Hyperlink("token") != expected {
+		t.Errorf("expected %#v [was: %#v]", expected, message.Hyperlink("token"))
+	}
+}
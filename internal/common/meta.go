@@ -1,9 +1,48 @@
 package common
 
+import (
+	"fmt"
+	"regexp"
+)
+
+// MoreMessage is the message (template) for values omitted by a limit.
+const MoreMessage = "… %v more (use narrower prefix)"
+
 type Meta struct {
-	Messages Messages      `json:"messages"`
-	Nospace  SuffixMatcher `json:"nospace"`
-	Usage    string        `json:"usage"`
+	Messages    Messages      `json:"messages"`
+	Nospace     SuffixMatcher `json:"nospace"`
+	Usage       string        `json:"usage"`
+	More        bool          `json:"more,omitempty"`        // further values are available on the next page
+	Order       string        `json:"order,omitempty"`       // ordering of values (`directories` or `tags`)
+	Fold        bool          `json:"fold,omitempty"`        // values match case insensitive (e.g. files on case insensitive filesystems)
+	Sensitive   bool          `json:"sensitive,omitempty"`   // values must not be cached
+	Explanation string        `json:"explanation,omitempty"` // short description of the action for tooling
+
+	Headers map[string]string `json:"-"` // tag -> header shown by zsh instead of the tag (e.g. the title of a command group)
+
+	omitted     int    // amount of values omitted so far (Limit and CARAPACE_MAX_RESULTS)
+	moreMessage string // message added by AddMore
+}
+
+// AddMore marks that further values are available and adds a message with the amount of omitted ones.
+// The message template is passed through translate (e.g. a lookup in the catalog).
+// Repeated calls replace the earlier message with the total amount.
+func (m *Meta) AddMore(omitted int, translate func(string) string) {
+	if m.moreMessage != "" {
+		_ = m.Messages.Suppress("^" + regexp.QuoteMeta(m.moreMessage) + "$")
+	}
+	m.More = true
+	m.omitted += omitted
+	m.moreMessage = fmt.Sprintf(translate(MoreMessage), m.omitted)
+	m.Messages.AddLevel(LevelInfo, m.moreMessage)
+}
+
+// Header sets the header shown for given tag.
+func (m *Meta) Header(tag, header string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[tag] = header
 }
 
 func (m *Meta) Merge(other Meta) {
@@ -12,4 +51,16 @@ func (m *Meta) Merge(other Meta) {
 	}
 	m.Nospace.Merge(other.Nospace)
 	m.Messages.Merge(other.Messages)
+	m.More = m.More || other.More
+	m.Fold = m.Fold || other.Fold
+	m.Sensitive = m.Sensitive || other.Sensitive
+	if other.Order != "" {
+		m.Order = other.Order
+	}
+	if other.Explanation != "" {
+		m.Explanation = other.Explanation
+	}
+	for tag, header := range other.Headers {
+		m.Header(tag, header)
+	}
 }
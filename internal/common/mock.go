@@ -3,11 +3,18 @@ package common
 import (
 	"fmt"
 	"os"
+	"time"
 )
 
 type Mock struct {
 	Dir     string
 	Replies map[string]string
+	Offset  time.Duration // added to the current time (e.g. to expire cache entries)
+}
+
+// Now returns the current time shifted by Offset.
+func (m Mock) Now() time.Time {
+	return time.Now().Add(m.Offset)
 }
 
 func (m Mock) CacheDir() string {
@@ -4,6 +4,7 @@ package common
 import (
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/carapace-sh/carapace/pkg/match"
 	"github.com/carapace-sh/carapace/pkg/style"
@@ -21,6 +22,24 @@ type RawValue struct {
 	Style       string `json:"style,omitempty"`
 	Tag         string `json:"tag,omitempty"`
 	Uid         string `json:"uid,omitempty"`
+	Source      string `json:"source,omitempty"`  // producer of the value (only set with `CARAPACE_PROVENANCE`)
+	Link        string `json:"link,omitempty"`    // url shown as terminal hyperlink (with `CARAPACE_HYPERLINK`)
+	Default     bool   `json:"default,omitempty"` // suggested choice (e.g. the default value of a flag)
+
+	Documentation string   `json:"documentation,omitempty"` // longer text for shells with a preview window
+	Columns       []string `json:"columns,omitempty"`       // fields aligned in the description by shells listing it as column
+	Highlight     string   `json:"-"`                       // part of the display matching the current word
+}
+
+// HighlightSplit splits the display into the parts before, within and after the highlighted match.
+func (r RawValue) HighlightSplit() (before, match, after string) {
+	text, offset := unlinked(r.Display) // skip the link of a hyperlink
+	index := strings.Index(text, r.Highlight)
+	if r.Highlight == "" || index < 0 {
+		return r.Display, "", ""
+	}
+	index += offset
+	return r.Display[:index], r.Highlight, r.Display[index+len(r.Highlight):]
 }
 
 // TrimmedDescription returns the trimmed description.
@@ -47,14 +66,25 @@ func RawValuesFrom(values ...string) RawValues {
 }
 
 func (r RawValues) Unique() RawValues {
+	return r.UniqueF(func(existing, other RawValue) RawValue { return other })
+}
+
+// UniqueF is like Unique but uses given function to merge duplicate values (in order of occurrence).
+func (r RawValues) UniqueF(merge func(existing, other RawValue) RawValue) RawValues {
 	uniqueRawValues := make(map[string]RawValue)
+	order := make([]string, 0, len(r)) // map iteration order is random
 	for _, value := range r {
+		if existing, ok := uniqueRawValues[value.Value]; ok {
+			value = merge(existing, value)
+		} else {
+			order = append(order, value.Value)
+		}
 		uniqueRawValues[value.Value] = value
 	}
 
 	rawValues := make([]RawValue, 0, len(uniqueRawValues))
-	for _, value := range uniqueRawValues {
-		rawValues = append(rawValues, value)
+	for _, value := range order {
+		rawValues = append(rawValues, uniqueRawValues[value])
 	}
 	sort.Sort(ByDisplay(rawValues))
 	return rawValues
@@ -99,6 +129,34 @@ func (r RawValues) Retain(values ...string) RawValues {
 	return filtered
 }
 
+// Suggest marks given values as default.
+func (r RawValues) Suggest(values ...string) RawValues {
+	suggested := make(map[string]bool)
+	for _, v := range values {
+		suggested[v] = true
+	}
+	marked := make(RawValues, len(r))
+	for index, rawValue := range r {
+		if suggested[rawValue.Value] {
+			rawValue.Default = true
+		}
+		marked[index] = rawValue
+	}
+	return marked
+}
+
+// StyleDefault adds the default style to values marked as default.
+func (r RawValues) StyleDefault() RawValues {
+	styled := make(RawValues, len(r))
+	for index, rawValue := range r {
+		if rawValue.Default {
+			rawValue.Style = style.Of(rawValue.Style, style.Carapace.Default)
+		}
+		styled[index] = rawValue
+	}
+	return styled
+}
+
 // Decolor clears style for all values.
 func (r RawValues) Decolor() RawValues {
 	rawValues := make(RawValues, len(r))
@@ -111,15 +169,93 @@ func (r RawValues) Decolor() RawValues {
 
 // FilterPrefix filters values with given prefix.
 func (r RawValues) FilterPrefix(prefix string) RawValues {
+	m := match.Current() // read once instead of for each value
 	filtered := make(RawValues, 0)
 	for _, r := range r {
-		if match.HasPrefix(r.Value, prefix) {
+		if m.HasPrefix(r.Value, prefix) {
 			filtered = append(filtered, r)
 		}
 	}
 	return filtered
 }
 
+// FilterPrefixFold filters values with given prefix ignoring case.
+func (r RawValues) FilterPrefixFold(prefix string) RawValues {
+	filtered := make(RawValues, 0)
+	for _, r := range r {
+		if match.CASE_INSENSITIVE.HasPrefix(r.Value, prefix) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// Highlight marks the part of the displays matching given (already filtered) value.
+// Displays that are not a suffix of the value (e.g. custom ones) are skipped.
+func (r RawValues) Highlight(value string) RawValues {
+	highlighted := make(RawValues, len(r))
+	for index, val := range r {
+		if strings.HasSuffix(val.Value, val.Display) {
+			display := []rune(val.Display)
+			if length := utf8.RuneCountInString(value) - (utf8.RuneCountInString(val.Value) - len(display)); length > 0 && length <= len(display) {
+				val.Highlight = string(display[:length])
+			}
+		}
+		highlighted[index] = val
+	}
+	return highlighted
+}
+
+// CommonValuePrefix returns the longest common prefix of all values.
+func (r RawValues) CommonValuePrefix() (prefix string) {
+	for index, val := range r {
+		if index == 0 {
+			prefix = val.Value
+		} else {
+			prefix = commonPrefix(prefix, val.Value)
+		}
+	}
+	return
+}
+
+// CommonDisplayPrefix returns the longest common prefix of all displays.
+func (r RawValues) CommonDisplayPrefix() (prefix string) {
+	for index, val := range r {
+		if index == 0 {
+			prefix = val.Display
+		} else {
+			prefix = commonPrefix(prefix, val.Display)
+		}
+	}
+	return
+}
+
+func commonPrefix(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	for i > 0 && i < len(a) && !utf8.RuneStart(a[i]) { // don't split multibyte characters
+		i--
+	}
+	return a[0:i]
+}
+
+// FilterTags filters values with given tags.
+func (r RawValues) FilterTags(tags ...string) RawValues {
+	toremove := make(map[string]bool)
+	for _, tag := range tags {
+		toremove[tag] = true
+	}
+	filtered := make(RawValues, 0)
+	for _, rawValue := range r {
+		if _, ok := toremove[rawValue.Tag]; !ok {
+			filtered = append(filtered, rawValue)
+		}
+	}
+	return filtered
+}
+
 func (r RawValues) EachTag(f func(tag string, values RawValues)) {
 	tagGroups := make(map[string]RawValues)
 	for _, val := range r {
@@ -150,6 +286,56 @@ func (a ByValue) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 // ByDisplay alias to filter by display.
 type ByDisplay []RawValue
 
-func (a ByDisplay) Len() int           { return len(a) }
-func (a ByDisplay) Less(i, j int) bool { return a[i].Display < a[j].Display }
-func (a ByDisplay) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a ByDisplay) Len() int { return len(a) }
+func (a ByDisplay) Less(i, j int) bool {
+	if a[i].Display == a[j].Display {
+		return a[i].Value < a[j].Value // deterministic order for equal displays
+	}
+	return a[i].Display < a[j].Display
+}
+func (a ByDisplay) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+// ByDirectory alias to sort directories (values with a `/` suffix) before others (use with sort.Stable).
+type ByDirectory []RawValue
+
+func (a ByDirectory) Len() int { return len(a) }
+func (a ByDirectory) Less(i, j int) bool {
+	return strings.HasSuffix(a[i].Value, "/") && !strings.HasSuffix(a[j].Value, "/")
+}
+func (a ByDirectory) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+
+// ByTag alias to group by tag (use with sort.Stable).
+type ByTag []RawValue
+
+func (a ByTag) Len() int           { return len(a) }
+func (a ByTag) Less(i, j int) bool { return a[i].Tag < a[j].Tag }
+func (a ByTag) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+
+// Columnize aligns the columns of given values in their description (padded to the widest field of each column).
+// Descriptions that were modified since are kept as is.
+func (r RawValues) Columnize() RawValues {
+	widths := make([]int, 0)
+	for _, value := range r {
+		for index, field := range value.Columns {
+			if len(widths) <= index {
+				widths = append(widths, 0)
+			}
+			if width := utf8.RuneCountInString(field); width > widths[index] {
+				widths[index] = width
+			}
+		}
+	}
+
+	columnized := make(RawValues, len(r))
+	for index, value := range r {
+		if len(value.Columns) > 0 && value.Description == strings.Join(value.Columns, "  ") {
+			fields := make([]string, 0, len(value.Columns))
+			for i, field := range value.Columns {
+				fields = append(fields, field+strings.Repeat(" ", widths[i]-utf8.RuneCountInString(field)))
+			}
+			value.Description = strings.TrimRight(strings.Join(fields, "  "), " ")
+		}
+		columnized[index] = value
+	}
+	return columnized
+}
@@ -44,6 +44,23 @@ func TestFilterPrefix(t *testing.T) {
 	}
 }
 
+func TestFilterPrefixFold(t *testing.T) {
+	v := RawValuesFrom("First", "Second").FilterPrefixFold("sEc")
+	if len(v) != 1 || v[0].Value != "Second" {
+		t.Errorf("unexpected values: %#v", v)
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	v := RawValues{
+		{Value: "first", Display: "first", Tag: "a"},
+		{Value: "second", Display: "second", Tag: "b"},
+	}.FilterTags("a")
+	if len(v) != 1 || v[0].Value != "second" {
+		t.Fail()
+	}
+}
+
 func equalRawValues(a, b RawValue) bool {
 	return a.Value == b.Value && a.Display == b.Display && a.Description == b.Description
 }
@@ -61,3 +78,116 @@ func TestSort(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestIconify(t *testing.T) {
+	v := RawValues{
+		{Value: "main.go", Display: "main.go", Tag: "files"},
+		{Value: "docs/", Display: "docs/", Tag: "directories"},
+		{Value: "\u002d\u002dhelp", Display: "\u002d\u002dhelp", Tag: "longhand flags"},
+		{Value: "plain", Display: "plain"},
+	}.Iconify()
+
+	expected := []string{"\ue627 main.go", "\uf07b docs/", "\uf024 --help", "plain"}
+	for index, value := range v {
+		if value.Display != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], value.Display)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	v := RawValues{
+		{Value: "prefix-äa", Display: "äa"},
+		{Value: "prefix-äb", Display: "äb"},
+		{Value: "prefix-öc", Display: "öc"},
+	}
+	if prefix := v.CommonValuePrefix(); prefix != "prefix-" {
+		t.Errorf("unexpected value prefix: %#v", prefix)
+	}
+	if prefix := v.CommonDisplayPrefix(); prefix != "" {
+		t.Errorf("unexpected display prefix: %#v", prefix)
+	}
+	if prefix := v[:2].CommonDisplayPrefix(); prefix != "ä" {
+		t.Errorf("unexpected display prefix: %#v", prefix)
+	}
+}
+
+func TestByDirectory(t *testing.T) {
+	v := RawValuesFrom("b", "a/", "c/", "a")
+	sort.Sort(ByDisplay(v))
+	sort.Stable(ByDirectory(v))
+
+	expected := []string{"a/", "c/", "a", "b"}
+	for index, value := range v {
+		if value.Value != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], value.Value)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	v := RawValues{
+		{Value: "prefix/äbc", Display: "äbc"},
+		{Value: "prefix/äx", Display: "prefix/äx"},
+		{Value: "prefix/ä-long", Display: "-f"},
+	}.Highlight("prefix/ä")
+
+	expected := []string{"ä", "prefix/ä", ""}
+	for index, value := range v {
+		if value.Highlight != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], value.Highlight)
+		}
+	}
+
+	if before, match, after := (RawValue{Display: " äbc", Highlight: "ä"}).HighlightSplit(); before != " " || match != "ä" || after != "bc" {
+		t.Errorf("unexpected split: %#v %#v %#v", before, match, after)
+	}
+}
+
+func TestLabelize(t *testing.T) {
+	v := RawValues{
+		{Value: "main.go", Display: "main.go", Tag: "files"},
+		{Value: "docs/", Display: "docs/", Tag: "directories"},
+		{Value: "--help", Display: "--help", Tag: "longhand flags"},
+		{Value: "sub", Display: "sub", Tag: "main commands"},
+		{Value: "plain", Display: "plain"},
+	}.Labelize()
+
+	expected := []string{"[file] main.go", "[dir] docs/", "[flag] --help", "[cmd] sub", "plain"}
+	for index, value := range v {
+		if value.Display != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], value.Display)
+		}
+	}
+}
+
+func TestUniqueDeterministic(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		v := RawValues{
+			{Value: "b", Display: "same"},
+			{Value: "a", Display: "same"},
+			{Value: "c", Display: "same"},
+			{Value: "a", Display: "same", Description: "later"},
+		}.Unique()
+
+		if len(v) != 3 || v[0].Value != "a" || v[0].Description != "later" || v[1].Value != "b" || v[2].Value != "c" {
+			t.Fatalf("unexpected order: %#v", v)
+		}
+	}
+}
+
+func TestColumnize(t *testing.T) {
+	v := RawValues{
+		{Value: "1", Description: "root  init", Columns: []string{"root", "init"}},
+		{Value: "1234", Description: "user  /usr/bin/bash", Columns: []string{"user", "/usr/bin/bash"}},
+		{Value: "56", Description: "nobody", Columns: []string{"nobody"}},
+		{Value: "7", Description: "modified", Columns: []string{"daemon"}},
+	}.Columnize()
+
+	expected := []string{"root    init", "user    /usr/bin/bash", "nobody", "modified"}
+	for index, value := range v {
+		if value.Description != expected[index] {
+			t.Errorf("expected %#v [was: %#v]", expected[index], value.Description)
+		}
+	}
+}
@@ -4,34 +4,79 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/common"
 )
 
 const (
+	CARAPACE_ACCESSIBLE    = "CARAPACE_ACCESSIBLE"    // screen reader friendly output (no colors or icons, labeled displays)
 	CARAPACE_COVERDIR      = "CARAPACE_COVERDIR"      // coverage directory for sandbox tests
+	CARAPACE_DISABLED_TAGS = "CARAPACE_DISABLED_TAGS" // tags to hide
+	CARAPACE_DOTFILES      = "CARAPACE_DOTFILES"      // include dotfiles (always, never)
 	CARAPACE_EXPERIMENTAL  = "CARAPACE_EXPERIMENTAL"  // enable experimental features
-	CARAPACE_HIDDEN        = "CARAPACE_HIDDEN"        // show hidden commands/flags
+	CARAPACE_FISH_NOSORT   = "CARAPACE_FISH_NOSORT"   // disable sorting in fish (complete --keep-order)
+	CARAPACE_FISH_QUOTE    = "CARAPACE_FISH_QUOTE"    // quote left open in the current word (fish)
+	CARAPACE_FZF           = "CARAPACE_FZF"           // use fzf for selection in bash/zsh snippets
+	CARAPACE_HIDDEN        = "CARAPACE_HIDDEN"        // show hidden commands/flags and deprecated flags
+	CARAPACE_HYPERLINK     = "CARAPACE_HYPERLINK"     // render links as terminal hyperlinks (opt-in)
+	CARAPACE_ICONS         = "CARAPACE_ICONS"         // prefix displays with nerd font icons
 	CARAPACE_LENIENT       = "CARAPACE_LENIENT"       // allow unknown flags
-	CARAPACE_LOG           = "CARAPACE_LOG"           // enable logging
+	CARAPACE_LOG           = "CARAPACE_LOG"           // enable logging (trace, debug, info, error)
+	CARAPACE_LOG_FORMAT    = "CARAPACE_LOG_FORMAT"    // log format (text, json)
 	CARAPACE_MATCH         = "CARAPACE_MATCH"         // match case insensitive
+	CARAPACE_MAX_ENTRIES   = "CARAPACE_MAX_ENTRIES"   // maximum amount of directory entries
+	CARAPACE_MAX_RESULTS   = "CARAPACE_MAX_RESULTS"   // maximum amount of values
 	CARAPACE_NOSPACE       = "CARAPACE_NOSPACE"       // nospace suffixes
+	CARAPACE_ORDER         = "CARAPACE_ORDER"         // order of values (directories, tags)
+	CARAPACE_PAGE          = "CARAPACE_PAGE"          // page of limited values
+	CARAPACE_PLAIN         = "CARAPACE_PLAIN"         // machine-stable output (no styles, hyperlinks, icons or user settings)
+	CARAPACE_PROFILE       = "CARAPACE_PROFILE"       // log durations of callbacks, executed commands and serialization
+	CARAPACE_PROVENANCE    = "CARAPACE_PROVENANCE"    // add the source of values to export
+	CARAPACE_RECORD        = "CARAPACE_RECORD"        // file to record completion invocations to
 	CARAPACE_SANDBOX       = "CARAPACE_SANDBOX"       // mock context for sandbox tests
+	CARAPACE_SHELL_ENV     = "CARAPACE_SHELL_ENV"     // snapshot of shell variables (passed by the snippet)
+	CARAPACE_SNIPPET_CACHE = "CARAPACE_SNIPPET_CACHE" // cache generated snippets (default true)
+	CARAPACE_TCSH_NODESC   = "CARAPACE_TCSH_NODESC"   // strip descriptions in tcsh
 	CARAPACE_TOOLTIP       = "CARAPACE_TOOLTIP"       // enable tooltip style
 	CARAPACE_ZSH_HASH_DIRS = "CARAPACE_ZSH_HASH_DIRS" // zsh hash directories
+	CARAPACE_ZSH_QUOTE     = "CARAPACE_ZSH_QUOTE"     // quote left open in the current word (zsh)
 	CLICOLOR               = "CLICOLOR"               // disable color
 	NO_COLOR               = "NO_COLOR"               // disable color
 )
 
+// Accessible returns true if output should be screen reader friendly (`CARAPACE_ACCESSIBLE`).
+func Accessible() bool {
+	return !Plain() && getBool(CARAPACE_ACCESSIBLE)
+}
+
 func ColorDisabled() bool {
-	return getBool(NO_COLOR) || os.Getenv(CLICOLOR) == "0"
+	return Plain() || Accessible() || os.Getenv(NO_COLOR) != "" || os.Getenv(CLICOLOR) == "0"
+}
+
+func DisabledTags() []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(os.Getenv(CARAPACE_DISABLED_TAGS), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func Dotfiles() string {
+	return os.Getenv(CARAPACE_DOTFILES)
 }
 
 func Experimental() bool {
 	return getBool(CARAPACE_EXPERIMENTAL)
 }
 
+func Fzf() bool {
+	return getBool(CARAPACE_FZF)
+}
+
 func Lenient() bool {
 	return getBool(CARAPACE_LENIENT)
 }
@@ -40,6 +85,33 @@ func Hashdirs() string {
 	return os.Getenv(CARAPACE_ZSH_HASH_DIRS)
 }
 
+// ShellEnv returns the snapshot of shell variables (`name=value` per line) passed by the snippet.
+// It contains variables that aren't exported and thus don't reach the process environment.
+func ShellEnv() []string {
+	shellEnv := make([]string, 0)
+	for _, line := range strings.Split(os.Getenv(CARAPACE_SHELL_ENV), "\n") {
+		if index := strings.Index(line, "="); index > 0 {
+			shellEnv = append(shellEnv, line)
+		}
+	}
+	return shellEnv
+}
+
+// FishNosort returns true if fish keeps the order of values (`CARAPACE_FISH_NOSORT`).
+func FishNosort() bool {
+	return getBool(CARAPACE_FISH_NOSORT)
+}
+
+// FishQuote returns the quote left open in the current word as passed by the fish snippet (`CARAPACE_FISH_QUOTE`).
+func FishQuote() string {
+	return os.Getenv(CARAPACE_FISH_QUOTE)
+}
+
+// ZshQuote returns the quote left open in the current word as passed by the zsh snippet (`CARAPACE_ZSH_QUOTE`).
+func ZshQuote() string {
+	return os.Getenv(CARAPACE_ZSH_QUOTE)
+}
+
 func Sandbox() (m *common.Mock, err error) {
 	sandbox := os.Getenv(CARAPACE_SANDBOX)
 	if sandbox == "" || !isGoRun() {
@@ -51,13 +123,41 @@ func Sandbox() (m *common.Mock, err error) {
 }
 
 func Log() bool {
-	return getBool(CARAPACE_LOG)
+	switch os.Getenv(CARAPACE_LOG) {
+	case "", "false", "0":
+		return false
+	default:
+		return true
+	}
+}
+
+func LogLevel() string {
+	return os.Getenv(CARAPACE_LOG)
+}
+
+func LogFormat() string {
+	return os.Getenv(CARAPACE_LOG_FORMAT)
 }
 
 func Hidden() bool {
 	return getBool(CARAPACE_HIDDEN)
 }
 
+// Hyperlink returns true if links should be rendered as terminal hyperlinks (OSC 8).
+func Hyperlink() bool {
+	return !Plain() && !Accessible() && getBool(CARAPACE_HYPERLINK)
+}
+
+func Icons() bool {
+	return !Plain() && !Accessible() && getBool(CARAPACE_ICONS)
+}
+
+// Plain returns true if the output is consumed programmatically (`_carapace {shell} --plain ...`)
+// and thus needs to be stable regardless of terminal and user settings.
+func Plain() bool {
+	return getBool(CARAPACE_PLAIN)
+}
+
 func CoverDir() string {
 	return os.Getenv(CARAPACE_COVERDIR) // custom env for GOCOVERDIR so that it works together with `-coverprofile`
 }
@@ -68,12 +168,65 @@ func Match() string { // see match.Match
 	return os.Getenv(CARAPACE_MATCH)
 }
 
+func MaxEntries() int {
+	if i, err := strconv.Atoi(os.Getenv(CARAPACE_MAX_ENTRIES)); err == nil && i > 0 {
+		return i
+	}
+	return 0 // unlimited
+}
+
+func MaxResults() int {
+	if i, err := strconv.Atoi(os.Getenv(CARAPACE_MAX_RESULTS)); err == nil && i > 0 {
+		return i
+	}
+	return 0
+}
+
 func Nospace() string {
 	return os.Getenv(CARAPACE_NOSPACE)
 }
 
+func Order() string {
+	return os.Getenv(CARAPACE_ORDER)
+}
+
+func Page() int {
+	if i, err := strconv.Atoi(os.Getenv(CARAPACE_PAGE)); err == nil && i > 0 {
+		return i
+	}
+	return 1
+}
+
+// Profile returns true if durations of callbacks, executed commands and serialization are logged (`CARAPACE_PROFILE`).
+func Profile() bool {
+	return getBool(CARAPACE_PROFILE)
+}
+
+func Provenance() bool {
+	return getBool(CARAPACE_PROVENANCE)
+}
+
+func Record() string {
+	return os.Getenv(CARAPACE_RECORD)
+}
+
+func SnippetCache() bool {
+	switch os.Getenv(CARAPACE_SNIPPET_CACHE) {
+	case "false", "0":
+		return false
+	case "true", "1":
+		return true
+	default:
+		return !isGoRun() // binaries of `go run` and `go test` are rebuilt anyway
+	}
+}
+
+func TcshNodesc() bool {
+	return getBool(CARAPACE_TCSH_NODESC)
+}
+
 func Tooltip() bool {
-	return getBool(CARAPACE_TOOLTIP)
+	return !Plain() && getBool(CARAPACE_TOOLTIP)
 }
 
 func getBool(s string) bool {
@@ -0,0 +1,155 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/xdg"
+)
+
+// Setting is a runtime setting backed by an environment variable.
+type Setting struct {
+	Name        string
+	Env         string
+	Description string
+}
+
+var settings = []Setting{
+	{"dotfiles", CARAPACE_DOTFILES, "include dotfiles (always, never)"},
+	{"hidden", CARAPACE_HIDDEN, "show hidden commands/flags and deprecated flags"},
+	{"icons", CARAPACE_ICONS, "prefix displays with nerd font icons"},
+	{"lenient", CARAPACE_LENIENT, "allow unknown flags"},
+	{"match", CARAPACE_MATCH, "match case insensitive"},
+	{"maxentries", CARAPACE_MAX_ENTRIES, "maximum amount of directory entries (default unlimited)"},
+	{"maxresults", CARAPACE_MAX_RESULTS, "maximum amount of values"},
+	{"nocolor", NO_COLOR, "disable color"},
+	{"nospace", CARAPACE_NOSPACE, "nospace suffixes"},
+	{"order", CARAPACE_ORDER, "order of values (directories, tags)"},
+	{"disabledtags", CARAPACE_DISABLED_TAGS, "comma separated list of tags to hide"},
+	{"tooltip", CARAPACE_TOOLTIP, "enable tooltip style"},
+}
+
+// GetSettings returns all known settings.
+func GetSettings() []Setting { return settings }
+
+func lookupSetting(name string) (Setting, error) {
+	for _, s := range settings {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return Setting{}, fmt.Errorf("unknown setting: '%v'", name)
+}
+
+// SettingsFile returns the path of the settings file for the current executable.
+func SettingsFile() (string, error) {
+	dir, err := xdg.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(filepath.Join(dir, "carapace", uid.Executable(), "settings.json")), nil
+}
+
+// LoadSettings reads the settings for the current executable.
+func LoadSettings() (map[string]string, error) {
+	file, err := SettingsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+
+	var s map[string]string
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadSettingsEnv sets the environment variables of configured settings unless they are already set.
+func LoadSettingsEnv() error {
+	s, err := LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		setting, err := lookupSetting(name)
+		if err != nil {
+			continue // ignore settings removed in newer versions
+		}
+		if _, ok := os.LookupEnv(setting.Env); !ok {
+			if err := os.Setenv(setting.Env, s[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetSetting returns the effective value of given setting (environment variable takes precedence).
+func GetSetting(name string) (string, error) {
+	setting, err := lookupSetting(name)
+	if err != nil {
+		return "", err
+	}
+
+	if value, ok := os.LookupEnv(setting.Env); ok {
+		return value, nil
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		return "", err
+	}
+	return s[name], nil
+}
+
+// SetSetting stores given setting for the current executable (empty value removes it).
+func SetSetting(name, value string) error {
+	if _, err := lookupSetting(name); err != nil {
+		return err
+	}
+
+	s, err := LoadSettings()
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(value) == "" {
+		delete(s, name)
+	} else {
+		s[name] = value
+	}
+
+	file, err := SettingsFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	marshalled, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, marshalled, 0600)
+}
@@ -0,0 +1,61 @@
+package env
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	os.Unsetenv(CARAPACE_MATCH)
+	defer os.Unsetenv(CARAPACE_MATCH)
+
+	if s, err := LoadSettings(); err != nil || len(s) != 0 {
+		t.Errorf("expected no settings [was: %v, %v]", s, err)
+	}
+
+	if err := SetSetting("unknown", "value"); err == nil {
+		t.Error("expected error for unknown setting")
+	}
+
+	if err := SetSetting("match", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := LoadSettings(); err != nil || s["match"] != "1" {
+		t.Errorf("expected match=1 [was: %v, %v]", s, err)
+	}
+	if value, err := GetSetting("match"); err != nil || value != "1" {
+		t.Errorf("expected 1 [was: %v, %v]", value, err)
+	}
+
+	file, err := SettingsFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(file); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600: %v", info.Mode().Perm())
+	}
+
+	t.Setenv(CARAPACE_MATCH, "0")
+	if value, err := GetSetting("match"); err != nil || value != "0" {
+		t.Errorf("expected environment variable to take precedence [was: %v, %v]", value, err)
+	}
+	if err := LoadSettingsEnv(); err != nil || os.Getenv(CARAPACE_MATCH) != "0" {
+		t.Errorf("expected environment variable to be kept [was: %v, %v]", os.Getenv(CARAPACE_MATCH), err)
+	}
+
+	os.Unsetenv(CARAPACE_MATCH)
+	if err := LoadSettingsEnv(); err != nil || os.Getenv(CARAPACE_MATCH) != "1" {
+		t.Errorf("expected environment variable to be set [was: %v, %v]", os.Getenv(CARAPACE_MATCH), err)
+	}
+
+	if err := SetSetting("match", ""); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := LoadSettings(); err != nil || len(s) != 0 {
+		t.Errorf("expected setting to be removed [was: %v, %v]", s, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 type Export struct {
 	Version string `json:"version"`
 	common.Meta
+	Prefix string           `json:"prefix,omitempty"` // longest common prefix of the values
 	Values common.RawValues `json:"values"`
 }
 
@@ -19,10 +20,12 @@ func (e Export) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
 		Version string `json:"version"`
 		common.Meta
+		Prefix string           `json:"prefix,omitempty"`
 		Values common.RawValues `json:"values"`
 	}{
 		Version: version(),
 		Meta:    e.Meta,
+		Prefix:  e.Prefix,
 		Values:  e.Values,
 	})
 }
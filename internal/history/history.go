@@ -0,0 +1,31 @@
+// Package history provides rudimentary extraction of urls from shell history files
+package history
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var url = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s'"<>\;|&()]+`)
+
+// Urls returns the urls with given scheme contained in given history (without duplicates).
+// Entries are returned in the order of their first occurrence.
+func Urls(r io.Reader, scheme string) ([]string, error) {
+	urls := make([]string, 0)
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		for _, match := range url.FindAllString(scanner.Text(), -1) {
+			if !strings.HasPrefix(match, scheme+"://") || seen[match] {
+				continue
+			}
+			seen[match] = true
+			urls = append(urls, match)
+		}
+	}
+	return urls, scanner.Err()
+}
@@ -0,0 +1,28 @@
+package history
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUrls(t *testing.T) {
+	urls, err := Urls(strings.NewReader(`
+curl https://example.com/api?q=1 | jq
+: 1700000000:0;git clone 'https://github.com/carapace-sh/carapace.git'
+- cmd: wget http://example.com/file.txt
+curl https://example.com/api?q=1
+git clone ssh://git@example.com/repo.git
+`), "https")
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := []string{
+		"https://example.com/api?q=1",
+		"https://github.com/carapace-sh/carapace.git",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %#v [was: %#v]", expected, urls)
+	}
+}
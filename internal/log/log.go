@@ -1,33 +1,191 @@
 package log
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/carapace-sh/carapace/internal/env"
-
 	"github.com/carapace-sh/carapace/pkg/ps"
 	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/xdg"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+const (
+	LevelTrace Level = iota
+	LevelDebug
+	LevelInfo
+	LevelError
 )
 
-var LOG = log.New(io.Discard, "", log.Flags())
+var levelNames = []string{"trace", "debug", "info", "error"}
+
+func (l Level) String() string {
+	if l < LevelTrace || l > LevelError {
+		return fmt.Sprintf("level(%d)", l)
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses given level name (`true` and `1` map to debug).
+func ParseLevel(s string) (Level, error) {
+	switch s = strings.ToLower(s); s {
+	case "true", "1":
+		return LevelDebug, nil
+	}
+	for index, name := range levelNames {
+		if name == s {
+			return Level(index), nil
+		}
+	}
+	return LevelError, fmt.Errorf("unknown log level: %v", s)
+}
+
+// Logger writes leveled entries tagged with the id of the current invocation.
+type Logger struct {
+	mutex sync.Mutex
+	out   io.Writer
+	level Level
+	json  bool
+	id    string
+	shell string
+}
+
+// New creates a logger writing entries with given minimum level to w.
+func New(w io.Writer, level Level, json bool) *Logger {
+	return &Logger{
+		out:   w,
+		level: level,
+		json:  json,
+		id:    invocationID(),
+		shell: ps.DetermineShell(),
+	}
+}
+
+// ID returns the id of the current invocation.
+func (l *Logger) ID() string { return l.id }
+
+// Enabled returns true if entries with given level are written.
+func (l *Logger) Enabled(level Level) bool {
+	return l.out != io.Discard && level >= l.level
+}
+
+func (l *Logger) Tracef(format string, args ...interface{}) { l.logf(LevelTrace, format, args...) }
+func (l *Logger) Debugf(format string, args ...interface{}) { l.logf(LevelDebug, format, args...) }
+func (l *Logger) Infof(format string, args ...interface{})  { l.logf(LevelInfo, format, args...) }
+func (l *Logger) Errorf(format string, args ...interface{}) { l.logf(LevelError, format, args...) }
+
+// Writer returns a writer adding each write as an entry with given level.
+func (l *Logger) Writer(level Level) io.Writer {
+	if !l.Enabled(level) {
+		return io.Discard
+	}
+	return levelWriter{l, level}
+}
+
+func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if l.Enabled(level) {
+		l.write(level, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *Logger) write(level Level, msg string) {
+	msg = strings.TrimSuffix(msg, "\n")
+	now := time.Now()
+
+	var line string
+	if l.json {
+		m, _ := json.Marshal(struct {
+			Time    string `json:"time"`
+			Level   string `json:"level"`
+			ID      string `json:"id"`
+			Shell   string `json:"shell,omitempty"`
+			Message string `json:"message"`
+		}{now.Format(time.RFC3339Nano), level.String(), l.id, l.shell, msg})
+		line = string(m)
+	} else {
+		line = fmt.Sprintf("%v %v %v %-5v %v", now.Format("2006/01/02 15:04:05.000000"), l.id, l.shell, strings.ToUpper(level.String()), msg)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	fmt.Fprintln(l.out, line)
+}
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	w.logger.write(w.level, string(p))
+	return len(p), nil
+}
+
+func invocationID() string {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%08x", uint32(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Default is the logger of the current invocation (enabled with `CARAPACE_LOG`).
+var Default = &Logger{out: io.Discard, id: invocationID()}
+
+// File returns the path of the log file for the current user and executable.
+// Within the sandbox it is located in the cache folder of the mock.
+func File() (string, error) {
+	dir, err := xdg.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if m, sandboxErr := env.Sandbox(); sandboxErr == nil {
+		dir = m.CacheDir()
+	}
+	return fmt.Sprintf("%v/carapace/%v.log", dir, uid.Executable()), nil
+}
 
 func init() {
+	setup()
+}
+
+// setup enables logging to File if `CARAPACE_LOG` is set.
+// Unknown levels fall back to debug and failures leave logging disabled
+// as this runs for every invocation of the program (not just completion).
+func setup() {
 	if !env.Log() {
 		return
 	}
 
-	tmpdir := fmt.Sprintf("%v/carapace", os.TempDir())
-	if err := os.MkdirAll(tmpdir, os.ModePerm); err != nil {
-		log.Fatal(err.Error())
+	level, err := ParseLevel(env.LogLevel())
+	if err != nil {
+		level = LevelDebug // e.g. `CARAPACE_LOG=yes`
 	}
 
-	file := fmt.Sprintf("%v/%v.log", tmpdir, uid.Executable())
-	if logfileWriter, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666); err != nil {
-		log.Fatal(err.Error())
-	} else {
-		LOG = log.New(logfileWriter, ps.DetermineShell()+" ", log.Flags()|log.Lmsgprefix|log.Lmicroseconds)
+	file, err := File()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return
+	}
+
+	logfileWriter, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
 	}
+	Default = New(logfileWriter, level, env.LogFormat() == "json")
 }
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New(&buffer, LevelDebug, false)
+	logger.Tracef("hidden")
+	logger.Debugf("shown %v", 1)
+	logger.Writer(LevelError).Write([]byte("written\n"))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries: %#v", lines)
+	}
+	if !strings.Contains(lines[0], " "+logger.ID()+" ") || !strings.HasSuffix(lines[0], "DEBUG shown 1") {
+		t.Errorf("unexpected entry: %#v", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "ERROR written") {
+		t.Errorf("unexpected entry: %#v", lines[1])
+	}
+}
+
+func TestLoggerJson(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := New(&buffer, LevelTrace, true)
+	logger.Infof("message")
+
+	var entry map[string]string
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err.Error())
+	}
+	if entry["level"] != "info" || entry["message"] != "message" || entry["id"] != logger.ID() {
+		t.Errorf("unexpected entry: %#v", entry)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, expected := range map[string]Level{"1": LevelDebug, "true": LevelDebug, "trace": LevelTrace, "ERROR": LevelError} {
+		if level, err := ParseLevel(s); err != nil || level != expected {
+			t.Errorf("expected %v for %#v [was: %v]", expected, s, level)
+		}
+	}
+	if _, err := ParseLevel("unknown"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer func(logger *Logger) { Default = logger }(Default)
+
+	cacheFile := t.TempDir() + "/file"
+	if err := os.WriteFile(cacheFile, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", cacheFile) // not a directory
+	t.Setenv("CARAPACE_LOG", "yes")
+	setup() // must not exit
+	if Default.out != io.Discard {
+		t.Error("expected logging to stay disabled")
+	}
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	setup()
+	if Default.level != LevelDebug {
+		t.Errorf("expected fallback to debug level [was: %v]", Default.level)
+	}
+}
+
+func TestFileSandbox(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CARAPACE_SANDBOX", `{"Dir":"`+dir+`"}`)
+	file, err := File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(file, dir+"/cache/") {
+		t.Errorf("expected log file within sandbox [was: %v]", file)
+	}
+}
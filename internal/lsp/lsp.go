@@ -0,0 +1,137 @@
+// Package lsp provides a minimal JSON-RPC 2.0 server using the message framing of the language server protocol.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Error codes as defined by JSON-RPC 2.0 and the language server protocol.
+const (
+	ParseError           = -32700
+	InvalidRequest       = -32600
+	MethodNotFound       = -32601
+	InvalidParams        = -32602
+	InternalError        = -32603
+	ServerNotInitialized = -32002
+)
+
+// MaxContentLength limits the size of a single message to guard against bogus Content-Length headers.
+const MaxContentLength = 16 << 20 // 16MiB
+
+// Error is a JSON-RPC error returned by a Handler to control the error code.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Handler handles given method.
+// Results of notifications (requests without an id) are discarded.
+type Handler func(method string, params json.RawMessage) (interface{}, error)
+
+type request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params"`
+}
+
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+type errorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   *Error           `json:"error"`
+}
+
+// Serve reads requests from r and writes responses to w until r is closed or `exit` is received.
+func Serve(r io.Reader, w io.Writer, handler Handler) error {
+	reader := bufio.NewReader(r)
+	for {
+		content, err := Read(reader)
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := Write(w, errorResponse{"2.0", nil, &Error{ParseError, err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result, err := handler(req.Method, req.Params)
+		switch {
+		case req.Method == "exit":
+			return nil
+		case req.ID == nil: // notification
+			continue
+		case err != nil:
+			var rpcErr *Error
+			if !errors.As(err, &rpcErr) {
+				rpcErr = &Error{InternalError, err.Error()}
+			}
+			err = Write(w, errorResponse{"2.0", req.ID, rpcErr})
+		default:
+			err = Write(w, response{"2.0", req.ID, result})
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Read reads the content of the next message.
+//
+//	Content-Length: 52\r\n
+//	\r\n
+//	{"jsonrpc":"2.0","id":1,"method":"initialize"}
+func Read(r *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %v", err.Error())
+	}
+	if length < 0 || length > MaxContentLength {
+		return nil, fmt.Errorf("invalid Content-Length: %v (expected 0-%v)", length, MaxContentLength)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// Write writes given message with a Content-Length header.
+func Write(w io.Writer, message interface{}) error {
+	m, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %v\r\n\r\n%s", len(m), m)
+	return err
+}
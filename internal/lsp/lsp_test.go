@@ -0,0 +1,88 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func message(content string) string {
+	return fmt.Sprintf("Content-Length: %v\r\n\r\n%v", len(content), content)
+}
+
+func TestServe(t *testing.T) {
+	input := strings.Join([]string{
+		message(`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"a":1}}`),
+		message(`{"jsonrpc":"2.0","method":"notify"}`),
+		message(`{"jsonrpc":"2.0","id":"two","method":"unknown"}`),
+		message(`{"jsonrpc":"2.0","id":3,"method":"fail"}`),
+		message(`invalid`),
+		message(`{"jsonrpc":"2.0","method":"exit"}`),
+		message(`{"jsonrpc":"2.0","id":4,"method":"echo"}`), // after exit
+	}, "")
+
+	notified := false
+	var output bytes.Buffer
+	err := Serve(strings.NewReader(input), &output, func(method string, params json.RawMessage) (interface{}, error) {
+		switch method {
+		case "echo":
+			return params, nil
+		case "notify":
+			notified = true
+			return "ignored", nil
+		case "fail":
+			return nil, errors.New("failed")
+		case "exit":
+			return nil, nil
+		default:
+			return nil, &Error{MethodNotFound, "unknown method: " + method}
+		}
+	})
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if !notified {
+		t.Error("notification not handled")
+	}
+
+	expected := []string{
+		`{"jsonrpc":"2.0","id":1,"result":{"a":1}}`,
+		`{"jsonrpc":"2.0","id":"two","error":{"code":-32601,"message":"unknown method: unknown"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32603,"message":"failed"}}`,
+		`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"invalid character 'i' looking for beginning of value"}}`,
+	}
+
+	reader := bufio.NewReader(&output)
+	for _, e := range expected {
+		content, err := Read(reader)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if string(content) != e {
+			t.Errorf("expected %v [was: %s]", e, content)
+		}
+	}
+	if content, err := Read(reader); err == nil {
+		t.Errorf("unexpected response: %s", content)
+	}
+}
+
+func TestReadInvalidHeader(t *testing.T) {
+	for _, header := range []string{"x", "-1", strconv.Itoa(MaxContentLength + 1)} {
+		if _, err := Read(bufio.NewReader(strings.NewReader("Content-Length: " + header + "\r\n\r\n{}"))); err == nil {
+			t.Errorf("expected error for %#v", header)
+		}
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	if _, err := Read(bufio.NewReader(strings.NewReader("Content-Length: 10\r\n\r\n{}"))); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF: %v", err)
+	}
+}
@@ -46,6 +46,22 @@ func (f Flag) OptargDelimiter() rune {
 	return '='
 }
 
+// DefaultValues returns the default value of the flag split into its elements (empty if unset).
+func (f Flag) DefaultValues() []string {
+	value := f.DefValue
+	if f.IsRepeatable() && strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+		if value == "" {
+			return []string{}
+		}
+		return strings.Split(value, ",")
+	}
+	if value == "" {
+		return []string{}
+	}
+	return []string{value}
+}
+
 func (f Flag) IsRepeatable() bool {
 	if strings.Contains(f.Value.Type(), "Slice") ||
 		strings.Contains(f.Value.Type(), "Array") ||
@@ -69,6 +85,25 @@ func (f Flag) IsOptarg() bool {
 }
 
 func (f Flag) Style() string {
+	switch {
+	case f.Deprecated != "":
+		return style.Carapace.FlagDeprecated
+	case f.Hidden:
+		return style.Of(f.argStyle(), style.Carapace.FlagHidden)
+	default:
+		return f.argStyle()
+	}
+}
+
+// ShorthandStyle is like Style but also considers the deprecation of the shorthand.
+func (f Flag) ShorthandStyle() string {
+	if f.ShorthandDeprecated != "" {
+		return style.Carapace.FlagDeprecated
+	}
+	return f.Style()
+}
+
+func (f Flag) argStyle() string {
 	switch {
 	case !f.TakesValue():
 		return style.Carapace.FlagNoArg
@@ -81,6 +116,22 @@ func (f Flag) Style() string {
 	}
 }
 
+// Description returns the usage of the flag or the deprecation note if it is deprecated.
+func (f Flag) Description() string {
+	if f.Deprecated != "" {
+		return "deprecated: " + f.Deprecated
+	}
+	return f.Usage
+}
+
+// ShorthandDescription is like Description but also considers the deprecation of the shorthand.
+func (f Flag) ShorthandDescription() string {
+	if f.ShorthandDeprecated != "" {
+		return "deprecated: " + f.ShorthandDeprecated
+	}
+	return f.Description()
+}
+
 func (f Flag) Required() bool {
 	if annotation := f.Annotations[cobra.BashCompOneRequiredFlag]; len(annotation) == 1 && annotation[0] == "true" {
 		return true
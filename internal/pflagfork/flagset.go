@@ -10,6 +10,7 @@ import (
 
 type FlagSet struct {
 	*pflag.FlagSet
+	SingleDash bool // longhand flags may also be passed with a single dash (`-name`)
 }
 
 func (f FlagSet) IsInterspersed() bool {
@@ -30,7 +31,7 @@ func (f FlagSet) IsPosix() bool {
 
 func (f FlagSet) IsShorthandSeries(arg string) bool {
 	re := regexp.MustCompile("^-(?P<shorthand>[^-].*)")
-	return re.MatchString(arg) && f.IsPosix()
+	return re.MatchString(arg) && f.IsPosix() && !f.SingleDash
 }
 
 func (f FlagSet) IsMutuallyExclusive(flag *pflag.Flag) bool {
@@ -46,6 +47,23 @@ func (f FlagSet) IsMutuallyExclusive(flag *pflag.Flag) bool {
 	return false
 }
 
+// IsRequiredTogether returns true if given flag is not set but another one of its `MarkFlagsRequiredTogether` group is.
+func (f FlagSet) IsRequiredTogether(flag *pflag.Flag) bool {
+	if flag.Changed {
+		return false
+	}
+	if groups, ok := flag.Annotations["cobra_annotation_required_if_others_set"]; ok {
+		for _, group := range groups {
+			for _, name := range strings.Split(group, " ") {
+				if other := f.Lookup(name); other != nil && other.Changed {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 func (f *FlagSet) VisitAll(fn func(*Flag)) {
 	f.FlagSet.VisitAll(func(flag *pflag.Flag) {
 		fn(&Flag{Flag: flag, Args: []string{}})
@@ -56,6 +74,12 @@ func (f *FlagSet) VisitAll(fn func(*Flag)) {
 func (fs FlagSet) LookupArg(arg string) (result *Flag) {
 	isPosix := fs.IsPosix()
 
+	if fs.SingleDash {
+		if flag := fs.lookupSingleDashLonghandArg(arg); flag != nil {
+			return flag
+		}
+	}
+
 	switch {
 	case strings.HasPrefix(arg, "--"):
 		return fs.lookupPosixLonghandArg(arg)
@@ -99,6 +123,37 @@ func (fs FlagSet) lookupPosixLonghandArg(arg string) (flag *Flag) {
 	return
 }
 
+func (fs FlagSet) lookupSingleDashLonghandArg(arg string) *Flag {
+	if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+		return nil
+	}
+	if flag := fs.lookupPosixLonghandArg("-" + arg); flag != nil {
+		flag.Prefix = strings.TrimPrefix(flag.Prefix, "-")
+		return flag
+	}
+	return nil
+}
+
+// Normalize converts single dash longhand flags (`-name`) to their posix representation (`--name`) for parsing.
+func (fs FlagSet) Normalize(args []string) []string {
+	if !fs.SingleDash {
+		return args
+	}
+
+	normalized := make([]string, len(args))
+	for index, arg := range args {
+		if arg == "--" {
+			copy(normalized[index:], args[index:])
+			break
+		}
+		if fs.lookupSingleDashLonghandArg(arg) != nil {
+			arg = "-" + arg
+		}
+		normalized[index] = arg
+	}
+	return normalized
+}
+
 func (fs FlagSet) lookupPosixShorthandArg(arg string) *Flag {
 	if !strings.HasPrefix(arg, "-") || !fs.IsPosix() || len(arg) < 2 {
 		return nil
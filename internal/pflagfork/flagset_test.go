@@ -14,7 +14,7 @@ func TestLookupPosixShorthandArg(t *testing.T) {
 				args = []string{}
 			}
 
-			fs := &FlagSet{pflag.NewFlagSet("test", pflag.PanicOnError)}
+			fs := &FlagSet{FlagSet: pflag.NewFlagSet("test", pflag.PanicOnError)}
 
 			fs.BoolP("bool", "b", false, "")
 			fs.CountP("count", "c", "")
@@ -46,3 +46,42 @@ func TestLookupPosixShorthandArg(t *testing.T) {
 	_test("-ccbs=val1", "string", "-ccbs=", "val1")
 	_test("-ccbsval1", "string", "-ccbs", "val1")
 }
+
+func TestLookupSingleDashArg(t *testing.T) {
+	fs := &FlagSet{FlagSet: pflag.NewFlagSet("test", pflag.PanicOnError), SingleDash: true}
+	fs.String("name", "", "")
+	fs.StringP("define", "D", "", "")
+
+	if f := fs.LookupArg("-name=value"); f == nil || f.Name != "name" || f.Prefix != "-name=" || !reflect.DeepEqual(f.Args, []string{"value"}) {
+		t.Errorf("should match single dash longhand: %#v", f)
+	}
+
+	if f := fs.LookupArg("-Dkey=value"); f == nil || f.Name != "define" || f.Prefix != "-D" || !reflect.DeepEqual(f.Args, []string{"key=value"}) {
+		t.Errorf("should match attached shorthand: %#v", f)
+	}
+
+	if args := fs.Normalize([]string{"-name", "value", "-Dkey=value", "--", "-name"}); !reflect.DeepEqual(args, []string{"--name", "value", "-Dkey=value", "--", "-name"}) {
+		t.Errorf("unexpected normalized args: %#v", args)
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.String("empty", "", "")
+	fs.String("string", "json", "")
+	fs.Bool("bool", false, "")
+	fs.StringSlice("emptyslice", nil, "")
+	fs.StringSlice("slice", []string{"a", "b"}, "")
+
+	for name, expected := range map[string][]string{
+		"empty":      {},
+		"string":     {"json"},
+		"bool":       {"false"},
+		"emptyslice": {},
+		"slice":      {"a", "b"},
+	} {
+		if actual := (Flag{Flag: fs.Lookup(name)}).DefaultValues(); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%v: expected %#v [was: %#v]", name, expected, actual)
+		}
+	}
+}
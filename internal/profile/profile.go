@@ -0,0 +1,100 @@
+// Package profile records the duration of callbacks, executed commands and serialization (`CARAPACE_PROFILE`).
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carapace-sh/carapace/internal/env"
+)
+
+const (
+	KindCallback  = "callback"
+	KindExec      = "exec"
+	KindSerialize = "serialize"
+)
+
+// Entry is a single measurement.
+type Entry struct {
+	Kind     string
+	Name     string
+	Duration time.Duration
+}
+
+func (e Entry) String() string {
+	return fmt.Sprintf("%v\t%v\t%v", e.Duration.Round(time.Microsecond), e.Kind, e.Name)
+}
+
+var (
+	entries = make([]Entry, 0)
+	mutex   sync.Mutex
+)
+
+// Start starts measuring and returns a function recording the entry once called.
+// It does nothing unless profiling is enabled.
+//
+//	defer profile.Start(profile.KindExec, "git status")()
+func Start(kind, name string) func() {
+	if !env.Profile() {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		entries = append(entries, Entry{Kind: kind, Name: name, Duration: time.Since(start)})
+	}
+}
+
+// Flush returns the recorded entries sorted by duration (longest first) and resets them.
+func Flush() []Entry {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	flushed := entries
+	entries = make([]Entry, 0)
+	sort.SliceStable(flushed, func(i, j int) bool { return flushed[i].Duration > flushed[j].Duration })
+	return flushed
+}
+
+// Caller returns the location (`dir/file.go:line`) of the first caller outside of given packages.
+// It returns an empty string unless profiling is enabled or when called during command execution
+// (e.g. actions created internally while completing, which are covered by the enclosing callback).
+func Caller(skip ...string) string {
+	if !env.Profile() {
+		return ""
+	}
+
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	for {
+		frame, more := frames.Next()
+		if !isSkipped(frame, skip) {
+			if strings.HasPrefix(frame.Function, "github.com/spf13/cobra.") {
+				return ""
+			}
+			return fmt.Sprintf("%v:%v", filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isSkipped(frame runtime.Frame, packages []string) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	for _, pkg := range packages {
+		if strings.HasPrefix(frame.Function, pkg+".") {
+			return true
+		}
+	}
+	return false
+}
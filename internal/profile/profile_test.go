@@ -0,0 +1,39 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStart(t *testing.T) {
+	Start(KindExec, "disabled")()
+	if entries := Flush(); len(entries) != 0 {
+		t.Errorf("expected no entries when disabled: %#v", entries)
+	}
+
+	t.Setenv("CARAPACE_PROFILE", "1")
+	stopSlow := Start(KindCallback, "slow")
+	Start(KindExec, "fast")()
+	time.Sleep(10 * time.Millisecond)
+	stopSlow()
+
+	entries := Flush()
+	if len(entries) != 2 || entries[0].Name != "slow" || entries[1].Name != "fast" {
+		t.Errorf("expected entries sorted by duration: %#v", entries)
+	}
+	if entries := Flush(); len(entries) != 0 {
+		t.Errorf("expected entries to be reset: %#v", entries)
+	}
+}
+
+func TestCaller(t *testing.T) {
+	if location := Caller(); location != "" {
+		t.Errorf("expected no location when disabled: %v", location)
+	}
+
+	t.Setenv("CARAPACE_PROFILE", "1")
+	if location := Caller(); !strings.HasPrefix(location, "profile/profile_test.go:") {
+		t.Errorf("unexpected location: %v", location)
+	}
+}
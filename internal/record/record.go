@@ -0,0 +1,49 @@
+// Package record provides transcripts of completion invocations for bug reports.
+package record
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Entry is a single completion invocation.
+type Entry struct {
+	Time    string   `json:"time"`
+	ID      string   `json:"id"`             // invocation id (same as in the log)
+	Shell   string   `json:"shell"`          // target shell
+	Line    string   `json:"line"`           // command line as passed by the shell
+	Command string   `json:"command"`        // resolved (sub)command
+	Tags    []string `json:"tags,omitempty"` // tags of the invoked action
+	Values  int      `json:"values"`         // amount of values before filtering
+	Hash    string   `json:"hash"`           // sha256 of the serialized output
+}
+
+// Hash returns the hex encoded sha256 checksum of given output.
+func Hash(output string) string {
+	sum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(sum[:])
+}
+
+// Append adds given entry as json line to the file at given path.
+func Append(path string, e Entry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	m, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(m, '\n'))
+	return err
+}
@@ -0,0 +1,42 @@
+package record
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	if actual := Hash(""); actual != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Error(actual)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "record.jsonl")
+	for _, line := range []string{"example ", "example action "} {
+		if err := Append(path, Entry{Line: line, Hash: Hash(line)}); err != nil {
+			t.Fatal(err.Error())
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 entries [was: %#v]", lines)
+	}
+
+	var e Entry
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatal(err.Error())
+	}
+	if e.Line != "example action " || e.Hash != Hash("example action ") {
+		t.Errorf("unexpected entry: %#v", e)
+	}
+}
@@ -2,10 +2,11 @@ package bash
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	pkgquote "github.com/carapace-sh/carapace/pkg/quote"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 )
 
 var sanitizer = strings.NewReplacer(
@@ -14,47 +15,10 @@ var sanitizer = strings.NewReplacer(
 	"\t", ``,
 )
 
-var valueReplacer = strings.NewReplacer(
-	`\`, `\\`,
-	`"`, `\"`,
-	`$`, `\$`,
-	"`", "\\`",
-)
-
 var displayReplacer = strings.NewReplacer(
 	`${`, `\\\${`,
 )
 
-func commonPrefix(a, b string) string {
-	i := 0
-	for i < len(a) && i < len(b) && a[i] == b[i] {
-		i++
-	}
-	return a[0:i]
-}
-
-func commonDisplayPrefix(values ...common.RawValue) (prefix string) {
-	for index, val := range values {
-		if index == 0 {
-			prefix = val.Display
-		} else {
-			prefix = commonPrefix(prefix, val.Display)
-		}
-	}
-	return
-}
-
-func commonValuePrefix(values ...common.RawValue) (prefix string) {
-	for index, val := range values {
-		if index == 0 {
-			prefix = val.Value
-		} else {
-			prefix = commonPrefix(prefix, val.Value)
-		}
-	}
-	return
-}
-
 // ActionRawValues formats values for bash.
 func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
 	for index, value := range values {
@@ -62,11 +26,11 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 	}
 
 	lastSegment := strings.TrimPrefix(currentWord, wordbreakPrefix) // last segment of currentWord split by COMP_WORDBREAKS
-	if len(values) > 1 && commonDisplayPrefix(values...) != "" {
+	if len(values) > 1 && values.CommonDisplayPrefix() != "" {
 		// When all display values have the same prefix bash will insert is as partial completion (which skips prefixes/formatting).
-		if valuePrefix := commonValuePrefix(values...); lastSegment != valuePrefix {
-			// replace values with common value prefix
-			values = common.RawValuesFrom(commonValuePrefix(values...))
+		if valuePrefix := values.CommonValuePrefix(); lastSegment != valuePrefix && quote == "" {
+			// replace values with common value prefix (not within an open quote as readline would close it for a single value)
+			values = common.RawValuesFrom(values.CommonValuePrefix())
 		} else {
 			// prevent insertion of partial display values by prefixing one with space
 			values[0].Display = " " + values[0].Display
@@ -81,19 +45,14 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 			nospace = nospace || meta.Nospace.Matches(val.Value)
 
 			vals[index] = sanitizer.Replace(val.Value)
-			if requiresQuoting(vals[index]) {
-				vals[index] = valueReplacer.Replace(vals[index])
-				switch {
-				case strings.HasPrefix(vals[index], "~"): // assume homedir expansion
-					if splitted := strings.SplitAfterN(vals[index], "/", 2); len(splitted) == 2 {
-						vals[index] = fmt.Sprintf(`%v"%v"`, splitted[0], splitted[1])
-					} else {
-						// TODO homedir expansion won't work this way, but shouldn't reach this point anyway.
-						vals[index] = fmt.Sprintf(`~"%v"`, strings.TrimPrefix(vals[index], "~"))
-					}
-				default:
-					vals[index] = fmt.Sprintf(`"%v"`, vals[index])
-				}
+			prefix, rest := splitExpansion(vals[index], lastSegment)
+			switch quote {
+			case `"`: // readline replaces the word after the open quote and closes it on its own
+				vals[index] = prefix + pkgquote.BashWithin(rest, quote)
+			case `'`:
+				vals[index] = pkgquote.BashWithin(vals[index], quote)
+			default:
+				vals[index] = prefix + pkgquote.Bash(rest)
 			}
 		} else {
 			nospace = true
@@ -109,10 +68,12 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 	return fmt.Sprintf("%v\001%v", nospace, strings.Join(vals, "\n"))
 }
 
-func requiresQuoting(s string) bool {
-	chars := " \t\r\n`" + `[]{}()<>;|$&:*#`
-	chars += os.Getenv("COMP_WORDBREAKS")
-	chars += `\`
-	return strings.ContainsAny(s, chars)
-
+// splitExpansion splits the leading segment expanded by the shell from given value so it is kept unquoted.
+// Homedirs (`~`, `~user`) are always kept, variables (`$VAR`, `${VAR}`) only if typed by the user.
+func splitExpansion(value, currentWord string) (prefix, rest string) {
+	prefix = shlex.ExpansionPrefix(value)
+	if !strings.HasPrefix(prefix, "~") && !strings.HasPrefix(currentWord, prefix) {
+		prefix = ""
+	}
+	return prefix, strings.TrimPrefix(value, prefix)
 }
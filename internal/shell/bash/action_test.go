@@ -0,0 +1,68 @@
+package bash
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/carapace-sh/carapace/internal/common"
+)
+
+func TestActionRawValuesWordbreaks(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		values   []string
+		expected string
+	}{
+		{"example userA", []string{"userA:"}, "false\001userA:"},
+		{"example userA:gr", []string{"userA:groupA"}, "false\001groupA"},
+		{"example --flag=va", []string{"--flag=value"}, "false\001value"},
+		{"example us", []string{"user@host"}, "false\001user@host"},
+		{"example user@h", []string{"user@host"}, "false\001host"},
+		{"example ho", []string{"host:with space"}, "false\001\"host:with space\""},
+		{"example $HOME/do", []string{"$HOME/docs/"}, "false\001$HOME/docs/"},
+		{"example ${HOME}/do", []string{"${HOME}/docs dir/"}, "false\001${HOME}/\"docs dir/\""},
+		{"example ~/do", []string{"~/docs dir/"}, "false\001~/\"docs dir/\""},
+		{"example ~user/do", []string{"~user/docs/"}, "false\001~user/docs/"},
+		{"example $", []string{"$literal"}, "false\001\"\\$literal\""},
+	} {
+		t.Setenv("COMP_LINE", tc.line)
+		t.Setenv("COMP_WORDBREAKS", " \t\n\"'@><=;|&(:")
+		t.Setenv("COMP_POINT", strconv.Itoa(len(tc.line)))
+
+		args, err := Patch([]string{"bash", "example"})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual := ActionRawValues(args[len(args)-1], common.Meta{}, common.RawValuesFrom(tc.values...)); actual != tc.expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", tc.line, tc.expected, actual)
+		}
+	}
+}
+
+func TestActionRawValuesOpenQuote(t *testing.T) {
+	for _, tc := range []struct {
+		line     string
+		values   []string
+		expected string
+	}{
+		{`example --message "fix the `, []string{"fix the bug", "fix the build"}, "true\001fix the bug\nfix the build"},
+		{`example --message "fix the bu`, []string{"fix the bug"}, "false\001fix the bug"},
+		{`example --message "say `, []string{`say "hi"`}, "false\001say \\\"hi\\\""},
+		{`example --message 'fix the b`, []string{"fix the bug", "fix the build"}, "true\001fix the bug\nfix the build"},
+		{`example --message 'it`, []string{"it's fixed"}, "false\001it'\\''s fixed"},
+		{`example --message="fix the b`, []string{"--message=fix the bug", "--message=fix the build"}, "true\001fix the bug\nfix the build"},
+		{`example --message fix\ the\ b`, []string{"fix the bug", "fix the build"}, "true\001\"fix the bu\""},
+	} {
+		t.Setenv("COMP_LINE", tc.line)
+		t.Setenv("COMP_WORDBREAKS", " \t\n\"'@><=;|&(:")
+		t.Setenv("COMP_POINT", strconv.Itoa(len(tc.line)))
+
+		args, err := Patch([]string{"bash", "example"})
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual := ActionRawValues(args[len(args)-1], common.Meta{}, common.RawValuesFrom(tc.values...)); actual != tc.expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", tc.line, tc.expected, actual)
+		}
+	}
+}
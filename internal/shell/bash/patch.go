@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	shlex "github.com/carapace-sh/carapace-shlex"
+	pkgshlex "github.com/carapace-sh/carapace/pkg/shlex"
 )
 
 // RedirectError current position is a redirect like `echo test >[TAB]`.
@@ -18,6 +19,13 @@ func (r RedirectError) Error() string {
 // introduces state and hides what is happening but works for now
 var wordbreakPrefix string = ""
 var compType = ""
+var quote = ""
+
+// WordbreakPrefix returns the prefix of the current word bash won't replace (set by Patch).
+func WordbreakPrefix() string { return wordbreakPrefix }
+
+// Quote returns the quote left open in the current word (set by Patch).
+func Quote() string { return quote }
 
 const (
 	COMP_TYPE_NORMAL               = "9"  // TAB, for normal completion
@@ -53,7 +61,7 @@ func CompLine() (string, bool) {
 //	`example action >/tmp/stdout.txt --values 2>/tmp/stderr.txt fi[TAB]`
 //	["example", "action", ">", "/tmp/stdout.txt", "--values", "2", ">", "/tmp/stderr.txt", "fi"]
 //	["example", "action", "--values", "fi"]
-func Patch(args []string) ([]string, error) { // TODO document and fix wordbreak splitting (e.g. `:`)
+func Patch(args []string) ([]string, error) {
 	compline, ok := CompLine()
 	if !ok {
 		return args, nil
@@ -77,6 +85,7 @@ func Patch(args []string) ([]string, error) { // TODO document and fix wordbreak
 
 	// TODO find a better solution to pass the wordbreakprefix to bash/action.go
 	wordbreakPrefix = tokens.CurrentPipeline().WordbreakPrefix()
+	quote = pkgshlex.OpenQuote(tokens.CurrentToken().State)
 	compType = os.Getenv("COMP_TYPE")
 	unsetBashCompEnv()
 
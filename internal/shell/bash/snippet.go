@@ -4,27 +4,38 @@ package bash
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the bash completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	result := fmt.Sprintf(`#!/bin/bash
+_%v_shell_env() {
+  local name
+  local -A exported
+  for name in $(compgen -e); do exported[${name}]=1; done
+  for name in $(compgen -v); do
+    [[ -n ${exported[${name}]} || ${!name} == *$'\n'* ]] || printf '%%s=%%s\n' "${name}" "${!name}"
+  done
+}
+
 _%v_completion() {
   export COMP_LINE
   export COMP_POINT
   export COMP_TYPE
   export COMP_WORDBREAKS
 
-  local nospace data compline="${COMP_LINE:0:${COMP_POINT}}"
+  local nospace data compline="${COMP_LINE:0:${COMP_POINT}}" shellenv
+  shellenv="$(_%v_shell_env)"
+  [ "${#shellenv}" -lt 65536 ] || shellenv='' # size of a single environment variable is limited
 
   if echo ${compline}"''" | xargs echo 2>/dev/null > /dev/null; then
-  	data=$(echo ${compline}"''" | xargs %v _carapace bash)
+  	data=$(echo ${compline}"''" | CARAPACE_SHELL_ENV="${shellenv}" xargs %v _carapace bash)
   elif echo ${compline} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
-  	data=$(echo ${compline} | sed "s/\$/'/" | xargs %v _carapace bash)
+  	data=$(echo ${compline} | sed "s/\$/'/" | CARAPACE_SHELL_ENV="${shellenv}" xargs %v _carapace bash)
   else
-  	data=$(echo ${compline} | sed 's/$/"/' | xargs %v _carapace bash)
+  	data=$(echo ${compline} | sed 's/$/"/' | CARAPACE_SHELL_ENV="${shellenv}" xargs %v _carapace bash)
   fi
 
   IFS=$'\001' read -r -d '' nospace data <<<"${data}"
@@ -37,7 +48,7 @@ _%v_completion() {
 }
 
 complete -o noquote -F _%v_completion %v
-`, cmd.Name(), uid.Executable(), uid.Executable(), uid.Executable(), cmd.Name(), cmd.Name())
+`, cmd.Name(), cmd.Name(), cmd.Name(), shlex.Quote(executable), shlex.Quote(executable), shlex.Quote(executable), cmd.Name(), cmd.Name())
 
 	return result
 }
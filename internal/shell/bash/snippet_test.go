@@ -0,0 +1,39 @@
+package bash
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSnippetShellEnv(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	snippet := Snippet(&cobra.Command{Use: "test"}, "test")
+	script := snippet + `
+shellvar=value
+export exported=value
+multiline=$'first\nsecond'
+_test_shell_env`
+	output, err := exec.Command("bash", "-c", script).Output()
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "exported="):
+			t.Error("exported variables should be skipped")
+		case strings.HasPrefix(line, "multiline="), line == "second":
+			t.Error("multiline values should be skipped")
+		}
+	}
+	if !strings.Contains(string(output), "\nshellvar=value\n") {
+		t.Errorf("missing shell variable: %v", string(output))
+	}
+}
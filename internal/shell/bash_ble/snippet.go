@@ -6,14 +6,14 @@ import (
 	"regexp"
 
 	"github.com/carapace-sh/carapace/internal/shell/bash"
-	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the bash-ble completion script.
-func Snippet(cmd *cobra.Command) string {
-	bashSnippet := bash.Snippet(cmd)
+func Snippet(cmd *cobra.Command, executable string) string {
+	bashSnippet := bash.Snippet(cmd, executable)
 	bashSnippet = regexp.MustCompile("complete -F [^\n]+").ReplaceAllString(bashSnippet, "")
 
 	result := fmt.Sprintf(`
@@ -40,7 +40,7 @@ _%v_completion_ble() {
 }
 
 complete -F _%v_completion_ble %v
-`, cmd.Name(), uid.Executable(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name())
+`, cmd.Name(), shlex.Quote(executable), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name())
 
 	return bashSnippet + result
 }
@@ -26,17 +26,27 @@ func sanitize(values []common.RawValue) []common.RawValue {
 
 type completion struct {
 	Usage            string
-	Messages         common.Messages
+	Messages         []message
 	DescriptionStyle string
 	Candidates       []complexCandidate
 }
 
+type message struct {
+	Message string
+	Level   string
+	Style   string
+}
+
+// segment is a styled text segment which is concatenated to the display of a candidate.
+type segment struct {
+	Text  string
+	Style string
+}
+
 type complexCandidate struct {
-	Value       string
-	Display     string
-	Description string
-	CodeSuffix  string
-	Style       string
+	Value      string
+	Display    []segment
+	CodeSuffix string
 }
 
 // ActionRawValues formats values for elvish.
@@ -61,16 +71,42 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 		if val.Style == "" || ui.ParseStyling(val.Style) == nil {
 			val.Style = valueStyle
 		}
-		vals[index] = complexCandidate{Value: val.Value, Display: val.Display, Description: val.Description, CodeSuffix: suffix, Style: val.Style}
+
+		display := make([]segment, 0, 5)
+		before, highlight, after := val.HighlightSplit()
+		for _, s := range []segment{
+			{Text: before, Style: val.Style},
+			{Text: highlight, Style: val.Style + " " + style.Carapace.Match},
+			{Text: after, Style: val.Style},
+		} {
+			if s.Text != "" {
+				display = append(display, s)
+			}
+		}
+		if val.Description != "" {
+			display = append(display,
+				segment{Text: " ", Style: descriptionStyle + " bg-default"},
+				segment{Text: "(" + val.Description + ")", Style: descriptionStyle},
+			)
+		}
+		vals[index] = complexCandidate{Value: val.Value, Display: display, CodeSuffix: suffix}
 	}
 
 	if len(values) > 0 {
 		meta.Usage = "" // TODO edit:notify is persistent, so avoid spamming the user for now
 	}
 
+	messages := make([]message, 0)
+	for _, m := range meta.Messages.GetLevels() {
+		if m.Link != "" {
+			m.Message += " <" + m.Link + ">" // TODO edit:notify doesn't pass hyperlinks (OSC 8) through
+		}
+		messages = append(messages, message{Message: m.Message, Level: m.Level, Style: m.Style()})
+	}
+
 	m, _ := json.Marshal(completion{
 		Usage:            meta.Usage,
-		Messages:         meta.Messages,
+		Messages:         messages,
 		DescriptionStyle: descriptionStyle,
 		Candidates:       vals,
 	})
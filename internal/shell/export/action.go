@@ -10,6 +10,7 @@ import (
 func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
 	m, _ := json.Marshal(export.Export{
 		Meta:   meta,
+		Prefix: values.CommonValuePrefix(),
 		Values: values,
 	})
 	return string(m)
@@ -66,7 +66,7 @@ func convert(cmd *cobra.Command) command {
 	})
 	c.PersistentFlags = pflags
 
-	subcommands := make([]command, 0)
+	subcommands := make([]command, 0, len(cmd.Commands()))
 	for _, s := range cmd.Commands() {
 		if !s.Hidden {
 			subcommands = append(subcommands, convert(s))
@@ -77,10 +77,72 @@ func convert(cmd *cobra.Command) command {
 }
 
 // Snippet exports the command structure as json.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	out, err := json.Marshal(convert(cmd))
 	if err == nil {
 		return string(out)
 	}
 	return err.Error()
 }
+
+// Parse recreates the command structure exported by Snippet.
+func Parse(s []byte) (*cobra.Command, error) {
+	var c command
+	if err := json.Unmarshal(s, &c); err != nil {
+		return nil, err
+	}
+	return c.cobra(), nil
+}
+
+func (c command) cobra() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     c.Name,
+		Short:   c.Short,
+		Long:    c.Long,
+		Aliases: c.Aliases,
+		Run:     func(cmd *cobra.Command, args []string) {},
+	}
+
+	persistent := make(map[string]bool)
+	for _, f := range c.PersistentFlags {
+		f.add(cmd.PersistentFlags())
+		persistent[f.name()] = true
+	}
+	for _, f := range c.LocalFlags {
+		if !persistent[f.name()] { // local flags include the persistent ones of the command itself
+			f.add(cmd.Flags())
+		}
+	}
+
+	for _, s := range c.Commands {
+		cmd.AddCommand(s.cobra())
+	}
+	return cmd
+}
+
+func (f flag) name() string {
+	if f.Longhand != "" {
+		return f.Longhand
+	}
+	return f.Shorthand
+}
+
+func (f flag) add(fs *pflag.FlagSet) {
+	fs.VarP(&value{typ: f.Type}, f.name(), f.Shorthand, f.Usage)
+	switch {
+	case f.NoOptDefVal != "":
+		fs.Lookup(f.name()).NoOptDefVal = f.NoOptDefVal
+	case f.Type == "bool", f.Type == "boolSlice":
+		fs.Lookup(f.name()).NoOptDefVal = "true"
+	}
+}
+
+// value preserves the type of a parsed flag.
+type value struct {
+	typ string
+	s   string
+}
+
+func (v *value) String() string     { return v.s }
+func (v *value) Set(s string) error { v.s = s; return nil }
+func (v *value) Type() string       { return v.typ }
@@ -2,10 +2,16 @@ package fish
 
 import (
 	"fmt"
-	"github.com/carapace-sh/carapace/internal/common"
 	"strings"
+
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/env"
 )
 
+// Quote returns the quote left open in the current word (passed by the snippet).
+// Values are not escaped as fish inserts them according to the quote on its own.
+func Quote() string { return env.FishQuote() }
+
 var sanitizer = strings.NewReplacer(
 	"\n", ``,
 	"\r", ``,
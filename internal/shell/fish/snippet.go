@@ -4,12 +4,11 @@ package fish
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the fish completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	return fmt.Sprintf(`function _%v_quote_suffix
   if not commandline -cp | xargs echo 2>/dev/null >/dev/null
     if commandline -cp | sed 's/$/"/'| xargs echo 2>/dev/null >/dev/null
@@ -23,10 +22,15 @@ func Snippet(cmd *cobra.Command) string {
 end
 
 function _%v_callback
-  commandline -cp | sed "s/\$/"(_%v_quote_suffix)"/" | sed "s/ \$/ ''/" | xargs %v _carapace fish
+  set -l quote (_%v_quote_suffix)
+  commandline -cp | sed "s/\$/$quote/" | sed "s/ \$/ ''/" | env CARAPACE_FISH_QUOTE="$quote" xargs %v _carapace fish
 end
 
 complete -c %v -f
-complete -c '%v' -f -a '(_%v_callback)' -r
-`, cmd.Name(), cmd.Name(), cmd.Name(), uid.Executable(), cmd.Name(), cmd.Name(), cmd.Name())
+if contains -- "$CARAPACE_FISH_NOSORT" 1 true
+  complete -c '%v' -f -k -a '(_%v_callback)' -r
+else
+  complete -c '%v' -f -a '(_%v_callback)' -r
+end
+`, cmd.Name(), cmd.Name(), cmd.Name(), executable, cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name())
 }
@@ -0,0 +1,45 @@
+package fzf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/shell/bash"
+	"github.com/carapace-sh/carapace/pkg/style"
+)
+
+var sanitizer = strings.NewReplacer(
+	"\n", ``,
+	"\r", ``,
+	"\t", ``,
+)
+
+// documentationEscaper keeps the documentation on a single line (decoded by `printf '%b'` in the preview).
+var documentationEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\n", `\n`,
+	"\r", ``,
+	"\t", `\t`,
+)
+
+// ActionRawValues formats values for fzf (`value\tnospace\tdisplay\tdocumentation`).
+func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
+	capability := style.DetectCapability()
+	vals := make([]string, len(values))
+	for index, val := range values {
+		nospace := 0
+		if meta.Nospace.Matches(val.Value) {
+			nospace = 1
+		}
+
+		display := fmt.Sprintf("\x1b[%vm%v\x1b[0m", capability.SGR(val.Style), sanitizer.Replace(val.Display))
+		if description := val.TrimmedDescription(); description != "" {
+			display += fmt.Sprintf(" \x1b[%vm(%v)\x1b[0m", capability.SGR(style.Carapace.Description), sanitizer.Replace(description))
+		}
+
+		value := strings.TrimPrefix(val.Value, bash.WordbreakPrefix()) // bash replaces the last segment split by COMP_WORDBREAKS
+		vals[index] = fmt.Sprintf("%v\t%v\t%v\t%v", sanitizer.Replace(value), nospace, display, documentationEscaper.Replace(val.Documentation))
+	}
+	return strings.Join(vals, "\n")
+}
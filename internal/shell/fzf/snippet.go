@@ -0,0 +1,83 @@
+// Package fzf provides bash and zsh completion using fzf for selection
+package fzf
+
+import (
+	"fmt"
+
+	"github.com/carapace-sh/carapace/pkg/shlex"
+	"github.com/spf13/cobra"
+)
+
+const options = `--ansi --delimiter="$(printf '\t')" --with-nth=3 --select-1 --exit-0 --height=40% --reverse`
+
+// preview shows the documentation (fourth field) if any value has one.
+const preview = `echo "%v" | cut -f4 | grep -q . && preview=(--preview="printf '%%b' {4}" --preview-window=wrap)`
+
+// BashSnippet creates the bash completion script using fzf for selection.
+func BashSnippet(cmd *cobra.Command, executable string) string {
+	return fmt.Sprintf(`#!/bin/bash
+_%v_completion() {
+  export COMP_LINE
+  export COMP_POINT
+  export COMP_TYPE
+  export COMP_WORDBREAKS
+
+  local data selected value nospace preview=() compline="${COMP_LINE:0:${COMP_POINT}}"
+
+  if echo ${compline}"''" | xargs echo 2>/dev/null > /dev/null; then
+  	data=$(echo ${compline}"''" | xargs %v _carapace fzf)
+  elif echo ${compline} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
+  	data=$(echo ${compline} | sed "s/\$/'/" | xargs %v _carapace fzf)
+  else
+  	data=$(echo ${compline} | sed 's/$/"/' | xargs %v _carapace fzf)
+  fi
+
+  [ -z "${data}" ] && return
+  %v
+  selected="$(echo "${data}" | fzf %v "${preview[@]}" --query="${COMP_WORDS[COMP_CWORD]}")"
+  printf '\e[5n' # redraw prompt
+  [ -z "${selected}" ] && return
+
+  IFS=$'\t' read -r value nospace _ <<<"${selected}"
+  compopt -o nospace
+  value="$(printf '%%q' "${value}")"
+  [ "${nospace}" = 1 ] || value="${value} "
+  COMPREPLY=("${value}")
+}
+
+complete -o noquote -F _%v_completion %v
+`, cmd.Name(), shlex.Quote(executable), shlex.Quote(executable), shlex.Quote(executable), fmt.Sprintf(preview, "${data}"), options, cmd.Name(), cmd.Name())
+}
+
+// ZshSnippet creates the zsh completion script using fzf for selection.
+func ZshSnippet(cmd *cobra.Command, executable string) string {
+	return fmt.Sprintf(`#compdef %v
+function _%v_completion {
+  local lines selected value nospace
+  local -a preview
+
+  # shellcheck disable=SC2086,SC2154,SC2155
+  if echo ${words}"''" | xargs echo 2>/dev/null > /dev/null; then
+    lines="$(echo ${words}"''" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace fzf)"
+  elif echo ${words} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
+    lines="$(echo ${words} | sed "s/\$/'/" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace fzf)"
+  else
+    lines="$(echo ${words} | sed 's/$/"/' | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace fzf)"
+  fi
+
+  [ -z "${lines}" ] && return 1
+  %v
+  selected="$(echo "${lines}" | fzf %v "${preview[@]}" --query="${PREFIX}")"
+  [ -z "${selected}" ] && return 1
+
+  IFS=$'\t' read -r value nospace _ <<<"${selected}"
+  if [ "${nospace}" = 1 ]; then
+    compadd -U -S '' -- "${value}"
+  else
+    compadd -U -- "${value}"
+  fi
+}
+compquote '' 2>/dev/null && _%v_completion
+compdef _%v_completion %v
+`, cmd.Name(), cmd.Name(), shlex.Quote(executable), shlex.Quote(executable), shlex.Quote(executable), fmt.Sprintf(preview, "${lines}"), options, cmd.Name(), cmd.Name(), cmd.Name())
+}
@@ -6,6 +6,6 @@ import (
 )
 
 // Snippet creates the ion completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	return ""
 }
@@ -0,0 +1,12 @@
+package ksh
+
+import (
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/shell/bash"
+)
+
+// ActionRawValues formats values for ksh.
+// The output format and quoting are the same as bash, but the current word is never split by wordbreaks.
+func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
+	return bash.ActionRawValues(currentWord, meta, values)
+}
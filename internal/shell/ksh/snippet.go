@@ -0,0 +1,64 @@
+// Package ksh provides ksh93 completion
+package ksh
+
+import (
+	"fmt"
+
+	"github.com/carapace-sh/carapace/pkg/shlex"
+	"github.com/spf13/cobra"
+)
+
+// Snippet creates the ksh completion script.
+//
+// Completion is registered with the bash compatible `complete` builtin if present (e.g. ksh93v-).
+// Otherwise ksh93 falls back to a `KEYBD` trap on TAB which inserts a unique value and lists ambiguous ones.
+// mksh is not supported as it has neither a completion hook nor a `complete` builtin.
+func Snippet(cmd *cobra.Command, executable string) string {
+	result := fmt.Sprintf(`#!/bin/ksh
+function _%v_completion {
+  typeset compline data value
+  compline="${COMP_LINE:0:${COMP_POINT}}"
+  data="$(print -r -- "${compline}" | sed -e "s/ \$/ ''/" -e 's/"/\"/g' | xargs %v _carapace ksh)"
+  _%v_nospace="${data%%%%$'\001'*}"
+  data="${data#*$'\001'}"
+
+  unset COMPREPLY
+  while IFS= read -r value; do
+    [[ -n "${value}" ]] && COMPREPLY[${#COMPREPLY[@]}]="${value}"
+  done <<<"${data}"
+  [[ "${_%v_nospace}" == true ]] && command -v compopt >/dev/null 2>&1 && compopt -o nospace
+  return 0
+}
+
+function _%v_keybd {
+  [[ "${.sh.edchar}" == $'\t' ]] || return 0
+  typeset line="${.sh.edtext:0:${.sh.edcol}}" current
+  [[ "${line%%%%[[:space:]]*}" == %v && "${line}" == *[[:space:]]* ]] || return 0
+
+  COMP_LINE="${line}"
+  COMP_POINT="${#line}"
+  _%v_completion
+  current="${line##*[[:space:]]}"
+  case ${#COMPREPLY[@]} in
+    0) .sh.edchar='' ;;
+    1) if [[ "${COMPREPLY[0]}" == "${current}"* ]]; then
+         .sh.edchar="${COMPREPLY[0]#"${current}"}"
+         [[ "${_%v_nospace}" == true ]] || .sh.edchar+=' '
+       else
+         .sh.edchar=''
+       fi ;;
+    *) print -u2
+       printf '%%s\n' "${COMPREPLY[@]}" >&2
+       .sh.edchar=$'\cL' ;; # redraw the current line
+  esac
+}
+
+if command -v complete >/dev/null 2>&1; then
+  complete -F _%v_completion %v
+elif [[ "${KSH_VERSION}" == *93* ]]; then
+  trap _%v_keybd KEYBD
+fi
+`, cmd.Name(), shlex.Quote(executable), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name())
+
+	return result
+}
@@ -0,0 +1,26 @@
+// Package menu provides output for menu programs like dmenu, rofi or fuzzel
+package menu
+
+import (
+	"strings"
+
+	"github.com/carapace-sh/carapace/internal/common"
+)
+
+var sanitizer = strings.NewReplacer(
+	"\n", ``,
+	"\r", ``,
+	"\t", ``,
+)
+
+// ActionRawValues formats values as `value\tdescription` lines.
+func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
+	vals := make([]string, len(values))
+	for index, val := range values {
+		vals[index] = sanitizer.Replace(val.Value)
+		if description := val.TrimmedDescription(); description != "" {
+			vals[index] += "\t" + sanitizer.Replace(description)
+		}
+	}
+	return strings.Join(vals, "\n")
+}
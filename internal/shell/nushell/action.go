@@ -2,10 +2,10 @@ package nushell
 
 import (
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	pkgquote "github.com/carapace-sh/carapace/pkg/quote"
 )
 
 type record struct {
@@ -20,11 +20,6 @@ var sanitizer = strings.NewReplacer(
 	"\r", ``,
 )
 
-var escaper = strings.NewReplacer(
-	`\`, `\\`,
-	`"`, `\"`,
-)
-
 func sanitize(values []common.RawValue) []common.RawValue {
 	for index, v := range values {
 		(&values[index]).Value = sanitizer.Replace(v.Value)
@@ -39,13 +34,19 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 	vals := make([]record, len(values))
 	for index, val := range sanitize(values) {
 		nospace := meta.Nospace.Matches(val.Value)
-		if strings.ContainsAny(val.Value, ` {}()[]<>$&"'|;#\`+"`") {
-			switch {
-			case strings.HasPrefix(val.Value, "~"):
-				val.Value = fmt.Sprintf(`~"%v"`, escaper.Replace(val.Value[1:]))
-			default:
-				val.Value = fmt.Sprintf(`"%v"`, escaper.Replace(val.Value))
+		switch {
+		case quote == "'" && !strings.Contains(val.Value, "'"): // nushell replaces the open quote as well
+			val.Value = "'" + val.Value
+			if !nospace {
+				val.Value += "'"
+			}
+		case quote != "":
+			val.Value = `"` + pkgquote.NushellWithin(val.Value)
+			if !nospace {
+				val.Value += `"`
 			}
+		default:
+			val.Value = pkgquote.Nushell(val.Value)
 		}
 
 		if !nospace {
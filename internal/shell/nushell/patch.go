@@ -4,14 +4,21 @@ import (
 	"strings"
 
 	shlex "github.com/carapace-sh/carapace-shlex"
+	pkgshlex "github.com/carapace-sh/carapace/pkg/shlex"
 )
 
+var quote = ""
+
+// Quote returns the quote left open in the current word (set by Patch).
+func Quote() string { return quote }
+
 // Patch uses the lexer to parse and patch given arguments which
 // are currently passed unprocessed to the completion function.
 //
 // see https://www.nushell.sh/book/working_with_strings.html
 func Patch(args []string) []string {
 	// TODO
+	quote = ""
 	for index, arg := range args {
 		if len(arg) == 0 {
 			continue
@@ -21,6 +28,9 @@ func Patch(args []string) []string {
 		case '"', "'"[0]:
 			if tokens, err := shlex.Split(arg); err == nil {
 				args[index] = tokens[0].Value
+				if index == len(args)-1 {
+					quote = pkgshlex.OpenQuote(tokens[0].State)
+				}
 			}
 		case '`':
 			args[index] = strings.Trim(arg, "`")
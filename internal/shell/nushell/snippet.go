@@ -4,13 +4,12 @@ package nushell
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the nushell completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	return fmt.Sprintf(`let %v_completer = {|spans| 
     %v _carapace nushell ...$spans | from json
-}`, cmd.Name(), uid.Executable())
+}`, cmd.Name(), executable)
 }
@@ -4,12 +4,12 @@ package oil
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the oil completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	result := fmt.Sprintf(`#!/bin/osh
 _%v_completion() {
   local compline="${COMP_LINE:0:${COMP_POINT}}"
@@ -23,7 +23,7 @@ _%v_completion() {
 }
 
 complete -F _%v_completion %v
-`, cmd.Name(), uid.Executable(), cmd.Name(), cmd.Name())
+`, cmd.Name(), shlex.Quote(executable), cmd.Name(), cmd.Name())
 
 	return result
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/carapace-sh/carapace/internal/common"
 	"github.com/carapace-sh/carapace/internal/env"
+	"github.com/carapace-sh/carapace/pkg/quote"
 	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/carapace-sh/carapace/third_party/github.com/elves/elvish/pkg/ui"
 )
@@ -19,7 +20,9 @@ var sanitizer = strings.NewReplacer( // TODO
 type completionResult struct {
 	CompletionText string
 	ListItemText   string
+	ResultType     string // name of System.Management.Automation.CompletionResultType
 	ToolTip        string
+	NoSpace        bool // whether the scriptblock must not append a space
 }
 
 // CompletionResult doesn't like empty parameters, so just replace with space if needed.
@@ -43,6 +46,7 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 	}
 
 	tooltipEnabled := env.Tooltip()
+	capability := style.DetectCapability()
 
 	vals := make([]completionResult, 0, len(values))
 	for _, val := range values {
@@ -50,34 +54,35 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 			val.Value = sanitizer.Replace(val.Value)
 			nospace := meta.Nospace.Matches(val.Value)
 
-			if strings.ContainsAny(val.Value, ` {}()[]*$?\"|<>&(),;#`+"`") {
-				val.Value = fmt.Sprintf("'%v'", val.Value)
-			}
-
-			if !nospace {
-				val.Value = val.Value + " "
-			}
+			val.Value = quote.Powershell(val.Value)
 
 			if val.Style == "" || ui.ParseStyling(val.Style) == nil {
 				val.Style = valueStyle
 			}
 
 			tooltip := " "
-			if tooltipEnabled && val.Description != "" {
-				tooltip = fmt.Sprintf("`e[%vm`e[%vm%v`e[21;22;23;24;25;29;39;49m", sgr(descriptionStyle+" bg-default"), sgr(descriptionStyle), sanitizer.Replace(val.TrimmedDescription()))
+			switch {
+			case tooltipEnabled && val.Description != "":
+				tooltip = fmt.Sprintf("`e[%vm`e[%vm%v`e[21;22;23;24;25;29;39;49m", sgr(capability, descriptionStyle+" bg-default"), sgr(capability, descriptionStyle), sanitizer.Replace(val.TrimmedDescription()))
 				val.Description = ""
+			case val.Documentation != "":
+				tooltip = val.Documentation // shown in the PSReadLine list view
+			case val.Description != "":
+				tooltip = sanitizer.Replace(val.TrimmedDescription())
 			}
 
-			listItemText := fmt.Sprintf("`e[21;22;23;24;25;29m`e[%vm%v`e[21;22;23;24;25;29;39;49m", sgr(val.Style), sanitizer.Replace(val.Display))
+			listItemText := fmt.Sprintf("`e[21;22;23;24;25;29m`e[%vm%v`e[21;22;23;24;25;29;39;49m", sgr(capability, val.Style), sanitizer.Replace(val.Display))
 			if val.Description != "" {
-				listItemText = listItemText + fmt.Sprintf("`e[%vm `e[%vm(%v)`e[21;22;23;24;25;29;39;49m", sgr(descriptionStyle+" bg-default"), sgr(descriptionStyle), sanitizer.Replace(val.TrimmedDescription()))
+				listItemText = listItemText + fmt.Sprintf("`e[%vm `e[%vm(%v)`e[21;22;23;24;25;29;39;49m", sgr(capability, descriptionStyle+" bg-default"), sgr(capability, descriptionStyle), sanitizer.Replace(val.TrimmedDescription()))
 			}
 			listItemText = listItemText + "`e[0m"
 
 			vals = append(vals, completionResult{
 				CompletionText: val.Value,
 				ListItemText:   ensureNotEmpty(listItemText),
+				ResultType:     resultType(val),
 				ToolTip:        ensureNotEmpty(tooltip),
+				NoSpace:        nospace,
 			})
 		}
 	}
@@ -85,8 +90,24 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 	return string(m)
 }
 
-func sgr(s string) string {
-	if result := style.SGR(s); result != "" {
+// resultType determines the CompletionResultType based on the tag of given value.
+func resultType(val common.RawValue) string {
+	switch {
+	case val.Tag == "shorthand flags" || val.Tag == "longhand flags" || val.Tag == "flags":
+		return "ParameterName"
+	case val.Tag == "commands" || strings.HasSuffix(val.Tag, " commands"):
+		return "Command"
+	case val.Tag == "directories" || (val.Tag == "files" && strings.HasSuffix(val.Value, "/")):
+		return "ProviderContainer"
+	case val.Tag == "files":
+		return "ProviderItem"
+	default:
+		return "ParameterValue"
+	}
+}
+
+func sgr(c style.Capability, s string) string {
+	if result := c.SGR(s); result != "" {
 		return result
 	}
 	return "39;49"
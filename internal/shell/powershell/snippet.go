@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"runtime"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
 
@@ -40,11 +39,19 @@ Function _%v_completer {
       $elems += $t.replace('` + "`" + `,', ',') # quick fix
     }
 
+    $output = if (!$wordToComplete) {
+      %v _carapace powershell $($elems| ForEach-Object {$_}) ''
+    } else {
+      %v _carapace powershell $($elems| ForEach-Object {$_})
+    }
+
     $completions = @(
-      if (!$wordToComplete) {
-        %v _carapace powershell $($elems| ForEach-Object {$_}) '' | ConvertFrom-Json | ForEach-Object { [CompletionResult]::new($_.CompletionText, $_.ListItemText.replace('` + "`" + `e[', "` + "`" + `e["), [CompletionResultType]::ParameterValue, $_.ToolTip.replace('` + "`" + `e[', "` + "`" + `e[")) }
-      } else {
-        %v _carapace powershell $($elems| ForEach-Object {$_}) | ConvertFrom-Json | ForEach-Object { [CompletionResult]::new($_.CompletionText, $_.ListItemText.replace('` + "`" + `e[', "` + "`" + `e["), [CompletionResultType]::ParameterValue, $_.ToolTip.replace('` + "`" + `e[', "` + "`" + `e[")) }
+      $output | ConvertFrom-Json | ForEach-Object {
+        $completionText = $_.CompletionText
+        if (!$_.NoSpace) {
+          $completionText = $completionText + ' '
+        }
+        [CompletionResult]::new($completionText, $_.ListItemText.replace('` + "`" + `e[', "` + "`" + `e["), [CompletionResultType]$_.ResultType, $_.ToolTip.replace('` + "`" + `e[', "` + "`" + `e["))
       }
     )
 
@@ -58,15 +65,15 @@ Register-ArgumentCompleter -Native -ScriptBlock (Get-Item "Function:_%v_complete
 `
 
 // Snippet creates the powershell completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	prefix := " # "
 	if runtime.GOOS == "windows" {
 		prefix = ""
 	}
 	return fmt.Sprintf(snippet,
 		cmd.Name(),
-		uid.Executable(),
-		uid.Executable(),
+		executable,
+		executable,
 		cmd.Name(),
 		cmd.Name(),
 		prefix,
@@ -13,73 +13,148 @@ import (
 	"github.com/carapace-sh/carapace/internal/shell/elvish"
 	"github.com/carapace-sh/carapace/internal/shell/export"
 	"github.com/carapace-sh/carapace/internal/shell/fish"
+	"github.com/carapace-sh/carapace/internal/shell/fzf"
 	"github.com/carapace-sh/carapace/internal/shell/ion"
+	"github.com/carapace-sh/carapace/internal/shell/ksh"
+	"github.com/carapace-sh/carapace/internal/shell/menu"
 	"github.com/carapace-sh/carapace/internal/shell/nushell"
 	"github.com/carapace-sh/carapace/internal/shell/oil"
 	"github.com/carapace-sh/carapace/internal/shell/powershell"
 	"github.com/carapace-sh/carapace/internal/shell/tcsh"
 	"github.com/carapace-sh/carapace/internal/shell/xonsh"
+	"github.com/carapace-sh/carapace/internal/shell/ysh"
 	"github.com/carapace-sh/carapace/internal/shell/zsh"
 	"github.com/carapace-sh/carapace/pkg/ps"
 	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/spf13/cobra"
 )
 
-// Snippet creates completion script for given shell.
-func Snippet(cmd *cobra.Command, shell string) (string, error) {
+// Snippet creates completion script for given shell invoking given executable.
+func Snippet(cmd *cobra.Command, shell string, executable string) (string, error) {
 	if shell == "" {
 		shell = ps.DetermineShell()
 	}
-	shellSnippets := map[string]func(cmd *cobra.Command) string{
-		"bash":       bash.Snippet,
-		"bash-ble":   bash_ble.Snippet,
-		"export":     export.Snippet,
-		"fish":       fish.Snippet,
-		"elvish":     elvish.Snippet,
-		"ion":        ion.Snippet,
-		"nushell":    nushell.Snippet,
-		"oil":        oil.Snippet,
-		"powershell": powershell.Snippet,
-		"tcsh":       tcsh.Snippet,
-		"xonsh":      xonsh.Snippet,
-		"zsh":        zsh.Snippet,
+	if env.Fzf() {
+		switch shell {
+		case "bash":
+			return fzf.BashSnippet(cmd.Root(), executable), nil
+		case "zsh":
+			return fzf.ZshSnippet(cmd.Root(), executable), nil
+		}
 	}
-	if s, ok := shellSnippets[shell]; ok {
-		return s(cmd.Root()), nil
+	if s, ok := snippets[shell]; ok {
+		return s(cmd.Root(), executable), nil
 	}
+	return "", fmt.Errorf("expected one of '%v' [was: %v]", strings.Join(Supported(), "', '"), shell)
+}
 
-	expected := make([]string, 0)
-	for key := range shellSnippets {
-		expected = append(expected, key)
+// snippets create the completion script for the given shell.
+var snippets = map[string]func(cmd *cobra.Command, executable string) string{
+	"bash":       bash.Snippet,
+	"bash-ble":   bash_ble.Snippet,
+	"export":     export.Snippet,
+	"fish":       fish.Snippet,
+	"elvish":     elvish.Snippet,
+	"ion":        ion.Snippet,
+	"ksh":        ksh.Snippet,
+	"nushell":    nushell.Snippet,
+	"oil":        oil.Snippet,
+	"powershell": powershell.Snippet,
+	"tcsh":       tcsh.Snippet,
+	"xonsh":      xonsh.Snippet,
+	"ysh":        ysh.Snippet,
+	"zsh":        zsh.Snippet,
+}
+
+// Supported returns the names of shells a completion script can be created for.
+func Supported() []string {
+	shells := make([]string, 0, len(snippets))
+	for name := range snippets {
+		shells = append(shells, name)
 	}
-	sort.Strings(expected)
-	return "", fmt.Errorf("expected one of '%v' [was: %v]", strings.Join(expected, "', '"), shell)
+	sort.Strings(shells)
+	return shells
+}
+
+// highlightShells are the shells rendering styled segments of the display
+// (fish and nushell highlight the matched prefix natively).
+var highlightShells = map[string]bool{
+	"elvish": true,
+	"zsh":    true,
 }
 
-func Value(shell string, value string, meta common.Meta, values common.RawValues) string { // TODO use context instead?
-	shellFuncs := map[string]func(currentWord string, meta common.Meta, values common.RawValues) string{
-		"bash":       bash.ActionRawValues,
-		"bash-ble":   bash_ble.ActionRawValues,
-		"fish":       fish.ActionRawValues,
-		"elvish":     elvish.ActionRawValues,
-		"export":     export.ActionRawValues,
-		"ion":        ion.ActionRawValues,
-		"nushell":    nushell.ActionRawValues,
-		"oil":        oil.ActionRawValues,
-		"powershell": powershell.ActionRawValues,
-		"tcsh":       tcsh.ActionRawValues,
-		"xonsh":      xonsh.ActionRawValues,
-		"zsh":        zsh.ActionRawValues,
+// columnShells are the shells listing descriptions in a column of monospace text
+// (others like fish and powershell wrap or truncate them, so columns aren't padded).
+var columnShells = map[string]bool{
+	"bash":     true,
+	"bash-ble": true,
+	"elvish":   true,
+	"fzf":      true,
+	"menu":     true,
+	"nushell":  true,
+	"xonsh":    true,
+	"zsh":      true,
+}
+
+// hyperlinkShells are the shells known to pass terminal hyperlinks (OSC 8) in the display through
+// (others like zsh and fish escape control characters in the completion listing).
+var hyperlinkShells = map[string]bool{
+	"nushell": true,
+}
+
+// serializers format values for the given shell.
+var serializers = map[string]func(currentWord string, meta common.Meta, values common.RawValues) string{
+	"bash":       bash.ActionRawValues,
+	"bash-ble":   bash_ble.ActionRawValues,
+	"fish":       fish.ActionRawValues,
+	"fzf":        fzf.ActionRawValues,
+	"elvish":     elvish.ActionRawValues,
+	"export":     export.ActionRawValues,
+	"ion":        ion.ActionRawValues,
+	"ksh":        ksh.ActionRawValues,
+	"menu":       menu.ActionRawValues,
+	"nushell":    nushell.ActionRawValues,
+	"oil":        oil.ActionRawValues,
+	"powershell": powershell.ActionRawValues,
+	"tcsh":       tcsh.ActionRawValues,
+	"xonsh":      xonsh.ActionRawValues,
+	"ysh":        ysh.ActionRawValues,
+	"zsh":        zsh.ActionRawValues,
+}
+
+// Serializers returns the names of shells values can be formatted for (see Supported for shells with a snippet).
+func Serializers() []string {
+	shells := make([]string, 0, len(serializers))
+	for name := range serializers {
+		shells = append(shells, name)
 	}
-	if f, ok := shellFuncs[shell]; ok {
+	sort.Strings(shells)
+	return shells
+}
+
+func Value(shell string, value string, meta common.Meta, values common.RawValues, translate func(string) string) string { // TODO use context instead?
+	if f, ok := serializers[shell]; ok {
 		if env.ColorDisabled() {
 			style.Carapace.Value = style.Default
 			style.Carapace.Description = style.Default
 			style.Carapace.Error = style.Underlined
+			style.Carapace.Warning = style.Underlined
+			style.Carapace.Info = style.Default
 			style.Carapace.Usage = style.Italic
 			values = values.Decolor()
 		}
 		filtered := values.FilterPrefix(value)
+		if meta.Fold {
+			filtered = values.FilterPrefixFold(value)
+		}
+		if tags := env.DisabledTags(); len(tags) > 0 {
+			filtered = filtered.FilterTags(tags...)
+		}
+		if maxResults := env.MaxResults(); maxResults > 0 && len(filtered) > maxResults {
+			sort.Sort(common.ByDisplay(filtered))
+			meta.AddMore(len(filtered)-maxResults, translate)
+			filtered = filtered[:maxResults]
+		}
 		switch shell {
 		case "elvish", "export", "zsh": // shells with support for showing messages
 		default:
@@ -88,7 +163,7 @@ func Value(shell string, value string, meta common.Meta, values common.RawValues
 
 		if shell != "export" {
 			switch {
-			case !meta.Messages.IsEmpty():
+			case meta.Messages.HasErrors(): // warnings and info messages don't prevent values from being inserted
 				meta.Nospace.Add('*')
 			case env.Nospace() != "":
 				meta.Nospace.Add([]rune(env.Nospace())...)
@@ -96,6 +171,37 @@ func Value(shell string, value string, meta common.Meta, values common.RawValues
 		}
 
 		sort.Sort(common.ByDisplay(filtered))
+		order := env.Order()
+		if order == "" {
+			order = meta.Order
+		}
+		if order == "" && shell == "fish" && env.FishNosort() {
+			order = "tags" // fish has no groups, so at least keep values of the same tag together
+		}
+		switch order {
+		case "directories":
+			sort.Stable(common.ByDirectory(filtered))
+		case "tags":
+			sort.Stable(common.ByTag(filtered))
+		}
+		if columnShells[shell] {
+			filtered = filtered.Columnize()
+		}
+		if style.Carapace.Match != "" && highlightShells[shell] {
+			filtered = filtered.Highlight(value)
+		}
+		if style.Carapace.Default != "" && shell != "export" && !env.Plain() { // export keeps the marker for the consumer to decide
+			filtered = filtered.StyleDefault()
+		}
+		if hyperlinkShells[shell] && env.Hyperlink() {
+			filtered = filtered.Hyperlink()
+		}
+		if env.Icons() {
+			filtered = filtered.Iconify()
+		}
+		if env.Accessible() {
+			filtered = filtered.Labelize()
+		}
 		if env.Experimental() {
 			if _, err := exec.LookPath("tabdance"); err == nil {
 				return f(value, meta, filtered)
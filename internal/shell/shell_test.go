@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// benchmarkCommand creates a command tree with given amount of subcommands (each with a couple of flags).
+func benchmarkCommand(subcommands int) *cobra.Command {
+	cmd := &cobra.Command{Use: "bench"}
+	cmd.PersistentFlags().Bool("persistent", false, "persistent flag")
+	for i := 0; i < subcommands; i++ {
+		subcmd := &cobra.Command{
+			Use:   fmt.Sprintf("sub%v", i),
+			Short: fmt.Sprintf("subcommand %v", i),
+			Run:   func(cmd *cobra.Command, args []string) {},
+		}
+		subcmd.Flags().StringP("string", "s", "", "string flag")
+		subcmd.Flags().Bool("bool", false, "bool flag")
+		cmd.AddCommand(subcmd)
+	}
+	return cmd
+}
+
+func TestSupported(t *testing.T) {
+	for _, name := range Supported() {
+		if _, ok := serializers[name]; !ok {
+			t.Errorf("missing serializer for %#v", name)
+		}
+		if _, err := Snippet(&cobra.Command{Use: "example"}, name, "example"); err != nil {
+			t.Error(err.Error())
+		}
+	}
+
+	if _, err := Snippet(&cobra.Command{Use: "example"}, "unknown", "example"); err == nil || !strings.Contains(err.Error(), "'bash', 'bash-ble'") {
+		t.Errorf("expected supported shells in error: %v", err)
+	}
+}
+
+func BenchmarkSnippet(b *testing.B) {
+	for _, subcommands := range []int{10, 500} {
+		cmd := benchmarkCommand(subcommands)
+		for _, shell := range []string{"bash", "elvish", "export", "fish", "nushell", "powershell", "xonsh", "zsh"} {
+			b.Run(fmt.Sprintf("%v/%v", shell, subcommands), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := Snippet(cmd, shell, "bench"); err != nil {
+						b.Fatal(err.Error())
+					}
+				}
+			})
+		}
+	}
+}
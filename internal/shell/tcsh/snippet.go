@@ -4,12 +4,18 @@ package tcsh
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the tcsh completion script.
-func Snippet(cmd *cobra.Command) string {
+//
+// Limitations (tcsh only supports plain word lists):
+//   - descriptions are appended to the value as `value_(description)` (strip them with `CARAPACE_TCSH_NODESC`)
+//   - the suffix can't be set per value, so no space is appended at all (the script uses an empty suffix)
+//   - open quotes are not handled
+//
+// The script consists of a single line as it is loaded with `eval` (which would treat a comment header as part of the command).
+func Snippet(cmd *cobra.Command, executable string) string {
 	// TODO initial version - needs to handle open quotes
-	return fmt.Sprintf("complete \"%v\" 'p@*@`echo \"$COMMAND_LINE'\"''\"'\" | xargs %v _carapace tcsh `@@' ;", cmd.Name(), uid.Executable())
+	return fmt.Sprintf("complete \"%v\" 'p@*@`echo \"$COMMAND_LINE'\"''\"'\" | xargs %v _carapace tcsh `@@' ;", cmd.Name(), executable)
 }
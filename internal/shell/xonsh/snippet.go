@@ -5,12 +5,11 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the xonsh completion script.
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	functionName := strings.Replace(cmd.Name(), "-", "__", -1)
 	return fmt.Sprintf(`from xonsh.completers.completer import add_one_completer
 from xonsh.completers.tools import contextual_command_completer
@@ -39,5 +38,5 @@ def _%v_completer(context):
         return result
 
 add_one_completer('%v', _%v_completer, 'start')
-`, functionName, cmd.Name(), cmd.Name(), uid.Executable(), cmd.Name(), functionName)
+`, functionName, cmd.Name(), cmd.Name(), executable, cmd.Name(), functionName)
 }
@@ -0,0 +1,40 @@
+package ysh
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carapace-sh/carapace/internal/common"
+)
+
+var sanitizer = strings.NewReplacer(
+	"\n", ``,
+	"\t", ``,
+)
+
+type candidate struct {
+	Value       string `json:"value"`
+	Display     string `json:"display"`
+	Description string `json:"description,omitempty"`
+	Nospace     bool   `json:"nospace,omitempty"`
+}
+
+// ActionRawValues formats values for ysh.
+func ActionRawValues(currentWord string, meta common.Meta, values common.RawValues) string {
+	candidates := make([]candidate, len(values))
+	for index, val := range values {
+		c := candidate{
+			Value:       sanitizer.Replace(val.Value),
+			Display:     sanitizer.Replace(val.Display),
+			Description: sanitizer.Replace(val.TrimmedDescription()),
+			Nospace:     meta.Nospace.Matches(val.Value),
+		}
+		if c.Description != "" {
+			c.Display = fmt.Sprintf("%v (%v)", c.Display, c.Description)
+		}
+		candidates[index] = c
+	}
+	m, _ := json.Marshal(candidates)
+	return string(m)
+}
@@ -0,0 +1,36 @@
+// Package ysh provides YSH (Oils) completion
+package ysh
+
+import (
+	"fmt"
+
+	"github.com/carapace-sh/carapace/pkg/shlex"
+	"github.com/spf13/cobra"
+)
+
+// Snippet creates the ysh completion script.
+func Snippet(cmd *cobra.Command, executable string) string {
+	result := fmt.Sprintf(`#!/usr/bin/env ysh
+proc _%v_completion {
+  var compline = COMP_LINE[:int(COMP_POINT)]
+  var candidates = []
+  write -- $compline | sed -e "s/ \$/ ''/" -e 's/"/\"/g' | xargs %v _carapace ysh | json read (&candidates)
+
+  setglobal COMPREPLY = []
+  for candidate in (candidates) {
+    if (len(candidates) === 1) {
+      call COMPREPLY->append(candidate.value)
+    } else {
+      call COMPREPLY->append(candidate.display)
+    }
+  }
+  if (len(candidates) === 1 and candidates[0].nospace) {
+    compopt -o nospace
+  }
+}
+
+complete -F _%v_completion %v
+`, cmd.Name(), shlex.Quote(executable), cmd.Name(), cmd.Name())
+
+	return result
+}
@@ -5,6 +5,10 @@ import (
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/env"
+	pkgquote "github.com/carapace-sh/carapace/pkg/quote"
+	"github.com/carapace-sh/carapace/pkg/shlex"
+	"github.com/carapace-sh/carapace/pkg/style"
 )
 
 var sanitizer = strings.NewReplacer(
@@ -13,36 +17,20 @@ var sanitizer = strings.NewReplacer(
 	"\t", ``,
 )
 
-// TODO verify these are correct/complete (copied from bash)
-var quoter = strings.NewReplacer(
-	`\`, `\\`,
-	`&`, `\&`,
-	`<`, `\<`,
-	`>`, `\>`,
-	"`", "\\`",
-	`'`, `\'`,
-	`"`, `\"`,
-	`{`, `\{`,
-	`}`, `\}`,
-	`$`, `\$`,
-	`#`, `\#`,
-	`|`, `\|`,
-	`?`, `\?`,
-	`(`, `\(`,
-	`)`, `\)`,
-	`;`, `\;`,
-	` `, `\ `,
-	`[`, `\[`,
-	`]`, `\]`,
-	`*`, `\*`,
-	`~`, `\~`,
-)
+// Quote returns the quote left open in the current word (passed by the snippet).
+func Quote() string { return env.ZshQuote() }
 
-func quoteValue(s string) string {
+func quoteValue(s, currentWord string) string {
+	if openQuote := Quote(); openQuote != "" { // zsh inserts the value after the open quote and closes it on its own
+		return pkgquote.ZshWithin(s, openQuote)
+	}
 	if strings.HasPrefix(s, "~/") || NamedDirectories.Matches(s) {
-		return "~" + quoter.Replace(strings.TrimPrefix(s, "~")) // assume file path expansion
+		return "~" + pkgquote.Zsh(strings.TrimPrefix(s, "~")) // assume file path expansion
+	}
+	if prefix := shlex.ExpansionPrefix(s); strings.HasPrefix(prefix, "$") && strings.HasPrefix(currentWord, prefix) {
+		return prefix + pkgquote.Zsh(strings.TrimPrefix(s, prefix)) // keep variable typed by the user
 	}
-	return quoter.Replace(s)
+	return pkgquote.Zsh(s)
 }
 
 // ActionRawValues formats values for zsh
@@ -56,14 +44,19 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 
 	tagGroup := make([]string, 0)
 	values.EachTag(func(tag string, values common.RawValues) {
+		id := strings.ReplaceAll(sanitizer.Replace(tag), " ", "-") // zstyle context friendly tag
+		if id == "" {
+			id = "values"
+		}
 		vals := make([]string, len(values))
 		displays := make([]string, len(values))
 		for index, val := range values {
 			val.Value = sanitizer.Replace(val.Value)
-			val.Value = quoteValue(val.Value)
+			val.Value = quoteValue(val.Value, currentWord)
 			val.Value = strings.ReplaceAll(val.Value, `\`, `\\`) // TODO find out why `_describe` needs another backslash
 			val.Value = strings.ReplaceAll(val.Value, `:`, `\:`) // TODO find out why `_describe` needs another backslash
-			if !meta.Nospace.Matches(val.Value) {
+			// a space would end up within an open quote
+			if !meta.Nospace.Matches(val.Value) && Quote() == "" {
 				val.Value = val.Value + " "
 			}
 			val.Display = sanitizer.Replace(val.Display)
@@ -79,7 +72,22 @@ func ActionRawValues(currentWord string, meta common.Meta, values common.RawValu
 				displays[index] = fmt.Sprintf("%v:%v", val.Display, val.Description)
 			}
 		}
-		tagGroup = append(tagGroup, strings.Join([]string{tag, strings.Join(displays, "\n"), strings.Join(vals, "\n")}, "\003"))
+		header := tag
+		if h, ok := meta.Headers[tag]; ok {
+			header = h // e.g. the title of a command group
+		}
+		tagGroup = append(tagGroup, strings.Join([]string{id, sanitizer.Replace(header), strings.Join(displays, "\n"), strings.Join(vals, "\n")}, "\003"))
 	})
-	return fmt.Sprintf("%v\001%v\001%v\001", zstyles{values}.Format(), message{meta}.Format(), strings.Join(tagGroup, "\002")+"\002")
+
+	capability := style.DetectCapability()
+	format := ""
+	if len(tagGroup) > 1 { // group headers are only useful for multiple tags
+		format = header(capability)
+	}
+	return fmt.Sprintf("%v\001%v\001%v\001%v\001", zstyles{values, capability}.Format(), message{meta, capability}.Format(), format, strings.Join(tagGroup, "\002")+"\002")
+}
+
+// header returns the default format for group headers (used unless the `descriptions` format is configured by the user).
+func header(c style.Capability) string {
+	return fmt.Sprintf("\x1b[%vm%%d\x1b[0m", c.SGR(style.Of(style.Carapace.Description, style.Bold)))
 }
@@ -5,20 +5,22 @@ import (
 	"strings"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/env"
 	"github.com/carapace-sh/carapace/pkg/style"
 )
 
 type message struct {
 	common.Meta
+	capability style.Capability
 }
 
 func (m message) Format() string {
 	formatted := make([]string, 0)
-	for _, message := range m.Messages.Get() {
-		formatted = append(formatted, m.formatMessage(message, style.Carapace.Error))
+	for _, message := range m.Messages.GetLevels() {
+		formatted = append(formatted, m.formatMessage(message, message.Style()))
 	}
 	if m.Usage != "" {
-		formatted = append(formatted, m.formatMessage(m.Usage, style.Carapace.Usage))
+		formatted = append(formatted, m.formatMessage(common.Message{Message: m.Usage}, style.Carapace.Usage))
 	}
 
 	if len(formatted) > 0 {
@@ -27,7 +29,7 @@ func (m message) Format() string {
 	return ""
 }
 
-func (m message) formatMessage(message, _style string) string {
+func (m message) formatMessage(message common.Message, _style string) string {
 	msg := strings.NewReplacer(
 		"\n", ``,
 		"\r", ``,
@@ -35,7 +37,11 @@ func (m message) formatMessage(message, _style string) string {
 		"\v", ``,
 		"\f", ``,
 		"\b", ``,
-	).Replace(message)
+	).Replace(message.Message)
 
-	return fmt.Sprintf("\x1b[%vm%v\x1b[%vm", style.SGR(_style), msg, style.SGR("fg-default"))
+	formatted := fmt.Sprintf("\x1b[%vm%v\x1b[%vm", m.capability.SGR(_style), msg, m.capability.SGR("fg-default"))
+	if env.Hyperlink() {
+		formatted = message.Hyperlink(formatted)
+	}
+	return formatted
 }
@@ -4,43 +4,59 @@ package zsh
 import (
 	"fmt"
 
-	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/spf13/cobra"
 )
 
 // Snippet creates the zsh completion script
-func Snippet(cmd *cobra.Command) string {
+func Snippet(cmd *cobra.Command, executable string) string {
 	return fmt.Sprintf(`#compdef %v
+function _%v_shell_env {
+  local name
+  for name in ${(k)parameters}; do
+    [[ ${parameters[${name}]} == (scalar|integer|float)* && ${parameters[${name}]} != *-(export|special)* && ${(P)name} != *$'\n'* ]] && printf '%%s=%%s\n' "${name}" "${(P)name}"
+  done
+}
+
 function _%v_completion {
   local IFS=$'\n'
+  local shellenv="$(_%v_shell_env)"
+  [ "${#shellenv}" -lt 65536 ] || shellenv='' # size of a single environment variable is limited
   
   # shellcheck disable=SC2086,SC2154,SC2155
   if echo ${words}"''" | xargs echo 2>/dev/null > /dev/null; then
-    local lines="$(echo ${words}"''" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace zsh )"
+    local lines="$(echo ${words}"''" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs %v _carapace zsh )"
   elif echo ${words} | sed "s/\$/'/" | xargs echo 2>/dev/null > /dev/null; then
-    local lines="$(echo ${words} | sed "s/\$/'/" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace zsh)"
+    local lines="$(echo ${words} | sed "s/\$/'/" | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs %v _carapace zsh)"
   else
-    local lines="$(echo ${words} | sed 's/$/"/' | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" xargs %v _carapace zsh)"
+    local lines="$(echo ${words} | sed 's/$/"/' | CARAPACE_ZSH_HASH_DIRS="$(hash -d)" CARAPACE_SHELL_ENV="${shellenv}" CARAPACE_ZSH_QUOTE="${compstate[quote]}" xargs %v _carapace zsh)"
   fi
 
-  local zstyle message data
-  IFS=$'\001' read -r -d '' zstyle message data <<<"${lines}"
+  local zstyle message format data configured header=0
+  IFS=$'\001' read -r -d '' zstyle message format data <<<"${lines}"
   # shellcheck disable=SC2154
   zstyle ":completion:${curcontext}:*" list-colors "${zstyle}"
-  zstyle ":completion:${curcontext}:*" group-name ''
+  zstyle -s ":completion:${curcontext}:*" group-name configured || zstyle ":completion:${curcontext}:*" group-name ''
+  if [ -n "$format" ] && ! zstyle -s ":completion:${curcontext}:descriptions" format configured; then
+    zstyle ":completion:${curcontext}:descriptions" format "${format}"
+    header=1
+  fi
   [ -z "$message" ] || _message -r "${message}"
   
-  local block tag displays values displaysArr valuesArr
+  local block tag description displays values displaysArr valuesArr list=''
+  [[ "${CARAPACE_ACCESSIBLE}" == (1|true) ]] && list='-l' # one candidate per line
   while IFS=$'\002' read -r -d $'\002' block; do
-    IFS=$'\003' read -r -d '' tag displays values <<<"${block}"
+    IFS=$'\003' read -r -d '' tag description displays values <<<"${block}"
     # shellcheck disable=SC2034
     IFS=$'\n' read -r -d $'\004' -A displaysArr <<<"${displays}"$'\004'
     IFS=$'\n' read -r -d $'\004' -A valuesArr <<<"${values}"$'\004'
   
-    [[ ${#valuesArr[@]} -gt 1 ]] && _describe -t "${tag}" "${tag}" displaysArr valuesArr -Q -S ''
+    [[ ${#valuesArr[@]} -gt 1 ]] && _describe -t "${tag}" "${description}" displaysArr valuesArr -Q -S '' ${list}
   done <<<"${data}"
+
+  [ "$header" -eq 0 ] || zstyle -d ":completion:${curcontext}:descriptions" format
 }
 compquote '' 2>/dev/null && _%v_completion
 compdef _%v_completion %v
-`, cmd.Name(), cmd.Name(), uid.Executable(), uid.Executable(), uid.Executable(), cmd.Name(), cmd.Name(), cmd.Name())
+`, cmd.Name(), cmd.Name(), cmd.Name(), cmd.Name(), shlex.Quote(executable), shlex.Quote(executable), shlex.Quote(executable), cmd.Name(), cmd.Name(), cmd.Name())
 }
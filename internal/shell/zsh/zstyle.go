@@ -10,28 +10,37 @@ import (
 )
 
 type zstyles struct {
-	rawValues common.RawValues
+	rawValues  common.RawValues
+	capability style.Capability
 }
 
 func (z zstyles) descriptionSGR() string {
 	if s := style.Carapace.Description; s != "" && ui.ParseStyling(s) != nil {
-		return style.SGR(s)
+		return z.capability.SGR(s)
 	}
-	return style.SGR(style.Default)
+	return z.capability.SGR(style.Default)
 }
 
 func (z zstyles) valueSGR(val common.RawValue) string {
 	if val.Style != "" && ui.ParseStyling(val.Style) != nil {
-		return style.SGR(val.Style)
+		return z.capability.SGR(val.Style)
 	}
 
 	if ui.ParseStyling(style.Carapace.Value) != nil {
-		return style.SGR(style.Carapace.Value)
+		return z.capability.SGR(style.Carapace.Value)
 	}
-	return style.SGR(style.Default)
+	return z.capability.SGR(style.Default)
 
 }
 
+func (z zstyles) highlightSGR(val common.RawValue) string {
+	s := style.Carapace.Value
+	if val.Style != "" && ui.ParseStyling(val.Style) != nil {
+		s = val.Style
+	}
+	return z.capability.SGR(style.Of(s, style.Carapace.Match))
+}
+
 func (z zstyles) Format() string {
 	replacer := strings.NewReplacer(
 		"#", `\#`,
@@ -47,6 +56,13 @@ func (z zstyles) Format() string {
 	formatted := make([]string, 0)
 	if len(z.rawValues) < 500 { // disable styling for large amount of values (bad performance)
 		for _, val := range z.rawValues {
+			if before, highlight, after := val.HighlightSplit(); highlight != "" {
+				pattern := fmt.Sprintf("(%v)(%v)(%v)", replacer.Replace(before), replacer.Replace(highlight), replacer.Replace(after))
+				sgr := fmt.Sprintf("%v=%v=%v", z.valueSGR(val), z.highlightSGR(val), z.valueSGR(val))
+				formatted = append(formatted, fmt.Sprintf("=(#b)%v([ ]## -- *)=0=%v=%v", pattern, sgr, z.descriptionSGR()))
+				formatted = append(formatted, fmt.Sprintf("=(#b)%v=0=%v", pattern, sgr))
+				continue
+			}
 			// match value with description
 			formatted = append(formatted, fmt.Sprintf("=(#b)(%v)([ ]## -- *)=0=%v=%v", replacer.Replace(val.Display), z.valueSGR(val), z.descriptionSGR()))
 			// only match value (also matches aliased completions that are placed on the same line if the space allows it)
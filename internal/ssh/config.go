@@ -0,0 +1,54 @@
+// Package ssh provides rudimentary extraction of hosts from ssh config files
+package ssh
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Hosts returns the hosts contained in given ssh config mapped to their HostName.
+// Patterns (e.g. `*.example.com` or `!bastion`) are skipped.
+func Hosts(r io.Reader) (map[string]string, error) {
+	hosts := make(map[string]string)
+	current := make([]string, 0)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, args := splitLine(line)
+		switch strings.ToLower(keyword) {
+		case "host":
+			current = current[:0]
+			for _, host := range strings.Fields(args) {
+				if !strings.ContainsAny(host, "*?!") {
+					hosts[host] = ""
+					current = append(current, host)
+				}
+			}
+		case "match":
+			current = current[:0]
+		case "hostname":
+			for _, host := range current {
+				if hosts[host] == "" {
+					hosts[host] = args
+				}
+			}
+		}
+	}
+	return hosts, scanner.Err()
+}
+
+// splitLine splits given line into keyword and arguments (`Keyword args` or `Keyword=args`).
+func splitLine(line string) (keyword, args string) {
+	index := strings.IndexAny(line, " \t=")
+	if index < 0 {
+		return line, ""
+	}
+	args = strings.TrimLeft(strings.TrimSpace(line[index+1:]), "=")
+	return line[:index], strings.Trim(strings.TrimSpace(args), `"`)
+}
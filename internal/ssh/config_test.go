@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHosts(t *testing.T) {
+	hosts, err := Hosts(strings.NewReader(`
+# comment
+Host first second
+    HostName first.example.com
+    User root
+
+Host *.example.com !bastion
+    User admin
+
+host = third
+	hostname="third.example.com"
+
+Match host fourth
+    HostName fourth.example.com
+`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string]string{
+		"first":  "first.example.com",
+		"second": "first.example.com",
+		"third":  "third.example.com",
+	}
+	if !reflect.DeepEqual(expected, hosts) {
+		t.Errorf("expected %#v, got %#v", expected, hosts)
+	}
+}
@@ -0,0 +1,170 @@
+// Package toml provides rudimentary extraction of key paths from toml documents
+package toml
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Keys returns the key paths contained in given toml document mapped to a short description.
+func Keys(r io.Reader) (map[string]string, error) {
+	keys := make(map[string]string)
+	table := ""
+	closing := "" // delimiter of a multiline value still being skipped
+
+	scanner := bufio.NewScanner(r)
+	for lineNr := 1; scanner.Scan(); lineNr++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if closing != "" {
+			if closesMultiline(line, closing) {
+				closing = ""
+			}
+			continue
+		}
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "[["):
+			end := strings.Index(line, "]]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid array of tables in line %v", lineNr)
+			}
+			table = joinKey(splitKey(line[2:end]))
+			keys[table] = "array of tables"
+
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid table in line %v", lineNr)
+			}
+			table = joinKey(splitKey(line[1:end]))
+			keys[table] = "table"
+
+		default:
+			index := indexOutsideQuotes(line, '=')
+			if index < 0 {
+				return nil, fmt.Errorf("invalid key/value pair in line %v", lineNr)
+			}
+
+			key := joinKey(splitKey(line[:index]))
+			if table != "" {
+				key = table + "." + key
+			}
+
+			value := strings.TrimSpace(line[index+1:])
+			if comment := indexOutsideQuotes(value, '#'); comment >= 0 {
+				value = strings.TrimSpace(value[:comment])
+			}
+			addValue(keys, key, value)
+			closing = opensMultiline(value)
+		}
+	}
+	return keys, scanner.Err()
+}
+
+// addValue adds given key and descends into inline tables (`{ host = "localhost", port = 80 }`).
+func addValue(keys map[string]string, key, value string) {
+	if !strings.HasPrefix(value, "{") || !strings.HasSuffix(value, "}") {
+		keys[key] = value
+		return
+	}
+
+	keys[key] = "inline table"
+	for _, pair := range splitOutside(value[1:len(value)-1], ',') {
+		if index := indexOutsideQuotes(pair, '='); index >= 0 {
+			addValue(keys, key+"."+joinKey(splitKey(pair[:index])), strings.TrimSpace(pair[index+1:]))
+		}
+	}
+}
+
+// splitOutside splits given string at r outside of quotes, arrays and inline tables.
+func splitOutside(s string, r rune) []string {
+	parts := make([]string, 0)
+	var quote rune
+	depth := 0
+	start := 0
+	for index, c := range s {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == r && depth == 0:
+			parts = append(parts, s[start:index])
+			start = index + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// opensMultiline returns the delimiter needed to close given value if it spans multiple lines.
+func opensMultiline(value string) string {
+	for _, delimiter := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(value, delimiter) && strings.Count(value, delimiter) == 1 {
+			return delimiter
+		}
+	}
+	if strings.HasPrefix(value, "[") && strings.Count(value, "[") > strings.Count(value, "]") {
+		return "]"
+	}
+	return ""
+}
+
+func closesMultiline(line, delimiter string) bool {
+	if delimiter == "]" {
+		return strings.HasPrefix(line, "]")
+	}
+	return strings.Contains(line, delimiter)
+}
+
+func indexOutsideQuotes(s string, r rune) int {
+	var quote rune
+	for index, c := range s {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == r:
+			return index
+		}
+	}
+	return -1
+}
+
+func splitKey(s string) []string {
+	parts := make([]string, 0)
+	for {
+		index := indexOutsideQuotes(s, '.')
+		if index < 0 {
+			break
+		}
+		parts = append(parts, s[:index])
+		s = s[index+1:]
+	}
+	parts = append(parts, s)
+
+	for index, part := range parts {
+		part = strings.TrimSpace(part)
+		if len(part) > 1 && (part[0] == '"' || part[0] == '\'') && part[len(part)-1] == part[0] {
+			part = part[1 : len(part)-1]
+		}
+		parts[index] = part
+	}
+	return parts
+}
+
+func joinKey(parts []string) string {
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,55 @@
+package toml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	keys, err := Keys(strings.NewReader(`
+# comment
+title = "example"
+description = """
+multi = line
+"""
+
+[server]
+host = "localhost" # trailing comment
+"quoted.key" = 1
+tls = { enabled = true, "cert" = "a,b", options = { min = "1.2" } }
+ports = [
+  8000,
+  8001,
+]
+
+[[plugins]]
+name.first = 'one'
+`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	expected := map[string]string{
+		"title":                  `"example"`,
+		"description":            `"""`,
+		"server":                 "table",
+		"server.host":            `"localhost"`,
+		"server.quoted.key":      "1",
+		"server.tls":             "inline table",
+		"server.tls.enabled":     "true",
+		"server.tls.cert":        `"a,b"`,
+		"server.tls.options":     "inline table",
+		"server.tls.options.min": `"1.2"`,
+		"server.ports":           "[",
+		"plugins":                "array of tables",
+		"plugins.name.first":     "'one'",
+	}
+	if len(keys) != len(expected) {
+		t.Errorf("expected %v keys [was: %v]", len(expected), keys)
+	}
+	for key, value := range expected {
+		if keys[key] != value {
+			t.Errorf("expected %#v for %#v [was: %#v]", value, key, keys[key])
+		}
+	}
+}
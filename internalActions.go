@@ -1,27 +1,42 @@
 package carapace
 
 import (
+	"debug/buildinfo"
+	"fmt"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/carapace-sh/carapace/internal/common"
 	"github.com/carapace-sh/carapace/internal/env"
 	"github.com/carapace-sh/carapace/internal/pflagfork"
+	"github.com/carapace-sh/carapace/pkg/match"
 	"github.com/carapace-sh/carapace/pkg/style"
 	"github.com/carapace-sh/carapace/pkg/uid"
 	"github.com/carapace-sh/carapace/pkg/util"
 	"github.com/spf13/cobra"
 )
 
-func actionPath(fileSuffixes []string, dirOnly bool) Action {
+func actionPath(opts PathOpts, fileSuffixes []string, dirOnly bool) Action {
 	return ActionCallback(func(c Context) Action {
 		if len(c.Value) == 2 && util.HasVolumePrefix(c.Value) {
 			// TODO should be fixed in Abs or wherever this is happening
 			return ActionValues(c.Value + "/") // prevent `C:` -> `C:.`
 		}
+		if util.HasUNCPrefix(c.Value) && strings.Count(strings.TrimPrefix(c.Value, "//"), "/") < 2 {
+			return ActionMessage("shares of UNC paths can't be listed")
+		}
+
+		fold := util.CaseInsensitiveFilesystem() || match.Current() == match.CASE_INSENSITIVE
+		if index := strings.LastIndex(c.Value, "/"); fold && index >= 0 {
+			c.Value = resolveCase(c, c.Value[:index+1]) + c.Value[index+1:] // insert the actual casing of the folder
+		}
 
-		abs, err := c.Abs(c.Value)
+		abs, err := opts.abs(c, c.Value)
 		if err != nil {
 			return ActionMessage(err.Error())
 		}
@@ -33,6 +48,14 @@ func actionPath(fileSuffixes []string, dirOnly bool) Action {
 			displayFolder = displayFolder + "/"
 		}
 
+		lastLevel := false
+		if level, ok := pathLevel(displayFolder, opts.Expand); ok && opts.Depth > 0 {
+			if level >= opts.Depth {
+				return ActionValues()
+			}
+			lastLevel = level == opts.Depth-1
+		}
+
 		actualFolder := filepath.ToSlash(filepath.Dir(abs))
 		files, err := os.ReadDir(actualFolder)
 		if err != nil {
@@ -40,54 +63,232 @@ func actionPath(fileSuffixes []string, dirOnly bool) Action {
 		}
 
 		showHidden := !strings.HasSuffix(abs, "/") && strings.HasPrefix(filepath.Base(abs), ".")
+		dotfiles := opts.Dotfiles
+		if dotfiles == "" {
+			dotfiles = env.Dotfiles()
+		}
+		switch dotfiles {
+		case "always":
+			showHidden = true
+		case "never":
+			showHidden = false
+		}
+
+		namePrefix := ""
+		if !strings.HasSuffix(abs, "/") {
+			namePrefix = filepath.Base(abs)
+		}
+		m := match.Current()
+		if fold {
+			m = match.CASE_INSENSITIVE
+		}
 
-		vals := make([]string, 0, len(files)*2)
+		maxEntries := env.MaxEntries()
+		overflow := false
+		vals := make([]string, 0, len(files)*3)
 		for _, file := range files {
 			if !showHidden && strings.HasPrefix(file.Name(), ".") {
 				continue
 			}
+			if !m.HasPrefix(file.Name(), namePrefix) {
+				continue // skip early as the remaining checks are expensive for large directories
+			}
+			if maxEntries > 0 && len(vals)/3 >= maxEntries {
+				overflow = true
+				break
+			}
 
 			info, err := file.Info()
 			if err != nil {
 				return ActionMessage(err.Error())
 			}
+			path := filepath.Clean(actualFolder + "/" + file.Name())
+			pathStyle := style.ForPath(path, c)
+
+			description := ""
 			symlinkedDir := false
-			if evaluatedPath, err := filepath.EvalSymlinks(actualFolder + "/" + file.Name()); err == nil {
-				if evaluatedInfo, err := os.Stat(evaluatedPath); err == nil {
-					symlinkedDir = evaluatedInfo.IsDir()
+			if info.Mode()&os.ModeSymlink != 0 {
+				if target, err := os.Readlink(path); err == nil {
+					description = "-> " + target
+				}
+				if evaluatedPath, err := filepath.EvalSymlinks(path); err != nil {
+					if pathStyle == "" {
+						pathStyle = style.Red // broken symlink (orphan style of LS_COLORS takes precedence)
+					}
+				} else if !opts.NoFollowSymlinks {
+					if evaluatedInfo, err := os.Stat(evaluatedPath); err == nil {
+						symlinkedDir = evaluatedInfo.IsDir()
+					}
 				}
 			}
 
 			switch {
+			case (info.IsDir() || symlinkedDir) && lastLevel:
+				if dirOnly {
+					vals = append(vals, displayFolder+file.Name(), description, pathStyle)
+				}
 			case info.IsDir():
-				vals = append(vals, displayFolder+file.Name()+"/", style.ForPath(filepath.Clean(actualFolder+"/"+file.Name()+"/"), c))
+				vals = append(vals, displayFolder+file.Name()+"/", description, pathStyle)
 			case symlinkedDir:
-				vals = append(vals, displayFolder+file.Name()+"/", style.ForPath(filepath.Clean(actualFolder+"/"+file.Name()), c)) // TODO colorist not returning the symlink color
+				vals = append(vals, displayFolder+file.Name()+"/", description, pathStyle) // TODO colorist not returning the symlink color
 			case !dirOnly:
+				if !opts.matches(file.Name()) {
+					continue
+				}
 				if len(fileSuffixes) == 0 {
 					fileSuffixes = []string{""}
 				}
 				for _, suffix := range fileSuffixes {
 					if strings.HasSuffix(file.Name(), suffix) {
-						vals = append(vals, displayFolder+file.Name(), style.ForPath(filepath.Clean(actualFolder+"/"+file.Name()), c))
+						vals = append(vals, displayFolder+file.Name(), description, pathStyle)
 						break
 					}
 				}
 			}
 		}
+		a := ActionStyledValuesDescribed(vals...)
 		if strings.HasPrefix(c.Value, "./") {
-			return ActionStyledValues(vals...).Invoke(Context{}).Prefix("./").ToA()
+			a = a.Invoke(Context{}).Prefix("./").ToA()
+		}
+		a.meta.Fold = fold
+		if overflow {
+			a.meta.Messages.AddLevel(common.LevelInfo, fmt.Sprintf(c.translate("more than %v entries (type more characters)"), maxEntries))
+		}
+		return a
+	})
+}
+
+// pathLevel returns the amount of segments of given folder below the working directory.
+// Depth doesn't apply to absolute, `~` (and with expand variable) paths or those leaving the working directory.
+func pathLevel(folder string, expand bool) (int, bool) {
+	switch {
+	case folder == "":
+		return 0, true
+	case strings.HasPrefix(folder, "/"), strings.HasPrefix(folder, "~"), util.HasVolumePrefix(folder):
+		return 0, false
+	case expand && strings.HasPrefix(folder, "$"):
+		return 0, false
+	}
+
+	switch cleaned := path.Clean(folder); {
+	case cleaned == ".":
+		return 0, true
+	case cleaned == "..", strings.HasPrefix(cleaned, "../"):
+		return 0, false
+	default:
+		return strings.Count(cleaned, "/") + 1, true
+	}
+}
+
+// actionWindowsPath completes paths with backslash separators when the value contains one (only for GOOS=windows).
+func actionWindowsPath(a Action) Action {
+	if runtime.GOOS != "windows" {
+		return a // backslash is a valid character in filenames
+	}
+	return ActionCallback(func(c Context) Action {
+		if !strings.Contains(c.Value, `\`) {
+			return a
+		}
+		return actionBackslashPath(a)
+	})
+}
+
+// actionBackslashPath invokes given path action with forward slashes and converts the separators of its values to backslash.
+func actionBackslashPath(a Action) Action {
+	return ActionCallback(func(c Context) Action {
+		c.Value = strings.ReplaceAll(c.Value, `\`, "/")
+		invoked := a.Invoke(c)
+		for index, v := range invoked.action.rawValues {
+			invoked.action.rawValues[index].Value = strings.ReplaceAll(v.Value, "/", `\`)
+			invoked.action.rawValues[index].Display = strings.ReplaceAll(v.Display, "/", `\`)
+		}
+		return invoked.ToA().NoSpace('\\')
+	})
+}
+
+// actionDescend additionally offers the children of a unique directory matching the value (without the trailing slash)
+// so that these are completed without retyping the slash.
+func actionDescend(f func(c Context) Action) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := f(c).Invoke(c)
+
+		filtered := invoked.action.rawValues.FilterPrefix(c.Value)
+		if invoked.action.meta.Fold {
+			filtered = invoked.action.rawValues.FilterPrefixFold(c.Value)
 		}
-		return ActionStyledValues(vals...)
+		if c.Value == "" || len(filtered) != 1 || !strings.EqualFold(filtered[0].Value, c.Value+"/") {
+			return invoked.ToA()
+		}
+
+		children := c
+		children.Value = filtered[0].Value
+		invokedChildren := f(children).Invoke(children)
+		segment := filtered[0].Value[strings.LastIndex(c.Value, "/")+1:]
+		for index, v := range invokedChildren.action.rawValues {
+			invokedChildren.action.rawValues[index].Display = segment + v.Display // display relative to the folder of the value
+		}
+		return invoked.Merge(invokedChildren).ToA() // keep the directory itself
 	})
 }
 
+// actionStyledPath styles path segments which weren't already styled by actionPath.
+func actionStyledPath(a Action) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		for index, v := range invoked.action.rawValues {
+			if v.Style == "" {
+				invoked.action.rawValues[index].Style = style.ForPath(v.Value, c)
+			}
+		}
+		return invoked.ToA()
+	})
+}
+
+// resolveCase replaces the segments of given folder with their actual casing in the filesystem.
+func resolveCase(c Context, folder string) string {
+	resolved := ""
+	for _, segment := range strings.SplitAfter(folder, "/") {
+		name := strings.TrimSuffix(segment, "/")
+		switch {
+		case name == "", name == ".", name == "..", strings.HasPrefix(name, "~"), util.HasVolumePrefix(name):
+		default:
+			if dir, err := c.Abs(resolved); err == nil {
+				if entries, err := os.ReadDir(dir); err == nil {
+					actual := name
+					for _, entry := range entries {
+						if entry.Name() == name {
+							actual = name // exact match takes precedence
+							break
+						}
+						if strings.EqualFold(entry.Name(), name) {
+							actual = entry.Name()
+						}
+					}
+					segment = actual + strings.TrimPrefix(segment, name)
+				}
+			}
+		}
+		resolved += segment
+	}
+	return resolved
+}
+
+func actionSettings() Action {
+	return ActionCallback(func(c Context) Action {
+		vals := make([]string, 0)
+		for _, setting := range env.GetSettings() {
+			vals = append(vals, setting.Name, setting.Description)
+		}
+		return ActionValuesDescribed(vals...)
+	}).Tag("settings")
+}
+
 func actionFlags(cmd *cobra.Command) Action {
 	return ActionCallback(func(c Context) Action {
 		cmd.InitDefaultHelpFlag()
 		cmd.InitDefaultVersionFlag()
 
-		flagSet := pflagfork.FlagSet{FlagSet: cmd.Flags()}
+		flagSet := flagSet(cmd)
 		isShorthandSeries := flagSet.IsShorthandSeries(c.Value)
 
 		nospace := make([]rune, 0)
@@ -96,7 +297,7 @@ func actionFlags(cmd *cobra.Command) Action {
 			switch {
 			case f.Hidden && !env.Hidden():
 				return // skip hidden flags
-			case f.Deprecated != "":
+			case f.Deprecated != "" && !env.Hidden():
 				return // skip deprecated flags
 			case f.Changed && !f.IsRepeatable():
 				return // don't repeat flag
@@ -104,15 +305,23 @@ func actionFlags(cmd *cobra.Command) Action {
 				return // skip flag of group already set
 			}
 
+			flagStyle := f.Style()
+			shorthandStyle := f.ShorthandStyle()
+			if flagSet.IsRequiredTogether(f.Flag) {
+				flagStyle = style.Of(flagStyle, style.Carapace.FlagRequired) // highlight missing flag of group already set
+				shorthandStyle = style.Of(shorthandStyle, style.Carapace.FlagRequired)
+			}
+			includeShorthand := f.Shorthand != "" && (f.ShorthandDeprecated == "" || env.Hidden())
+
 			if isShorthandSeries {
-				if f.Shorthand != "" && f.ShorthandDeprecated == "" {
+				if includeShorthand {
 					for _, shorthand := range c.Value[1:] {
 						if shorthandFlag := cmd.Flags().ShorthandLookup(string(shorthand)); shorthandFlag != nil && shorthandFlag.Value.Type() != "bool" && shorthandFlag.Value.Type() != "count" && shorthandFlag.NoOptDefVal == "" {
 							return // abort shorthand flag series if a previous one is not bool or count and requires an argument (no default value)
 						}
 					}
-					batch = append(batch, ActionStyledValuesDescribed(f.Shorthand, f.Usage, f.Style()).Tag("shorthand flags").
-						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f), nil }))
+					batch = append(batch, ActionStyledValuesDescribed(f.Shorthand, f.ShorthandDescription(), shorthandStyle).Tag("shorthand flags").
+						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f.Name), nil }))
 					if f.IsOptarg() {
 						nospace = append(nospace, []rune(f.Shorthand)[0])
 					}
@@ -120,16 +329,20 @@ func actionFlags(cmd *cobra.Command) Action {
 			} else {
 				switch f.Mode() {
 				case pflagfork.NameAsShorthand:
-					batch = append(batch, ActionStyledValuesDescribed("-"+f.Name, f.Usage, f.Style()).Tag("longhand flags").
-						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f), nil }))
+					batch = append(batch, ActionStyledValuesDescribed("-"+f.Name, f.Description(), flagStyle).Tag("longhand flags").
+						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f.Name), nil }))
 				case pflagfork.Default:
-					batch = append(batch, ActionStyledValuesDescribed("--"+f.Name, f.Usage, f.Style()).Tag("longhand flags").
-						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f), nil }))
+					prefix := "--"
+					if flagSet.SingleDash {
+						prefix = "-"
+					}
+					batch = append(batch, ActionStyledValuesDescribed(prefix+f.Name, f.Description(), flagStyle).Tag("longhand flags").
+						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f.Name), nil }))
 				}
 
-				if f.Shorthand != "" && f.ShorthandDeprecated == "" {
-					batch = append(batch, ActionStyledValuesDescribed("-"+f.Shorthand, f.Usage, f.Style()).Tag("shorthand flags").
-						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f), nil }))
+				if includeShorthand {
+					batch = append(batch, ActionStyledValuesDescribed("-"+f.Shorthand, f.ShorthandDescription(), shorthandStyle).Tag("shorthand flags").
+						UidF(func(s string, uc uid.Context) (*url.URL, error) { return uid.Flag(cmd, f.Name), nil }))
 				}
 			}
 		})
@@ -160,3 +373,52 @@ func initHelpCompletion(cmd *cobra.Command) {
 		ActionCommands(cmd),
 	)
 }
+
+// completionBridge determines how to delegate completion to given executable by the modules of a go binary
+// (`carapace` supports `_carapace export`, `cobra` supports `__complete`) so that unknown executables aren't invoked.
+func completionBridge(path string) string {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return "" // not a go binary
+	}
+
+	modules := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		modules = append(modules, dep.Path)
+	}
+
+	bridge := ""
+	for _, module := range modules {
+		switch module {
+		case "github.com/carapace-sh/carapace", "github.com/rsteube/carapace":
+			return "carapace"
+		case "github.com/spf13/cobra":
+			bridge = "cobra"
+		}
+	}
+	return bridge
+}
+
+// actionCobraComplete parses the output of cobra's `__complete` command.
+//
+//	value\tdescription
+//	:4
+func actionCobraComplete(output []byte) Action {
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, ":") {
+		return ActionMessage("missing cobra directive")
+	}
+	directive, err := strconv.Atoi(last[1:])
+	if err != nil {
+		return ActionMessage(err.Error())
+	}
+
+	values := make([]string, 0, len(lines)-1)
+	for _, line := range lines[:len(lines)-1] {
+		if !strings.HasPrefix(line, "_activeHelp_ ") {
+			values = append(values, line)
+		}
+	}
+	return compDirective(directive).ToA(values...)
+}
@@ -34,11 +34,59 @@ func (ia InvokedAction) Filter(values ...string) InvokedAction {
 //	b := carapace.ActionValues("B", "C").Invoke(c)
 //	c := a.Merge(b) // ["A", "B", "C"]
 func (ia InvokedAction) Merge(others ...InvokedAction) InvokedAction {
+	return ia.MergeWith(MergeKeepLast, others...)
+}
+
+// MergePolicy decides which duplicate value is kept when merging InvokedActions.
+type MergePolicy int
+
+const (
+	MergeKeepLast           MergePolicy = iota // later values overwrite earlier ones (default)
+	MergeKeepFirst                             // earlier values are kept
+	MergePreferDescribed                       // values with a description are kept (earlier ones first)
+	MergeConcatDescriptions                    // distinct descriptions are joined (other fields are kept from earlier values)
+)
+
+func (p MergePolicy) merge(existing, other common.RawValue) common.RawValue {
+	switch p {
+	case MergeKeepFirst:
+		return existing
+	case MergePreferDescribed:
+		if existing.Description == "" && other.Description != "" {
+			return other
+		}
+		return existing
+	case MergeConcatDescriptions:
+		switch {
+		case other.Description == "":
+		case existing.Description == "":
+			existing.Description = other.Description
+		case !strings.Contains(", "+existing.Description+", ", ", "+other.Description+", "):
+			existing.Description += ", " + other.Description
+		}
+		if existing.Style == "" {
+			existing.Style = other.Style
+		}
+		if existing.Tag == "" {
+			existing.Tag = other.Tag
+		}
+		return existing
+	default:
+		return other
+	}
+}
+
+// MergeWith is like Merge but uses given policy for duplicate values.
+//
+//	a := carapace.ActionValuesDescribed("B", "").Invoke(c)
+//	b := carapace.ActionValuesDescribed("B", "described").Invoke(c)
+//	c := a.MergeWith(carapace.MergePreferDescribed, b) // ["B (described)"]
+func (ia InvokedAction) MergeWith(policy MergePolicy, others ...InvokedAction) InvokedAction {
 	for _, other := range append([]InvokedAction{ia}, others...) {
 		ia.action.rawValues = append(ia.action.rawValues, other.action.rawValues...)
 		ia.action.meta.Merge(other.action.meta)
 	}
-	ia.action.rawValues = ia.action.rawValues.Unique()
+	ia.action.rawValues = ia.action.rawValues.UniqueF(policy.merge)
 	return ia
 }
 
@@ -61,6 +109,14 @@ func (ia InvokedAction) Retain(values ...string) InvokedAction {
 	return ia
 }
 
+// Suggest marks given values as default.
+//
+//	carapace.ActionValues("json", "yaml").Invoke(c).Suggest("json")
+func (ia InvokedAction) Suggest(values ...string) InvokedAction {
+	ia.action.rawValues = ia.action.rawValues.Suggest(values...)
+	return ia
+}
+
 // Suffix adds a suffx to values (only the ones inserted, not the display values)
 //
 //	carapace.ActionValues("apple", "melon", "orange").Invoke(c).Suffix("juice")
@@ -109,13 +165,32 @@ func tokenize(s string, dividers ...string) []string {
 //	a := carapace.ActionValues("A/B/C", "A/C", "B/C", "C").Invoke(c)
 //	b := a.ToMultiPartsA("/") // completes segments separately (first one is ["A/", "B/", "C"])
 func (ia InvokedAction) ToMultiPartsA(dividers ...string) Action {
+	nospace := make([]rune, 0, len(dividers))
+	for _, divider := range dividers {
+		if runes := []rune(divider); len(runes) == 0 {
+			nospace = append(nospace, '*')
+			break
+		} else {
+			nospace = append(nospace, runes[len(runes)-1])
+		}
+	}
+	return ia.toMultiPartsA(func(s string) []string { return tokenize(s, dividers...) }, nospace...)
+}
+
+// toMultiPartsA is like ToMultiPartsA but with a custom tokenizer (segments keep their divider suffix).
+func (ia InvokedAction) toMultiPartsA(tokenize func(s string) []string, nospace ...rune) Action {
 	return ActionCallback(func(c Context) Action {
-		splittedCV := tokenize(c.Value, dividers...)
+		splittedCV := tokenize(c.Value)
+
+		m := match.Current()
+		if ia.action.meta.Fold {
+			m = match.CASE_INSENSITIVE
+		}
 
 		uniqueVals := make(map[string]common.RawValue)
 		for _, val := range ia.action.rawValues {
-			if match.HasPrefix(val.Value, c.Value) {
-				if splitted := tokenize(val.Value, dividers...); len(splitted) >= len(splittedCV) {
+			if m.HasPrefix(val.Value, c.Value) {
+				if splitted := tokenize(val.Value); len(splitted) >= len(splittedCV) {
 					v := strings.Join(splitted[:len(splittedCV)], "")
 					d := splitted[len(splittedCV)-1]
 
@@ -127,6 +202,10 @@ func (ia InvokedAction) ToMultiPartsA(dividers ...string) Action {
 							Style:       val.Style,
 							Tag:         val.Tag,
 							Uid:         val.Uid,
+							Source:      val.Source,
+							Link:        val.Link,
+
+							Documentation: val.Documentation,
 						}
 					} else {
 						uniqueVals[v] = common.RawValue{
@@ -136,6 +215,7 @@ func (ia InvokedAction) ToMultiPartsA(dividers ...string) Action {
 							Style:       "",
 							Tag:         val.Tag,
 							Uid:         val.Uid,
+							Source:      val.Source,
 						}
 					}
 				}
@@ -148,20 +228,14 @@ func (ia InvokedAction) ToMultiPartsA(dividers ...string) Action {
 		}
 
 		a := Action{rawValues: vals}
-		for _, divider := range dividers {
-			if runes := []rune(divider); len(runes) == 0 {
-				a.meta.Nospace.Add('*')
-				break
-			} else {
-				a.meta.Nospace.Add(runes[len(runes)-1])
-			}
-		}
+		a.meta.Merge(ia.action.meta)
+		a.meta.Nospace.Add(nospace...)
 		return a
 	})
 }
 
-func (ia InvokedAction) value(shell string, value string) string {
-	return _shell.Value(shell, value, ia.action.meta, ia.action.rawValues)
+func (ia InvokedAction) value(c Context, shell string, value string) string {
+	return _shell.Value(shell, value, ia.action.meta, ia.action.rawValues, c.translate)
 }
 
 func init() {
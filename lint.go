@@ -0,0 +1,124 @@
+package carapace
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/spf13/cobra"
+)
+
+// lintTimeout is the maximum duration an action may take during Lint.
+var lintTimeout = 5 * time.Second
+
+// Lint invokes every registered action of the command tree with an empty Context
+// and reports the ones that fail immediately (error message, panic or timeout)
+// along with their explanation (see Action.Explain).
+//
+//	func TestLint(t *testing.T) {
+//	    for _, err := range carapace.Gen(rootCmd).Lint() {
+//	        t.Error(err)
+//	    }
+//	}
+//
+// External commands executed with (Context).Command or ActionExecCommand are not run (their output is empty)
+// and nothing is cached, so only the callbacks themselves are verified.
+// An action exceeding the timeout keeps running in the background until its callback returns.
+func (c Carapace) Lint() []error {
+	errs := make([]error, 0)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, target := range storage.lintTargets(cmd) {
+			if err := lintAction(cmd, target.action); err != nil {
+				name := target.name
+				if explanation := target.action.explanation; explanation != "" {
+					name = fmt.Sprintf("%v (%v)", name, explanation)
+				}
+				errs = append(errs, fmt.Errorf("%v: %v: %v", uid.Command(cmd), name, err.Error()))
+			}
+		}
+		for _, subcmd := range cmd.Commands() {
+			if subcmd.Name() != "_carapace" {
+				walk(subcmd)
+			}
+		}
+	}
+	walk(c.cmd.Root())
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+type lintTarget struct {
+	name   string
+	action Action
+}
+
+// lintTargets returns the actions registered for given command.
+func (s _storage) lintTargets(cmd *cobra.Command) []lintTarget {
+	storageMutex.RLock()
+	entry, ok := s[cmd]
+	storageMutex.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	targets := make([]lintTarget, 0)
+	entry.flagMutex.RLock()
+	for name, a := range entry.flag {
+		targets = append(targets, lintTarget{"flag " + name, a})
+	}
+	entry.flagMutex.RUnlock()
+
+	for index, a := range entry.positional {
+		targets = append(targets, lintTarget{fmt.Sprintf("positional[%v]", index), a})
+	}
+	if entry.positionalAny != nil {
+		targets = append(targets, lintTarget{"positionalAny", *entry.positionalAny})
+	}
+	for index, a := range entry.dash {
+		targets = append(targets, lintTarget{fmt.Sprintf("dash[%v]", index), a})
+	}
+	if entry.dashAny != nil {
+		targets = append(targets, lintTarget{"dashAny", *entry.dashAny})
+	}
+	return targets
+}
+
+// lintAction invokes given action recovering from panics and giving up after lintTimeout.
+func lintAction(cmd *cobra.Command, a Action) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+
+		context := NewContext()
+		context.cmd = cmd
+		context.dryRun = true
+		messages := make([]string, 0)
+		for _, message := range a.Invoke(context).action.meta.Messages.GetLevels() {
+			if message.Level == common.LevelError {
+				messages = append(messages, message.Message)
+			}
+		}
+		if len(messages) > 0 {
+			done <- errors.New(strings.Join(messages, ", "))
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(lintTimeout):
+		return fmt.Errorf("timeout exceeded: %v", lintTimeout)
+	}
+}
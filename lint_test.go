@@ -0,0 +1,57 @@
+package carapace
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLint(t *testing.T) {
+	defer func(timeout time.Duration) { lintTimeout = timeout }(lintTimeout)
+	lintTimeout = 100 * time.Millisecond
+
+	rootCmd := &cobra.Command{Use: "lint"}
+	rootCmd.Flags().String("valid", "", "")
+	rootCmd.Flags().String("broken", "", "")
+	rootCmd.Flags().String("exec", "", "")
+	subCmd := &cobra.Command{Use: "sub"}
+	rootCmd.AddCommand(subCmd)
+
+	Gen(rootCmd).FlagCompletion(ActionMap{
+		"valid":  ActionValues("one", "two"),
+		"broken": ActionMessage("broken callback").Explain("always fails"),
+		"exec": ActionExecCommand("carapace-lint-missing")(func(output []byte) Action {
+			return ActionValues() // not executed
+		}),
+	})
+	Gen(rootCmd).PositionalCompletion(
+		ActionCallback(func(c Context) Action {
+			var m map[string]Action
+			m["panic"] = ActionValues()
+			return m["panic"]
+		}),
+		ActionWarning("warnings are fine"),
+	)
+	Gen(subCmd).PositionalAnyCompletion(
+		ActionCallback(func(c Context) Action {
+			time.Sleep(time.Second)
+			return ActionValues()
+		}),
+	)
+
+	errs := Gen(rootCmd).Lint()
+	actual := make([]string, 0, len(errs))
+	for _, err := range errs {
+		actual = append(actual, err.Error())
+	}
+	expected := []string{
+		"cmd://lint/sub: positionalAny: timeout exceeded: 100ms",
+		"cmd://lint: flag broken (always fails): broken callback",
+		"cmd://lint: positional[0]: panic: assignment to entry in nil map",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
@@ -1,5 +1,20 @@
 package carapace
 
-import "github.com/carapace-sh/carapace/internal/log"
+import (
+	stdlog "log"
 
-var LOG = log.LOG
+	"github.com/carapace-sh/carapace/internal/log"
+)
+
+// LOG writes debug entries to the logger of the current invocation.
+//
+// Deprecated: use Log() instead.
+var LOG = stdlog.New(log.Default.Writer(log.LevelDebug), "", 0)
+
+// Log returns the leveled logger of the current invocation.
+// Entries are written to the log file when enabled with `CARAPACE_LOG` (trace, debug, info, error).
+//
+//	carapace.Log().Debugf("fetched %v namespaces", len(namespaces))
+func Log() *log.Logger {
+	return log.Default
+}
@@ -0,0 +1,115 @@
+package carapace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+
+	shlex "github.com/carapace-sh/carapace-shlex"
+	"github.com/carapace-sh/carapace/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+// lspServer answers completion requests of editors received by `_carapace lsp`.
+//
+// Each request is completed by a separate invocation of `_carapace export`
+// so that flag state of the command does not leak between requests.
+type lspServer struct {
+	cmd         *cobra.Command
+	initialized bool
+	complete    func(args ...string) ([]byte, error)
+	last        struct {
+		args   []string
+		result json.RawMessage
+	}
+}
+
+func newLspServer(cmd *cobra.Command) *lspServer {
+	return &lspServer{
+		cmd: cmd,
+		complete: func(args ...string) ([]byte, error) {
+			executable, err := os.Executable()
+			if err != nil {
+				return nil, err
+			}
+			return exec.Command(executable, append([]string{"_carapace", "export"}, args...)...).Output()
+		},
+	}
+}
+
+var lspEmpty = json.RawMessage(`{"messages":[],"nospace":"","usage":"","values":[]}`)
+
+type lspCompleteParams struct {
+	Line  string `json:"line"`
+	Point *int   `json:"point"` // byte offset of the cursor (defaults to the end of line)
+}
+
+func (s *lspServer) handle(method string, params json.RawMessage) (interface{}, error) {
+	Log().Debugf("lsp: %v %s", method, params)
+
+	switch method {
+	case "initialize":
+		s.initialized = true
+		return map[string]interface{}{
+			"serverInfo": map[string]string{
+				"name": s.cmd.Root().Name(),
+			},
+			"capabilities": map[string]interface{}{
+				"complete": true,
+			},
+		}, nil
+	case "initialized", "shutdown", "exit":
+		return nil, nil
+	case "complete":
+		if !s.initialized {
+			return nil, &lsp.Error{Code: lsp.ServerNotInitialized, Message: "server not initialized"}
+		}
+
+		var p lspCompleteParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &lsp.Error{Code: lsp.InvalidParams, Message: err.Error()}
+		}
+		point := len(p.Line)
+		if p.Point != nil {
+			point = *p.Point
+		}
+		if point < 0 || point > len(p.Line) {
+			return nil, &lsp.Error{Code: lsp.InvalidParams, Message: fmt.Sprintf("point out of range: %v", point)}
+		}
+		return s.completeLine(p.Line[:point])
+	default:
+		return nil, &lsp.Error{Code: lsp.MethodNotFound, Message: fmt.Sprintf("unknown method: %v", method)}
+	}
+}
+
+// completeLine completes the current pipeline of given line (up to the cursor).
+// The previous result is reused if the words did not change (e.g. cursor moved back and forth).
+func (s *lspServer) completeLine(line string) (json.RawMessage, error) {
+	tokens, err := shlex.Split(line)
+	if err != nil {
+		return nil, &lsp.Error{Code: lsp.InvalidParams, Message: err.Error()}
+	}
+
+	args := tokens.CurrentPipeline().Words().Strings()
+	if len(args) < 2 {
+		return lspEmpty, nil // nothing to complete yet
+	}
+
+	if s.last.result != nil && reflect.DeepEqual(s.last.args, args) {
+		return s.last.result, nil
+	}
+
+	output, err := s.complete(args...)
+	if err != nil {
+		return nil, err
+	}
+	if output = bytes.TrimSpace(output); !json.Valid(output) {
+		return nil, fmt.Errorf("invalid export: %s", output)
+	}
+	s.last.args = args
+	s.last.result = json.RawMessage(output)
+	return s.last.result, nil
+}
@@ -0,0 +1,71 @@
+package carapace
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/carapace-sh/carapace/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+func TestLspServer(t *testing.T) {
+	calls := make([][]string, 0)
+	s := newLspServer(&cobra.Command{Use: "example"})
+	s.complete = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte(`{"values":[]}` + "\n"), nil
+	}
+
+	code := func(err error) int {
+		var rpcErr *lsp.Error
+		if errors.As(err, &rpcErr) {
+			return rpcErr.Code
+		}
+		return 0
+	}
+
+	if _, err := s.handle("complete", json.RawMessage(`{"line":"example "}`)); code(err) != lsp.ServerNotInitialized {
+		t.Errorf("expected not initialized [was: %v]", err)
+	}
+
+	result, err := s.handle("initialize", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if m, _ := json.Marshal(result); string(m) != `{"capabilities":{"complete":true},"serverInfo":{"name":"example"}}` {
+		t.Errorf("unexpected initialize result: %s", m)
+	}
+
+	for _, line := range []string{
+		`{"line":"example action --values "}`,
+		`{"line":"example action --values ", "point":24}`, // unchanged words
+		`{"line":"echo | example action --val", "point":26}`,
+		`{"line":"example"}`, // nothing to complete
+	} {
+		result, err := s.handle("complete", json.RawMessage(line))
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if _, ok := result.(json.RawMessage); !ok {
+			t.Errorf("unexpected result: %#v", result)
+		}
+	}
+
+	expected := [][]string{
+		{"example", "action", "--values", ""},
+		{"example", "action", "--va"},
+	}
+	if !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected %#v [was: %#v]", expected, calls)
+	}
+
+	if _, err := s.handle("complete", json.RawMessage(`{"line":"example ","point":9}`)); code(err) != lsp.InvalidParams {
+		t.Errorf("expected invalid params [was: %v]", err)
+	}
+
+	if _, err := s.handle("unknown", nil); code(err) != lsp.MethodNotFound {
+		t.Errorf("expected method not found [was: %v]", err)
+	}
+}
@@ -0,0 +1,84 @@
+package carapace
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+type _macros struct {
+	mutex sync.RWMutex
+	f     map[string]func(args string) Action
+}
+
+var macros = _macros{f: make(map[string]func(args string) Action)}
+
+var rMacro = regexp.MustCompile(`^\$([a-zA-Z0-9_.-]+)(\((.*)\))?$`)
+
+func init() {
+	RegisterMacro("directories", func(args string) Action { return ActionDirectories() })
+	RegisterMacro("executables", func(args string) Action { return ActionExecutables() })
+	RegisterMacro("files", func(args string) Action {
+		var suffixes []string
+		if err := yaml.Unmarshal([]byte(args), &suffixes); err != nil {
+			return ActionMessage(err.Error())
+		}
+		return ActionFiles(suffixes...)
+	})
+	RegisterMacro("groups", func(args string) Action { return ActionGroups() })
+	RegisterMacro("message", func(args string) Action { return ActionMessage(args) })
+	RegisterMacro("services", func(args string) Action { return ActionServices() })
+	RegisterMacro("signals", func(args string) Action { return ActionSignals() })
+	RegisterMacro("users", func(args string) Action { return ActionUsers() })
+}
+
+// RegisterMacro registers a named action resolvable with ActionMacro (`$name` or `$name(args)`).
+// Registering an existing name replaces the macro.
+//
+//	carapace.RegisterMacro("git.branches", func(args string) carapace.Action {
+//		return carapace.ActionExecCommand("git", "branch", "--format=%(refname:short)")(func(output []byte) carapace.Action {
+//			return carapace.ActionValues(strings.Split(string(output), "\n")...)
+//		})
+//	})
+func RegisterMacro(name string, f func(args string) Action) {
+	macros.mutex.Lock()
+	defer macros.mutex.Unlock()
+	macros.f[name] = f
+}
+
+// Macros returns the names of registered macros.
+func Macros() []string {
+	macros.mutex.RLock()
+	defer macros.mutex.RUnlock()
+
+	names := make([]string, 0, len(macros.f))
+	for name := range macros.f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActionMacro completes given macro (e.g. from a spec file).
+// Arguments are passed as is (`$files([.go, .md])` passes `[.go, .md]`).
+//
+//	carapace.ActionMacro("$files([.go, go.mod])")
+func ActionMacro(s string) Action {
+	return ActionCallback(func(c Context) Action {
+		matches := rMacro.FindStringSubmatch(strings.TrimSpace(s))
+		if matches == nil {
+			return ActionMessage("invalid macro: %#v", s)
+		}
+
+		macros.mutex.RLock()
+		f, ok := macros.f[matches[1]]
+		macros.mutex.RUnlock()
+		if !ok {
+			return ActionMessage("unknown macro: %#v", matches[1])
+		}
+		return f(matches[3])
+	})
+}
@@ -0,0 +1,47 @@
+package carapace
+
+import "testing"
+
+func TestActionMacro(t *testing.T) {
+	RegisterMacro("test.values", func(args string) Action {
+		return ActionValues("prefix-"+args, "other")
+	})
+
+	assertEqual(t,
+		ActionValues("prefix-(nested)", "other").Invoke(Context{}),
+		ActionMacro("$test.values((nested))").Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionValues("prefix-", "other").Invoke(Context{}),
+		ActionMacro("$test.values").Invoke(Context{}),
+	)
+
+	if vals := ActionMacro("$files([.md])").Invoke(Context{}).action.rawValues.Retain("README.md", "go.mod"); len(vals) != 1 || vals[0].Value != "README.md" {
+		t.Errorf("expected files to be filtered by suffix: %#v", vals)
+	}
+
+	assertEqual(t,
+		ActionMessage(`unknown macro: "missing"`).Invoke(Context{}),
+		ActionMacro("$missing(args)").Invoke(Context{}),
+	)
+
+	assertEqual(t,
+		ActionMessage(`invalid macro: "files"`).Invoke(Context{}),
+		ActionMacro("files").Invoke(Context{}),
+	)
+}
+
+func TestMacros(t *testing.T) {
+	RegisterMacro("test.listed", func(args string) Action { return ActionValues() })
+
+	found := false
+	for _, name := range Macros() {
+		if name == "test.listed" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected registered macro to be listed: %#v", Macros())
+	}
+}
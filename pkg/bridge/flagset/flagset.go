@@ -0,0 +1,62 @@
+// Package flagset bridges programs using flag.FlagSet or a bare pflag.FlagSet to carapace.
+package flagset
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/carapace-sh/carapace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Command wraps given flag.FlagSet in a synthetic command.
+// Flags are passed with a single dash (`-name value`) like the flag package expects.
+//
+//	cmd := flagset.Command(flag.CommandLine)
+//	carapace.Gen(cmd).FlagCompletion(carapace.ActionMap{
+//		"file": carapace.ActionFiles(),
+//	})
+//	if flagset.Complete(cmd) {
+//		return
+//	}
+//	flag.Parse()
+func Command(fs *flag.FlagSet) *cobra.Command {
+	cmd := command(fs.Name())
+	cmd.Flags().AddGoFlagSet(fs)
+	carapace.Gen(cmd).SingleDashLonghand()
+	return cmd
+}
+
+// PflagCommand wraps given pflag.FlagSet in a synthetic command.
+func PflagCommand(fs *pflag.FlagSet) *cobra.Command {
+	cmd := command(fs.Name())
+	cmd.Flags().AddFlagSet(fs)
+	carapace.Gen(cmd)
+	return cmd
+}
+
+// Complete executes the hidden completion command if it was invoked (`_carapace` as first argument).
+func Complete(cmd *cobra.Command) bool {
+	if len(os.Args) < 2 || os.Args[1] != "_carapace" {
+		return false
+	}
+	cmd.SetArgs(os.Args[1:])
+	cmd.Execute() // errors are already printed by cobra
+	return true
+}
+
+func command(name string) *cobra.Command {
+	if name == "" {
+		name = os.Args[0]
+	}
+	return &cobra.Command{
+		Use:  filepath.Base(name),
+		Args: cobra.ArbitraryArgs,
+		Run:  func(cmd *cobra.Command, args []string) {},
+		CompletionOptions: cobra.CompletionOptions{
+			DisableDefaultCmd: true,
+		},
+	}
+}
@@ -0,0 +1,44 @@
+package flagset
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func complete(t *testing.T, cmd *cobra.Command, args ...string) string {
+	var stdout bytes.Buffer
+	cmd.SetOut(&stdout)
+	cmd.SetArgs(append([]string{"_carapace", "export", ""}, args...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err.Error())
+	}
+	return stdout.String()
+}
+
+func TestCommand(t *testing.T) {
+	fs := flag.NewFlagSet("stdlib", flag.ContinueOnError)
+	fs.String("name", "", "name flag")
+	fs.Bool("verbose", false, "verbose flag")
+
+	cmd := Command(fs)
+	if cmd.Name() != "stdlib" {
+		t.Errorf("unexpected name: %v", cmd.Name())
+	}
+	if output := complete(t, cmd, "-n"); !strings.Contains(output, `"value":"-name"`) {
+		t.Errorf("should complete single dash longhand: %v", output)
+	}
+}
+
+func TestPflagCommand(t *testing.T) {
+	fs := pflag.NewFlagSet("pflag", pflag.ContinueOnError)
+	fs.StringP("name", "n", "", "name flag")
+
+	if output := complete(t, PflagCommand(fs), "--n"); !strings.Contains(output, `"value":"--name"`) {
+		t.Errorf("should complete longhand: %v", output)
+	}
+}
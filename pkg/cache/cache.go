@@ -28,3 +28,10 @@ func Cache(timeout time.Duration, keys ...key.Key) func(f func() ([]byte, error)
 		return content, nil
 	}
 }
+
+// Invalidate removes entries cached with given keys (e.g. after `tool login` changed the state).
+//
+//	cache.Invalidate(key.String("accounts"))
+func Invalidate(keys ...key.Key) error {
+	return cache.Invalidate(keys...)
+}
@@ -0,0 +1,7 @@
+//go:build darwin
+
+package etc
+
+func defaultSource() Source {
+	return Fallback{Dscl{}, Files{}, Embedded{}} // `/etc/passwd` only contains system accounts
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !windows
+
+package etc
+
+func defaultSource() Source {
+	return Fallback{Getent{}, Files{}, Embedded{}} // getent includes the files (and e.g. LDAP) when available
+}
@@ -0,0 +1,7 @@
+//go:build windows
+
+package etc
+
+func defaultSource() Source {
+	return Fallback{Netapi{}, Embedded{}}
+}
@@ -0,0 +1,121 @@
+package etc
+
+import (
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// Dscl queries the local directory service of darwin with `dscl`.
+type Dscl struct{}
+
+// Users returns the users of the local directory node (including those missing in `/etc/passwd`).
+func (d Dscl) Users() ([]User, error) {
+	uids, err := dsclList("/Users", "UniqueID")
+	if err != nil {
+		return nil, err
+	}
+	gids, err := dsclList("/Users", "PrimaryGroupID")
+	if err != nil {
+		return nil, err
+	}
+	names, err := dsclList("/Users", "RealName")
+	if err != nil {
+		return nil, err
+	}
+	homes, err := dsclList("/Users", "NFSHomeDirectory")
+	if err != nil {
+		return nil, err
+	}
+	shells, err := dsclList("/Users", "UserShell")
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]User, 0, len(uids))
+	for _, name := range sortedKeys(uids) {
+		users = append(users, User{
+			Name:        name,
+			Uid:         uids[name],
+			Gid:         gids[name],
+			Description: names[name],
+			Home:        homes[name],
+			Shell:       shells[name],
+		})
+	}
+	return users, nil
+}
+
+// Groups returns the groups of the local directory node.
+func (d Dscl) Groups() ([]Group, error) {
+	gids, err := dsclList("/Groups", "PrimaryGroupID")
+	if err != nil {
+		return nil, err
+	}
+	names, err := dsclList("/Groups", "RealName")
+	if err != nil {
+		return nil, err
+	}
+	members, err := dsclList("/Groups", "GroupMembership")
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]Group, 0, len(gids))
+	for _, name := range sortedKeys(gids) {
+		group := Group{
+			Name:        name,
+			Gid:         gids[name],
+			Description: names[name],
+		}
+		if m := members[name]; m != "" {
+			group.Members = strings.Fields(m)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// Services returns no services as these aren't part of the directory service.
+func (d Dscl) Services() ([]Service, error) {
+	return nil, nil
+}
+
+// Signals returns no signals as these aren't part of the directory service.
+func (d Dscl) Signals() ([]Signal, error) {
+	return nil, nil
+}
+
+// dsclList returns given attribute of the records in given path.
+func dsclList(path, attribute string) (map[string]string, error) {
+	output, err := exec.Command("dscl", ".", "-list", path, attribute).Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseDsclList(string(output)), nil
+}
+
+// parseDsclList parses lines like `root    0` (attributes with spaces span the rest of the line).
+func parseDsclList(content string) map[string]string {
+	result := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 0:
+		case 1:
+			result[fields[0]] = ""
+		default:
+			result[fields[0]] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), fields[0]))
+		}
+	}
+	return result
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
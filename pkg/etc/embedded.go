@@ -0,0 +1,104 @@
+package etc
+
+import (
+	"os/user"
+	"runtime"
+
+	"github.com/carapace-sh/carapace/pkg/ps"
+)
+
+// Embedded provides the current user along with common entries that don't depend on any files.
+type Embedded struct{}
+
+// Users returns `root` and the current user (windows: only the current user).
+func (e Embedded) Users() ([]User, error) {
+	users := make([]User, 0, 2)
+	if runtime.GOOS != "windows" {
+		users = append(users, User{Name: "root", Uid: "0", Gid: "0", Home: "/root", Shell: "/bin/sh"})
+	}
+	if current, err := user.Current(); err == nil && current.Username != "root" { // falls back to $USER and $HOME
+		users = append(users, User{
+			Name:        current.Username,
+			Uid:         current.Uid,
+			Gid:         current.Gid,
+			Description: current.Name,
+			Home:        current.HomeDir,
+		})
+	}
+	return users, nil
+}
+
+// Groups returns `root` and the primary group of the current user (windows: only the latter).
+func (e Embedded) Groups() ([]Group, error) {
+	groups := make([]Group, 0, 2)
+	if runtime.GOOS != "windows" {
+		groups = append(groups, Group{Name: "root", Gid: "0"})
+	}
+	if current, err := user.Current(); err == nil && current.Gid != "0" {
+		if group, err := user.LookupGroupId(current.Gid); err == nil {
+			groups = append(groups, Group{Name: group.Name, Gid: group.Gid})
+		}
+	}
+	return groups, nil
+}
+
+// Services returns well-known services registered with IANA.
+func (e Embedded) Services() ([]Service, error) {
+	services := make([]Service, len(wellKnownServices))
+	copy(services, wellKnownServices)
+	return services, nil
+}
+
+// Signals returns the signals defined by `syscall` of the target platform and architecture.
+func (e Embedded) Signals() ([]Signal, error) {
+	signals := make([]Signal, 0, len(ps.Signals()))
+	for _, signal := range ps.Signals() {
+		signals = append(signals, Signal{Name: signal.Name, Number: signal.Number})
+	}
+	return signals, nil
+}
+
+var wellKnownServices = []Service{
+	{Name: "ftp-data", Port: 20, Protocol: "tcp"},
+	{Name: "ftp", Port: 21, Protocol: "tcp"},
+	{Name: "ssh", Port: 22, Protocol: "tcp"},
+	{Name: "telnet", Port: 23, Protocol: "tcp"},
+	{Name: "smtp", Port: 25, Protocol: "tcp", Aliases: []string{"mail"}},
+	{Name: "domain", Port: 53, Protocol: "tcp"},
+	{Name: "domain", Port: 53, Protocol: "udp"},
+	{Name: "bootps", Port: 67, Protocol: "udp"},
+	{Name: "bootpc", Port: 68, Protocol: "udp"},
+	{Name: "tftp", Port: 69, Protocol: "udp"},
+	{Name: "http", Port: 80, Protocol: "tcp", Aliases: []string{"www"}},
+	{Name: "kerberos", Port: 88, Protocol: "tcp"},
+	{Name: "kerberos", Port: 88, Protocol: "udp"},
+	{Name: "pop3", Port: 110, Protocol: "tcp"},
+	{Name: "sunrpc", Port: 111, Protocol: "tcp", Aliases: []string{"portmapper"}},
+	{Name: "sunrpc", Port: 111, Protocol: "udp", Aliases: []string{"portmapper"}},
+	{Name: "ntp", Port: 123, Protocol: "udp"},
+	{Name: "imap", Port: 143, Protocol: "tcp", Aliases: []string{"imap2"}},
+	{Name: "snmp", Port: 161, Protocol: "udp"},
+	{Name: "ldap", Port: 389, Protocol: "tcp"},
+	{Name: "https", Port: 443, Protocol: "tcp"},
+	{Name: "https", Port: 443, Protocol: "udp"},
+	{Name: "microsoft-ds", Port: 445, Protocol: "tcp"},
+	{Name: "submissions", Port: 465, Protocol: "tcp", Aliases: []string{"ssmtp", "smtps"}},
+	{Name: "syslog", Port: 514, Protocol: "udp"},
+	{Name: "submission", Port: 587, Protocol: "tcp"},
+	{Name: "ipp", Port: 631, Protocol: "tcp"},
+	{Name: "ldaps", Port: 636, Protocol: "tcp"},
+	{Name: "rsync", Port: 873, Protocol: "tcp"},
+	{Name: "imaps", Port: 993, Protocol: "tcp"},
+	{Name: "pop3s", Port: 995, Protocol: "tcp"},
+	{Name: "openvpn", Port: 1194, Protocol: "udp"},
+	{Name: "ms-sql-s", Port: 1433, Protocol: "tcp"},
+	{Name: "nfs", Port: 2049, Protocol: "tcp"},
+	{Name: "nfs", Port: 2049, Protocol: "udp"},
+	{Name: "mysql", Port: 3306, Protocol: "tcp"},
+	{Name: "ms-wbt-server", Port: 3389, Protocol: "tcp"},
+	{Name: "postgresql", Port: 5432, Protocol: "tcp", Aliases: []string{"postgres"}},
+	{Name: "amqp", Port: 5672, Protocol: "tcp"},
+	{Name: "x11", Port: 6000, Protocol: "tcp"},
+	{Name: "redis", Port: 6379, Protocol: "tcp"},
+	{Name: "http-alt", Port: 8080, Protocol: "tcp", Aliases: []string{"webcache"}},
+}
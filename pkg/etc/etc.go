@@ -0,0 +1,129 @@
+// Package etc provides users, groups and services commonly read from `/etc` (along with signals)
+// with embedded fallbacks for platforms and minimal containers lacking these files.
+package etc
+
+import (
+	"runtime"
+	"strings"
+)
+
+// User is an account of the operating system.
+type User struct {
+	Name        string
+	Uid         string // numeric on unix (relative id or SID on windows)
+	Gid         string
+	Description string // GECOS field (usually the full name)
+	Home        string
+	Shell       string
+}
+
+// Group is a group of the operating system.
+type Group struct {
+	Name        string
+	Gid         string // empty on windows
+	Description string
+	Members     []string
+}
+
+// Service is a named network service.
+type Service struct {
+	Name     string
+	Port     int
+	Protocol string // `tcp` or `udp`
+	Aliases  []string
+}
+
+// Signal is a signal of the operating system.
+type Signal struct {
+	Name   string // name without `SIG` prefix
+	Number int
+}
+
+// Privileged checks if the user is `root` (or the builtin Administrator on windows).
+func (u User) Privileged() bool {
+	if runtime.GOOS == "windows" {
+		return u.Uid == "500" || strings.HasSuffix(u.Uid, "-500")
+	}
+	return u.Uid == "0"
+}
+
+// System checks if the user is a service account without login shell.
+func (u User) System() bool {
+	return strings.HasSuffix(u.Shell, "/nologin") || strings.HasSuffix(u.Shell, "/false")
+}
+
+// Source provides users, groups, services and signals.
+type Source interface {
+	Users() ([]User, error)
+	Groups() ([]Group, error)
+	Services() ([]Service, error)
+	Signals() ([]Signal, error)
+}
+
+// Default is the source used by Users, Groups, Services and Signals.
+// It uses the platform specific source (Netapi on windows, Dscl on darwin, Getent and Files otherwise)
+// and falls back to the embedded tables.
+var Default Source = defaultSource()
+
+// Users returns the users of the Default source.
+func Users() ([]User, error) {
+	return Default.Users()
+}
+
+// Groups returns the groups of the Default source.
+func Groups() ([]Group, error) {
+	return Default.Groups()
+}
+
+// Services returns the services of the Default source.
+func Services() ([]Service, error) {
+	return Default.Services()
+}
+
+// Signals returns the signals of the Default source.
+func Signals() ([]Signal, error) {
+	return Default.Signals()
+}
+
+// Fallback returns the first non-empty result of its sources.
+type Fallback []Source
+
+// Users returns the users of the first source providing any.
+func (f Fallback) Users() (users []User, err error) {
+	for _, source := range f {
+		if users, err = source.Users(); err == nil && len(users) > 0 {
+			break
+		}
+	}
+	return
+}
+
+// Groups returns the groups of the first source providing any.
+func (f Fallback) Groups() (groups []Group, err error) {
+	for _, source := range f {
+		if groups, err = source.Groups(); err == nil && len(groups) > 0 {
+			break
+		}
+	}
+	return
+}
+
+// Services returns the services of the first source providing any.
+func (f Fallback) Services() (services []Service, err error) {
+	for _, source := range f {
+		if services, err = source.Services(); err == nil && len(services) > 0 {
+			break
+		}
+	}
+	return
+}
+
+// Signals returns the signals of the first source providing any.
+func (f Fallback) Signals() (signals []Signal, err error) {
+	for _, source := range f {
+		if signals, err = source.Signals(); err == nil && len(signals) > 0 {
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package etc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/carapace-sh/carapace/pkg/ps"
+)
+
+func TestParsePasswd(t *testing.T) {
+	actual := parsePasswd("# comment\nroot:x:0:0:root:/root:/bin/bash\nuser:x:1000:1000:Full Name,,,:/home/user:/bin/zsh\ninvalid\n")
+	expected := []User{
+		{Name: "root", Uid: "0", Gid: "0", Description: "root", Home: "/root", Shell: "/bin/bash"},
+		{Name: "user", Uid: "1000", Gid: "1000", Description: "Full Name", Home: "/home/user", Shell: "/bin/zsh"},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestParseGroup(t *testing.T) {
+	actual := parseGroup("root:x:0:\nwheel:x:10:root,user\n")
+	expected := []Group{
+		{Name: "root", Gid: "0"},
+		{Name: "wheel", Gid: "10", Members: []string{"root", "user"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestParseServices(t *testing.T) {
+	actual := parseServices("# comment\nssh\t\t22/tcp\t\t\t# SSH Remote Login Protocol\nhttp 80/tcp www\ninvalid\nbroken x/tcp\n")
+	expected := []Service{
+		{Name: "ssh", Port: 22, Protocol: "tcp"},
+		{Name: "http", Port: 80, Protocol: "tcp", Aliases: []string{"www"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+type source struct {
+	users []User
+	err   error
+}
+
+func (s source) Users() ([]User, error)       { return s.users, s.err }
+func (s source) Groups() ([]Group, error)     { return nil, s.err }
+func (s source) Services() ([]Service, error) { return nil, s.err }
+func (s source) Signals() ([]Signal, error)   { return nil, s.err }
+
+func TestFallback(t *testing.T) {
+	users := []User{{Name: "fallback"}}
+	fallback := Fallback{
+		source{err: errors.New("missing")},
+		source{},
+		source{users: users},
+	}
+	if actual, err := fallback.Users(); err != nil || !reflect.DeepEqual(users, actual) {
+		t.Errorf("expected %#v [was: %#v, %v]", users, actual, err)
+	}
+
+	if _, err := (Fallback{source{}, source{err: errors.New("missing")}}).Users(); err == nil {
+		t.Error("expected error of last source")
+	}
+}
+
+func TestMinimalContainer(t *testing.T) {
+	s := Fallback{Files{Root: t.TempDir()}, Embedded{}}
+
+	if users, err := s.Users(); err != nil || len(users) == 0 {
+		t.Errorf("expected embedded users [was: %#v, %v]", users, err)
+	}
+
+	if services, err := s.Services(); err != nil || len(services) != len(wellKnownServices) {
+		t.Errorf("expected embedded services [was: %#v, %v]", services, err)
+	}
+
+	if signals, err := s.Signals(); err != nil || len(signals) != len(ps.Signals()) {
+		t.Errorf("expected embedded signals [was: %#v, %v]", signals, err)
+	}
+}
+
+func TestParseDsclList(t *testing.T) {
+	actual := parseDsclList("_amavisd                 AMaViS Daemon\nroot                     System Administrator\nnobody\n")
+	expected := map[string]string{
+		"_amavisd": "AMaViS Daemon",
+		"root":     "System Administrator",
+		"nobody":   "",
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
@@ -0,0 +1,125 @@
+package etc
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Files reads `/etc/passwd`, `/etc/group` and `/etc/services`.
+type Files struct {
+	Root string // alternative root directory (e.g. of a container image)
+}
+
+// Users parses `/etc/passwd`.
+func (f Files) Users() ([]User, error) {
+	content, err := os.ReadFile(f.Root + "/etc/passwd")
+	if err != nil {
+		return nil, err
+	}
+	return parsePasswd(string(content)), nil
+}
+
+// Groups parses `/etc/group`.
+func (f Files) Groups() ([]Group, error) {
+	content, err := os.ReadFile(f.Root + "/etc/group")
+	if err != nil {
+		return nil, err
+	}
+	return parseGroup(string(content)), nil
+}
+
+// Services parses `/etc/services`.
+func (f Files) Services() ([]Service, error) {
+	content, err := os.ReadFile(f.Root + "/etc/services")
+	if err != nil {
+		return nil, err
+	}
+	return parseServices(string(content)), nil
+}
+
+// Signals returns no signals as these aren't part of any file (the embedded ones are used instead).
+func (f Files) Signals() ([]Signal, error) {
+	return nil, nil
+}
+
+// records splits given content into colon separated fields skipping comments and blank lines.
+func records(content string, fields int) [][]string {
+	result := make([][]string, 0)
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if record := strings.Split(line, ":"); len(record) >= fields {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+// parsePasswd parses lines like `root:x:0:0:root:/root:/bin/bash`.
+func parsePasswd(content string) []User {
+	users := make([]User, 0)
+	for _, record := range records(content, 7) {
+		users = append(users, User{
+			Name:        record[0],
+			Uid:         record[2],
+			Gid:         record[3],
+			Description: strings.TrimRight(record[4], ","), // trailing empty GECOS subfields
+			Home:        record[5],
+			Shell:       record[6],
+		})
+	}
+	return users
+}
+
+// parseGroup parses lines like `wheel:x:10:root,user`.
+func parseGroup(content string) []Group {
+	groups := make([]Group, 0)
+	for _, record := range records(content, 4) {
+		group := Group{
+			Name: record[0],
+			Gid:  record[2],
+		}
+		if record[3] != "" {
+			group.Members = strings.Split(record[3], ",")
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// parseServices parses lines like `ssh 22/tcp # SSH Remote Login Protocol`.
+func parseServices(content string) []Service {
+	services := make([]Service, 0)
+	for _, line := range strings.Split(content, "\n") {
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		splitted := strings.SplitN(fields[1], "/", 2)
+		if len(splitted) != 2 {
+			continue
+		}
+		number, err := strconv.Atoi(splitted[0])
+		if err != nil {
+			continue
+		}
+
+		service := Service{
+			Name:     fields[0],
+			Port:     number,
+			Protocol: splitted[1],
+		}
+		if len(fields) > 2 {
+			service.Aliases = fields[2:]
+		}
+		services = append(services, service)
+	}
+	return services
+}
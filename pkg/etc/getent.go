@@ -0,0 +1,40 @@
+package etc
+
+import (
+	"os/exec"
+)
+
+// Getent queries the name service switch with `getent` (includes users and groups of e.g. LDAP).
+type Getent struct{}
+
+// Users returns the output of `getent passwd`.
+func (g Getent) Users() ([]User, error) {
+	output, err := exec.Command("getent", "passwd").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePasswd(string(output)), nil
+}
+
+// Groups returns the output of `getent group`.
+func (g Getent) Groups() ([]Group, error) {
+	output, err := exec.Command("getent", "group").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseGroup(string(output)), nil
+}
+
+// Services returns the output of `getent services`.
+func (g Getent) Services() ([]Service, error) {
+	output, err := exec.Command("getent", "services").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseServices(string(output)), nil
+}
+
+// Signals returns no signals as these aren't part of the name service switch.
+func (g Getent) Signals() ([]Signal, error) {
+	return nil, nil
+}
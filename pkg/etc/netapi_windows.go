@@ -0,0 +1,110 @@
+//go:build windows
+
+package etc
+
+import (
+	"errors"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modnetapi32           = syscall.NewLazyDLL("netapi32.dll")
+	procNetUserEnum       = modnetapi32.NewProc("NetUserEnum")
+	procNetLocalGroupEnum = modnetapi32.NewProc("NetLocalGroupEnum")
+)
+
+const (
+	filterNormalAccount = 0x0002
+	maxPreferredLength  = 0xFFFFFFFF
+	ufAccountDisable    = 0x0002
+)
+
+// userInfo20 is USER_INFO_20.
+type userInfo20 struct {
+	Name     *uint16
+	FullName *uint16
+	Comment  *uint16
+	Flags    uint32
+	UserId   uint32
+}
+
+// localGroupInfo1 is LOCALGROUP_INFO_1.
+type localGroupInfo1 struct {
+	Name    *uint16
+	Comment *uint16
+}
+
+// Netapi lists local users and groups with NetUserEnum and NetLocalGroupEnum.
+type Netapi struct{}
+
+// Users returns the local user accounts (Uid is the relative id).
+func (n Netapi) Users() ([]User, error) {
+	var buf *byte
+	var read, total uint32
+	if ret, _, _ := procNetUserEnum.Call(0, 20, filterNormalAccount, uintptr(unsafe.Pointer(&buf)), maxPreferredLength, uintptr(unsafe.Pointer(&read)), uintptr(unsafe.Pointer(&total)), 0); ret != 0 {
+		return nil, syscall.Errno(ret)
+	}
+	if buf == nil {
+		return nil, errors.New("NetUserEnum returned no buffer")
+	}
+	defer syscall.NetApiBufferFree(buf)
+
+	users := make([]User, 0, read)
+	for _, info := range (*[1 << 16]userInfo20)(unsafe.Pointer(buf))[:read:read] {
+		if info.Flags&ufAccountDisable != 0 {
+			continue
+		}
+		users = append(users, User{
+			Name:        utf16PtrToString(info.Name),
+			Uid:         strconv.FormatUint(uint64(info.UserId), 10),
+			Description: utf16PtrToString(info.FullName),
+		})
+	}
+	return users, nil
+}
+
+// Groups returns the local groups (described by their comment).
+func (n Netapi) Groups() ([]Group, error) {
+	var buf *byte
+	var read, total uint32
+	if ret, _, _ := procNetLocalGroupEnum.Call(0, 1, uintptr(unsafe.Pointer(&buf)), maxPreferredLength, uintptr(unsafe.Pointer(&read)), uintptr(unsafe.Pointer(&total)), 0); ret != 0 {
+		return nil, syscall.Errno(ret)
+	}
+	if buf == nil {
+		return nil, errors.New("NetLocalGroupEnum returned no buffer")
+	}
+	defer syscall.NetApiBufferFree(buf)
+
+	groups := make([]Group, 0, read)
+	for _, info := range (*[1 << 16]localGroupInfo1)(unsafe.Pointer(buf))[:read:read] {
+		groups = append(groups, Group{
+			Name:        utf16PtrToString(info.Name),
+			Description: utf16PtrToString(info.Comment),
+		})
+	}
+	return groups, nil
+}
+
+// Services returns no services (the embedded ones are used instead).
+func (n Netapi) Services() ([]Service, error) {
+	return nil, nil
+}
+
+// Signals returns no signals (the embedded ones are used instead).
+func (n Netapi) Signals() ([]Signal, error) {
+	return nil, nil
+}
+
+// utf16PtrToString converts given null terminated string.
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	s := make([]uint16, 0)
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; ptr = unsafe.Pointer(uintptr(ptr) + 2) {
+		s = append(s, *(*uint16)(ptr))
+	}
+	return syscall.UTF16ToString(s)
+}
@@ -0,0 +1,64 @@
+// Package execlocation provides locations like containers or remote hosts that commands are executed in
+package execlocation
+
+import (
+	"github.com/carapace-sh/carapace/pkg/shlex"
+)
+
+// Location wraps a command line so that it is executed elsewhere.
+type Location interface {
+	// Wrap returns the local command line executing given one at the location
+	// with additional environment variables (`key=value`) and working directory (empty for default).
+	Wrap(name string, args []string, env []string, dir string) (string, []string)
+}
+
+// Docker executes commands in a running container.
+//
+//	execlocation.Docker{Container: "web"} // docker exec -i web ...
+type Docker struct {
+	Container string
+	Runtime   string   // container runtime (defaults to `docker`, e.g. `podman`)
+	Options   []string // additional options (e.g. `--user`, `root`)
+}
+
+// Wrap executes the command using `docker exec`.
+func (d Docker) Wrap(name string, args []string, env []string, dir string) (string, []string) {
+	runtime := d.Runtime
+	if runtime == "" {
+		runtime = "docker"
+	}
+
+	wrapped := []string{"exec", "-i"}
+	for _, e := range env {
+		wrapped = append(wrapped, "--env", e)
+	}
+	if dir != "" {
+		wrapped = append(wrapped, "--workdir", dir)
+	}
+	wrapped = append(wrapped, d.Options...)
+	wrapped = append(wrapped, d.Container, name)
+	return runtime, append(wrapped, args...)
+}
+
+// Ssh executes commands on a remote host.
+//
+//	execlocation.Ssh{Host: "user@example.com"} // ssh user@example.com -- ...
+type Ssh struct {
+	Host    string
+	Options []string // additional options (e.g. `-p`, `2222`)
+}
+
+// Wrap executes the command using `ssh` (the remote command line is quoted for a POSIX shell).
+func (s Ssh) Wrap(name string, args []string, env []string, dir string) (string, []string) {
+	commandline := shlex.Join(append([]string{name}, args...))
+	if len(env) > 0 {
+		commandline = "env " + shlex.Join(env) + " " + commandline
+	}
+	if dir != "" {
+		commandline = "cd " + shlex.Quote(dir) + " && " + commandline
+	}
+
+	wrapped := append([]string{}, s.Options...)
+	wrapped = append(wrapped, "-o", "BatchMode=yes", s.Host, "--", commandline) // never prompt for passwords during completion
+	return "ssh", wrapped
+}
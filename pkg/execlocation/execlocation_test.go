@@ -0,0 +1,25 @@
+package execlocation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocker(t *testing.T) {
+	name, args := Docker{Container: "web"}.Wrap("ls", []string{"-a"}, []string{"KEY=value"}, "/tmp")
+	if expected := []string{"exec", "-i", "--env", "KEY=value", "--workdir", "/tmp", "web", "ls", "-a"}; name != "docker" || !reflect.DeepEqual(expected, args) {
+		t.Errorf("expected docker %#v [was: %v %#v]", expected, name, args)
+	}
+
+	name, args = Docker{Container: "web", Runtime: "podman", Options: []string{"--user", "root"}}.Wrap("ls", nil, nil, "")
+	if expected := []string{"exec", "-i", "--user", "root", "web", "ls"}; name != "podman" || !reflect.DeepEqual(expected, args) {
+		t.Errorf("expected podman %#v [was: %v %#v]", expected, name, args)
+	}
+}
+
+func TestSsh(t *testing.T) {
+	name, args := Ssh{Host: "example.com", Options: []string{"-p", "2222"}}.Wrap("ls", []string{"some dir"}, []string{"KEY=it's"}, "/tmp")
+	if expected := []string{"-p", "2222", "-o", "BatchMode=yes", "example.com", "--", `cd /tmp && env 'KEY=it'\''s' ls 'some dir'`}; name != "ssh" || !reflect.DeepEqual(expected, args) {
+		t.Errorf("expected ssh %#v [was: %v %#v]", expected, name, args)
+	}
+}
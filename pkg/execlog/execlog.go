@@ -1,8 +1,9 @@
 package execlog
 
 import (
-	shlex "github.com/carapace-sh/carapace-shlex"
 	"github.com/carapace-sh/carapace/internal/log"
+	"github.com/carapace-sh/carapace/internal/profile"
+	"github.com/carapace-sh/carapace/pkg/shlex"
 	"github.com/carapace-sh/carapace/third_party/golang.org/x/sys/execabs"
 )
 
@@ -19,22 +20,25 @@ func Command(name string, arg ...string) *Cmd {
 }
 
 func (c *Cmd) CombinedOutput() ([]byte, error) {
-	log.LOG.Printf("executing %#v", shlex.Join(c.Args))
+	log.Default.Infof("executing %#v", shlex.Join(c.Args))
+	defer profile.Start(profile.KindExec, shlex.Join(c.Args))()
 	return c.Cmd.CombinedOutput()
 }
 
 func (c *Cmd) Output() ([]byte, error) {
-	log.LOG.Printf("executing %#v", shlex.Join(c.Args))
+	log.Default.Infof("executing %#v", shlex.Join(c.Args))
+	defer profile.Start(profile.KindExec, shlex.Join(c.Args))()
 	return c.Cmd.Output()
 }
 
 func (c *Cmd) Run() error {
-	log.LOG.Printf("executing %#v", shlex.Join(c.Args))
+	log.Default.Infof("executing %#v", shlex.Join(c.Args))
+	defer profile.Start(profile.KindExec, shlex.Join(c.Args))()
 	return c.Cmd.Run()
 }
 
 func (c *Cmd) Start() error {
-	log.LOG.Printf("executing %#v", shlex.Join(c.Args))
+	log.Default.Infof("executing %#v", shlex.Join(c.Args))
 	return c.Cmd.Start()
 }
 
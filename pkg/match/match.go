@@ -15,7 +15,7 @@ const (
 
 func (m Match) Equal(s, t string) bool {
 	if m == CASE_INSENSITIVE {
-		strings.EqualFold(s, t)
+		return strings.EqualFold(s, t)
 	}
 	return s == t
 
@@ -35,23 +35,25 @@ func (m Match) TrimPrefix(s, prefix string) string {
 	return s
 }
 
-var match = CASE_SENSITIVE
-
-func init() {
+// Current returns the match mode (evaluated lazily as it might be set by a runtime setting).
+// It reads the environment on each call, so callers matching many values should read it once beforehand.
+func Current() Match {
 	switch os.Getenv("CARAPACE_MATCH") {
 	case "CASE_INSENSITIVE", strconv.Itoa(int(CASE_INSENSITIVE)):
-		match = CASE_INSENSITIVE
+		return CASE_INSENSITIVE
+	default:
+		return CASE_SENSITIVE
 	}
 }
 
 func Equal(s, t string) bool {
-	return match.Equal(s, t)
+	return Current().Equal(s, t)
 }
 
 func HasPrefix(s, prefix string) bool {
-	return match.HasPrefix(s, prefix)
+	return Current().HasPrefix(s, prefix)
 }
 
 func TrimPrefix(s, prefix string) string {
-	return match.TrimPrefix(s, prefix)
+	return Current().TrimPrefix(s, prefix)
 }
@@ -0,0 +1,178 @@
+// Package mount provides access to mount points, block devices and filesystem types
+package mount
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Mount is a mounted filesystem.
+type Mount struct {
+	Device  string // source like `/dev/sda1` or `tmpfs`
+	Path    string
+	Type    string
+	Options []string
+	Size    uint64 // total bytes (0 if unavailable)
+	Free    uint64 // bytes available to unprivileged users
+}
+
+// Device is a block device.
+type Device struct {
+	Path string // like `/dev/sda1`
+	Size uint64 // total bytes (0 if unavailable)
+}
+
+// Filesystem is a filesystem type supported by the kernel.
+type Filesystem struct {
+	Name    string
+	Virtual bool // not backed by a block device (e.g. `proc`)
+}
+
+// Mounts returns the mounted filesystems.
+func Mounts() ([]Mount, error) {
+	return mounts()
+}
+
+// Devices returns the block devices.
+func Devices() ([]Device, error) {
+	return devices()
+}
+
+// Filesystems returns the supported filesystem types.
+func Filesystems() ([]Filesystem, error) {
+	return filesystems()
+}
+
+// isDevice returns true if given mount source is a device (as opposed to e.g. `tmpfs` or `host:/export`).
+func isDevice(source string) bool {
+	return strings.HasPrefix(source, "/dev/")
+}
+
+var octalEscape = regexp.MustCompile(`\\[0-7]{3}`)
+
+// unescape replaces octal escapes like `\040` for space used in `/proc/self/mounts`.
+func unescape(s string) string {
+	return octalEscape.ReplaceAllStringFunc(s, func(escape string) string {
+		i, _ := strconv.ParseUint(escape[1:], 8, 8)
+		return string(rune(i))
+	})
+}
+
+// parseProcMounts parses the fstab format of `/proc/self/mounts`.
+//
+//	/dev/sda1 / ext4 rw,relatime 0 0
+func parseProcMounts(s string) []Mount {
+	mounts := make([]Mount, 0)
+	for _, line := range strings.Split(s, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 4 {
+			mounts = append(mounts, Mount{
+				Device:  unescape(fields[0]),
+				Path:    unescape(fields[1]),
+				Type:    fields[2],
+				Options: strings.Split(fields[3], ","),
+			})
+		}
+	}
+	return mounts
+}
+
+var mountOutput = regexp.MustCompile(`^(.+?) on (.+?)(?: type (\S+))? \((.*)\)$`)
+
+// parseMountOutput parses the output of `mount` on linux and BSD.
+//
+//	/dev/sda1 on / type ext4 (rw,relatime)
+//	/dev/disk1s1 on / (apfs, local, journaled)
+func parseMountOutput(s string) []Mount {
+	mounts := make([]Mount, 0)
+	for _, line := range strings.Split(s, "\n") {
+		matches := mountOutput.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		options := make([]string, 0)
+		for _, option := range strings.Split(matches[4], ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				options = append(options, option)
+			}
+		}
+
+		m := Mount{
+			Device:  matches[1],
+			Path:    matches[2],
+			Type:    matches[3],
+			Options: options,
+		}
+		if m.Type == "" && len(options) > 0 { // BSD lists the type as first option
+			m.Type, m.Options = options[0], options[1:]
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
+
+// parsePartitions parses `/proc/partitions`.
+//
+//	major minor  #blocks  name
+//
+//	   8        1  523264 sda1
+func parsePartitions(s string) []Device {
+	devices := make([]Device, 0)
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		if blocks, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			devices = append(devices, Device{
+				Path: "/dev/" + fields[3],
+				Size: blocks * 1024,
+			})
+		}
+	}
+	return devices
+}
+
+// parseFilesystems parses `/proc/filesystems`.
+//
+//	nodev	proc
+//		ext4
+func parseFilesystems(s string) []Filesystem {
+	filesystems := make([]Filesystem, 0)
+	for _, line := range strings.Split(s, "\n") {
+		switch fields := strings.Fields(line); len(fields) {
+		case 1:
+			filesystems = append(filesystems, Filesystem{Name: fields[0]})
+		case 2:
+			filesystems = append(filesystems, Filesystem{Name: fields[1], Virtual: fields[0] == "nodev"})
+		}
+	}
+	return filesystems
+}
+
+// devicesFrom returns the unique devices of given mounts.
+func devicesFrom(mounts []Mount) []Device {
+	seen := make(map[string]bool)
+	devices := make([]Device, 0)
+	for _, m := range mounts {
+		if isDevice(m.Device) && !seen[m.Device] {
+			seen[m.Device] = true
+			devices = append(devices, Device{Path: m.Device, Size: m.Size})
+		}
+	}
+	return devices
+}
+
+// filesystemsFrom returns the unique filesystem types of given mounts.
+func filesystemsFrom(mounts []Mount) []Filesystem {
+	seen := make(map[string]bool)
+	filesystems := make([]Filesystem, 0)
+	for _, m := range mounts {
+		if m.Type != "" && !seen[m.Type] {
+			seen[m.Type] = true
+			filesystems = append(filesystems, Filesystem{Name: m.Type, Virtual: !isDevice(m.Device)})
+		}
+	}
+	return filesystems
+}
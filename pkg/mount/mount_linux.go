@@ -0,0 +1,48 @@
+//go:build linux
+
+package mount
+
+import (
+	"os"
+	"syscall"
+)
+
+// mounts reads `/proc/self/mounts` and the usage of mounted devices.
+func mounts() ([]Mount, error) {
+	content, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		return nil, err
+	}
+
+	mounts := parseProcMounts(string(content))
+	for index, m := range mounts {
+		if !isDevice(m.Device) {
+			continue // skip network and virtual filesystems which might block
+		}
+
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(m.Path, &stat); err == nil {
+			mounts[index].Size = stat.Blocks * uint64(stat.Bsize)
+			mounts[index].Free = stat.Bavail * uint64(stat.Bsize)
+		}
+	}
+	return mounts, nil
+}
+
+// devices reads `/proc/partitions`.
+func devices() ([]Device, error) {
+	content, err := os.ReadFile("/proc/partitions")
+	if err != nil {
+		return nil, err
+	}
+	return parsePartitions(string(content)), nil
+}
+
+// filesystems reads `/proc/filesystems`.
+func filesystems() ([]Filesystem, error) {
+	content, err := os.ReadFile("/proc/filesystems")
+	if err != nil {
+		return nil, err
+	}
+	return parseFilesystems(string(content)), nil
+}
@@ -0,0 +1,34 @@
+//go:build !linux
+
+package mount
+
+import (
+	"os/exec"
+)
+
+// mounts parses the output of `mount` (usage is not yet supported on this platform).
+func mounts() ([]Mount, error) {
+	output, err := exec.Command("mount").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseMountOutput(string(output)), nil
+}
+
+// devices returns the mounted devices as unmounted ones are not yet supported on this platform.
+func devices() ([]Device, error) {
+	mounts, err := mounts()
+	if err != nil {
+		return nil, err
+	}
+	return devicesFrom(mounts), nil
+}
+
+// filesystems returns the types of mounted filesystems as supported ones are not yet listed on this platform.
+func filesystems() ([]Filesystem, error) {
+	mounts, err := mounts()
+	if err != nil {
+		return nil, err
+	}
+	return filesystemsFrom(mounts), nil
+}
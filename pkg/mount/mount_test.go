@@ -0,0 +1,66 @@
+package mount
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProcMounts(t *testing.T) {
+	actual := parseProcMounts("proc /proc proc rw,relatime 0 0\n/dev/sdb1 /mnt/usb\\040stick vfat rw 0 0\n")
+	expected := []Mount{
+		{Device: "proc", Path: "/proc", Type: "proc", Options: []string{"rw", "relatime"}},
+		{Device: "/dev/sdb1", Path: "/mnt/usb stick", Type: "vfat", Options: []string{"rw"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestParseMountOutput(t *testing.T) {
+	actual := parseMountOutput("/dev/sda1 on / type ext4 (rw,relatime)\n/dev/disk1s1 on /Volumes/Macintosh HD (apfs, local, journaled)\n")
+	expected := []Mount{
+		{Device: "/dev/sda1", Path: "/", Type: "ext4", Options: []string{"rw", "relatime"}},
+		{Device: "/dev/disk1s1", Path: "/Volumes/Macintosh HD", Type: "apfs", Options: []string{"local", "journaled"}},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestParsePartitions(t *testing.T) {
+	actual := parsePartitions("major minor  #blocks  name\n\n   8        0  1024 sda\n   8        1  512 sda1\n")
+	expected := []Device{
+		{Path: "/dev/sda", Size: 1024 * 1024},
+		{Path: "/dev/sda1", Size: 512 * 1024},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestParseFilesystems(t *testing.T) {
+	actual := parseFilesystems("nodev\tproc\n\text4\n")
+	expected := []Filesystem{
+		{Name: "proc", Virtual: true},
+		{Name: "ext4"},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	mounts := []Mount{
+		{Device: "/dev/sda1", Path: "/", Type: "ext4"},
+		{Device: "/dev/sda1", Path: "/home", Type: "ext4"},
+		{Device: "tmpfs", Path: "/tmp", Type: "tmpfs"},
+	}
+
+	if expected, actual := []Device{{Path: "/dev/sda1"}}, devicesFrom(mounts); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+
+	if expected, actual := []Filesystem{{Name: "ext4"}, {Name: "tmpfs", Virtual: true}}, filesystemsFrom(mounts); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %#v [was: %#v]", expected, actual)
+	}
+}
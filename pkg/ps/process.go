@@ -0,0 +1,32 @@
+package ps
+
+import (
+	"github.com/carapace-sh/carapace/third_party/github.com/mitchellh/go-ps"
+)
+
+// Process is a running process.
+type Process struct {
+	Pid        int
+	Executable string
+	Cmdline    string // full command line (empty if unavailable)
+	State      string // single character state like `R` (running), `S` (sleeping), `T` (stopped) or `Z` (zombie) (empty if unavailable)
+}
+
+// Processes returns the currently running processes.
+func Processes() ([]Process, error) {
+	processes, err := ps.Processes()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Process, 0, len(processes))
+	for _, p := range processes {
+		process := Process{
+			Pid:        p.Pid(),
+			Executable: p.Executable(),
+		}
+		process.Cmdline, process.State = details(p.Pid())
+		result = append(result, process)
+	}
+	return result, nil
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package ps
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// details reads command line and state from `/proc`.
+func details(pid int) (cmdline, state string) {
+	if content, err := os.ReadFile(fmt.Sprintf("/proc/%v/cmdline", pid)); err == nil {
+		cmdline = strings.TrimSpace(strings.ReplaceAll(string(content), "\x00", " "))
+	}
+
+	if content, err := os.ReadFile(fmt.Sprintf("/proc/%v/stat", pid)); err == nil {
+		// state follows the executable name in parentheses which might contain spaces
+		if index := strings.LastIndex(string(content), ")"); index >= 0 {
+			if fields := strings.Fields(string(content)[index+1:]); len(fields) > 0 {
+				state = fields[0]
+			}
+		}
+	}
+	return
+}
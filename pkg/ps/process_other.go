@@ -0,0 +1,8 @@
+//go:build !linux
+
+package ps
+
+// details is not yet supported on this platform.
+func details(pid int) (cmdline, state string) {
+	return "", ""
+}
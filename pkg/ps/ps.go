@@ -1,4 +1,4 @@
-// Package ps provides shell determination by process name
+// Package ps provides shell determination by process name and access to processes and signals
 package ps
 
 import (
@@ -32,6 +32,10 @@ func DetermineShell() string {
 			return "fish"
 		case "ion":
 			return "ion"
+		case "ksh":
+			return "ksh"
+		case "ksh93":
+			return "ksh"
 		case "nu":
 			return "nushell"
 		case "oil":
@@ -46,6 +50,8 @@ func DetermineShell() string {
 			return "tcsh"
 		case "xonsh":
 			return "xonsh"
+		case "ysh":
+			return "ysh"
 		case "zsh":
 			return "zsh"
 		default:
@@ -0,0 +1,19 @@
+package ps
+
+import "sort"
+
+// Signal is a named signal of the operating system.
+type Signal struct {
+	Name   string // name without `SIG` prefix
+	Number int
+}
+
+// Signals returns the signals of the operating system.
+func Signals() []Signal {
+	return signals
+}
+
+func sortSignals(signals []Signal) []Signal {
+	sort.Slice(signals, func(i, j int) bool { return signals[i].Number < signals[j].Number })
+	return signals
+}
@@ -0,0 +1,9 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package ps
+
+import "syscall"
+
+var platformSignals = []Signal{
+	{"EMT", int(syscall.SIGEMT)}, {"INFO", int(syscall.SIGINFO)},
+}
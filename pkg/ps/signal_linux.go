@@ -0,0 +1,9 @@
+//go:build linux
+
+package ps
+
+import "syscall"
+
+var platformSignals = []Signal{
+	{"PWR", int(syscall.SIGPWR)},
+}
@@ -0,0 +1,5 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+
+package ps
+
+var signals = []Signal{}
@@ -0,0 +1,9 @@
+//go:build aix || solaris
+
+package ps
+
+import "syscall"
+
+var platformSignals = []Signal{
+	{"EMT", int(syscall.SIGEMT)}, {"PWR", int(syscall.SIGPWR)},
+}
@@ -0,0 +1,16 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package ps
+
+import "syscall"
+
+var signals = sortSignals(append([]Signal{
+	{"HUP", int(syscall.SIGHUP)}, {"INT", int(syscall.SIGINT)}, {"QUIT", int(syscall.SIGQUIT)}, {"ILL", int(syscall.SIGILL)},
+	{"TRAP", int(syscall.SIGTRAP)}, {"ABRT", int(syscall.SIGABRT)}, {"BUS", int(syscall.SIGBUS)}, {"FPE", int(syscall.SIGFPE)},
+	{"KILL", int(syscall.SIGKILL)}, {"USR1", int(syscall.SIGUSR1)}, {"SEGV", int(syscall.SIGSEGV)}, {"USR2", int(syscall.SIGUSR2)},
+	{"PIPE", int(syscall.SIGPIPE)}, {"ALRM", int(syscall.SIGALRM)}, {"TERM", int(syscall.SIGTERM)}, {"CHLD", int(syscall.SIGCHLD)},
+	{"CONT", int(syscall.SIGCONT)}, {"STOP", int(syscall.SIGSTOP)}, {"TSTP", int(syscall.SIGTSTP)}, {"TTIN", int(syscall.SIGTTIN)},
+	{"TTOU", int(syscall.SIGTTOU)}, {"URG", int(syscall.SIGURG)}, {"XCPU", int(syscall.SIGXCPU)}, {"XFSZ", int(syscall.SIGXFSZ)},
+	{"VTALRM", int(syscall.SIGVTALRM)}, {"PROF", int(syscall.SIGPROF)}, {"WINCH", int(syscall.SIGWINCH)}, {"IO", int(syscall.SIGIO)},
+	{"SYS", int(syscall.SIGSYS)},
+}, platformSignals...))
@@ -0,0 +1,13 @@
+//go:build windows
+
+package ps
+
+import "syscall"
+
+// signals only contains the ones defined by syscall (most of these can't be sent on windows).
+var signals = sortSignals([]Signal{
+	{"HUP", int(syscall.SIGHUP)}, {"INT", int(syscall.SIGINT)}, {"QUIT", int(syscall.SIGQUIT)}, {"ILL", int(syscall.SIGILL)},
+	{"TRAP", int(syscall.SIGTRAP)}, {"ABRT", int(syscall.SIGABRT)}, {"BUS", int(syscall.SIGBUS)}, {"FPE", int(syscall.SIGFPE)},
+	{"KILL", int(syscall.SIGKILL)}, {"SEGV", int(syscall.SIGSEGV)}, {"PIPE", int(syscall.SIGPIPE)}, {"ALRM", int(syscall.SIGALRM)},
+	{"TERM", int(syscall.SIGTERM)},
+})
@@ -0,0 +1,159 @@
+// Package quote provides the escaping of values used by the shell serializers.
+//
+// Custom integrations can use it to insert values the same way as the completion scripts do.
+//
+//	quote.Bash("with space")   // "with space"
+//	quote.Zsh("with space")    // with\ space
+//	quote.Fish("with space")   // 'with space'
+package quote
+
+import (
+	"fmt"
+	"strings"
+)
+
+var bashReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	`$`, `\$`,
+	"`", "\\`",
+)
+
+// Bash quotes given value with double quotes if it contains characters special to bash.
+// Wordbreak characters like `:`, `=` and `@` are left as is since bash only replaces
+// the last segment of the current word and a closing quote would end up in the middle
+// of values like `host:port`.
+func Bash(s string) string {
+	if strings.ContainsAny(s, " \t\r\n`"+`"'[]{}()<>;|$&*#\`) {
+		return fmt.Sprintf(`"%v"`, bashReplacer.Replace(s))
+	}
+	return s
+}
+
+// BashWithin escapes given value for insertion after an open quote (`"` or `'`).
+// The quote itself is closed by readline.
+func BashWithin(s, openQuote string) string {
+	switch openQuote {
+	case `"`:
+		return bashReplacer.Replace(s)
+	case `'`:
+		return strings.ReplaceAll(s, `'`, `'\''`)
+	default:
+		return Bash(s)
+	}
+}
+
+var zshReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`&`, `\&`,
+	`<`, `\<`,
+	`>`, `\>`,
+	"`", "\\`",
+	`'`, `\'`,
+	`"`, `\"`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`|`, `\|`,
+	`?`, `\?`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`;`, `\;`,
+	` `, `\ `,
+	`[`, `\[`,
+	`]`, `\]`,
+	`*`, `\*`,
+	`~`, `\~`,
+)
+
+// Zsh escapes characters special to zsh with a backslash.
+func Zsh(s string) string {
+	return zshReplacer.Replace(s)
+}
+
+// ZshWithin escapes given value for insertion after an open quote (`"` or `'`).
+// The quote itself is closed by zsh.
+func ZshWithin(s, openQuote string) string {
+	switch openQuote {
+	case `"`:
+		return bashReplacer.Replace(s) // same characters are special within double quotes
+	case `'`:
+		return strings.ReplaceAll(s, `'`, `'\''`)
+	default:
+		return Zsh(s)
+	}
+}
+
+var fishReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+)
+
+// Fish quotes given value with single quotes if it contains characters special to fish.
+// Note that the fish completion script passes values unquoted as fish escapes them on its own.
+func Fish(s string) string {
+	if strings.ContainsAny(s, " \t\r\n`"+`"'[]{}()<>;|$&*?~#\%`) {
+		return fmt.Sprintf(`'%v'`, fishReplacer.Replace(s))
+	}
+	return s
+}
+
+var nushellReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+)
+
+// Nushell quotes given value with double quotes if it contains characters special to nushell.
+// A leading `~` is kept outside of the quotes so that it is still expanded.
+func Nushell(s string) string {
+	if !strings.ContainsAny(s, ` {}()[]<>$&"'|;#\`+"`") {
+		return s
+	}
+	if strings.HasPrefix(s, "~") {
+		return fmt.Sprintf(`~"%v"`, nushellReplacer.Replace(s[1:]))
+	}
+	return fmt.Sprintf(`"%v"`, nushellReplacer.Replace(s))
+}
+
+// NushellWithin escapes given value for insertion after an open double quote.
+func NushellWithin(s string) string {
+	return nushellReplacer.Replace(s)
+}
+
+// Powershell quotes given value with single quotes if it contains characters special to powershell.
+// Backslash is no escape character (Windows path separator).
+func Powershell(s string) string {
+	if strings.ContainsAny(s, ` {}()[]*$?"'|<>&(),;#`+"`") {
+		return fmt.Sprintf("'%v'", strings.ReplaceAll(s, "'", "''"))
+	}
+	return s
+}
+
+var tcshReplacer = strings.NewReplacer(
+	`&`, `\&`,
+	`<`, `\<`,
+	`>`, `\>`,
+	"`", "\\`",
+	`'`, `\'`,
+	`"`, `\"`,
+	`{`, ``, // TODO seems escaping is not working
+	`}`, ``, // TODO seems escaping is not working
+	`$`, `\$`,
+	`#`, `\#`,
+	`|`, `\|`,
+	`?`, `\?`,
+	`(`, `\(`,
+	`)`, `\)`,
+	`;`, `\;`,
+	` `, `\ `,
+	`[`, `\[`,
+	`]`, `\]`,
+	`*`, `\*`,
+	`\`, `\\`,
+)
+
+// Tcsh escapes characters special to tcsh with a backslash (braces are removed).
+func Tcsh(s string) string {
+	return tcshReplacer.Replace(s)
+}
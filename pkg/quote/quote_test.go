@@ -0,0 +1,162 @@
+package quote
+
+import "testing"
+
+func testQuote(t *testing.T, name string, f func(s string) string, cases map[string]string) {
+	t.Helper()
+	for s, expected := range cases {
+		if actual := f(s); actual != expected {
+			t.Errorf("%v(%#v): expected %#v [was: %#v]", name, s, expected, actual)
+		}
+	}
+}
+
+func TestBash(t *testing.T) {
+	testQuote(t, "Bash", Bash, map[string]string{
+		"":              "",
+		"plain":         "plain",
+		"host:port":     "host:port",
+		"user@host":     "user@host",
+		"--flag=value":  "--flag=value",
+		"with space":    `"with space"`,
+		"tab\tchar":     "\"tab\tchar\"",
+		`double"quote`:  `"double\"quote"`,
+		"single'quote":  `"single'quote"`,
+		"$variable":     `"\$variable"`,
+		"back`tick":     "\"back\\`tick\"",
+		`back\slash`:    `"back\\slash"`,
+		"glob*":         `"glob*"`,
+		"(paren)":       `"(paren)"`,
+		"[bracket]":     `"[bracket]"`,
+		"{brace}":       `"{brace}"`,
+		"a;b|c&d":       `"a;b|c&d"`,
+		"<redirect>":    `"<redirect>"`,
+		"#comment":      `"#comment"`,
+		"host:with spc": `"host:with spc"`,
+	})
+}
+
+func TestBashWithin(t *testing.T) {
+	testQuote(t, "BashWithin(\")", func(s string) string { return BashWithin(s, `"`) }, map[string]string{
+		"with space":   "with space",
+		`double"quote`: `double\"quote`,
+		"single'quote": "single'quote",
+		"$variable":    `\$variable`,
+		"back`tick":    "back\\`tick",
+		`back\slash`:   `back\\slash`,
+	})
+	testQuote(t, "BashWithin(')", func(s string) string { return BashWithin(s, `'`) }, map[string]string{
+		"with space":   "with space",
+		`double"quote`: `double"quote`,
+		"single'quote": `single'\''quote`,
+		"$variable":    "$variable",
+	})
+	testQuote(t, "BashWithin()", func(s string) string { return BashWithin(s, "") }, map[string]string{
+		"plain":      "plain",
+		"with space": `"with space"`,
+	})
+}
+
+func TestZsh(t *testing.T) {
+	testQuote(t, "Zsh", Zsh, map[string]string{
+		"":             "",
+		"plain":        "plain",
+		"host:port":    "host:port",
+		"with space":   `with\ space`,
+		`double"quote`: `double\"quote`,
+		"single'quote": `single\'quote`,
+		"$variable":    `\$variable`,
+		"back`tick":    "back\\`tick",
+		`back\slash`:   `back\\slash`,
+		"~home":        `\~home`,
+		"glob*?":       `glob\*\?`,
+		"(paren)":      `\(paren\)`,
+		"[bracket]":    `\[bracket\]`,
+		"{brace}":      `\{brace\}`,
+		"a;b|c&d":      `a\;b\|c\&d`,
+		"<redirect>":   `\<redirect\>`,
+		"#comment":     `\#comment`,
+	})
+}
+
+func TestZshWithin(t *testing.T) {
+	testQuote(t, "ZshWithin(\")", func(s string) string { return ZshWithin(s, `"`) }, map[string]string{
+		"with space":   "with space",
+		`double"quote`: `double\"quote`,
+		"$variable":    `\$variable`,
+	})
+	testQuote(t, "ZshWithin(')", func(s string) string { return ZshWithin(s, `'`) }, map[string]string{
+		"with space":   "with space",
+		"single'quote": `single'\''quote`,
+	})
+	testQuote(t, "ZshWithin()", func(s string) string { return ZshWithin(s, "") }, map[string]string{
+		"with space": `with\ space`,
+	})
+}
+
+func TestFish(t *testing.T) {
+	testQuote(t, "Fish", Fish, map[string]string{
+		"":             "",
+		"plain":        "plain",
+		"host:port":    "host:port",
+		"with space":   `'with space'`,
+		`double"quote`: `'double"quote'`,
+		"single'quote": `'single\'quote'`,
+		"$variable":    `'$variable'`,
+		`back\slash`:   `'back\\slash'`,
+		"~home":        `'~home'`,
+		"glob*?":       `'glob*?'`,
+		"{brace}":      `'{brace}'`,
+		"%percent":     `'%percent'`,
+	})
+}
+
+func TestNushell(t *testing.T) {
+	testQuote(t, "Nushell", Nushell, map[string]string{
+		"":             "",
+		"plain":        "plain",
+		"host:port":    "host:port",
+		"with space":   `"with space"`,
+		`double"quote`: `"double\"quote"`,
+		"single'quote": `"single'quote"`,
+		`back\slash`:   `"back\\slash"`,
+		"~/with space": `~"/with space"`,
+		"~plain":       "~plain",
+		"$variable":    `"$variable"`,
+		"(paren)":      `"(paren)"`,
+	})
+	testQuote(t, "NushellWithin", NushellWithin, map[string]string{
+		"with space":   "with space",
+		`double"quote`: `double\"quote`,
+		`back\slash`:   `back\\slash`,
+	})
+}
+
+func TestPowershell(t *testing.T) {
+	testQuote(t, "Powershell", Powershell, map[string]string{
+		"":             "",
+		"plain":        "plain",
+		`C:\Windows`:   `C:\Windows`,
+		"with space":   `'with space'`,
+		"single'quote": `'single''quote'`,
+		`double"quote`: `'double"quote'`,
+		"$variable":    `'$variable'`,
+		"a,b":          `'a,b'`,
+		"back`tick":    "'back`tick'",
+		"glob*?":       `'glob*?'`,
+	})
+}
+
+func TestTcsh(t *testing.T) {
+	testQuote(t, "Tcsh", Tcsh, map[string]string{
+		"":             "",
+		"plain":        "plain",
+		"with space":   `with\ space`,
+		`double"quote`: `double\"quote`,
+		"single'quote": `single\'quote`,
+		"$variable":    `\$variable`,
+		`back\slash`:   `back\\slash`,
+		"{brace}":      "brace",
+		"glob*?":       `glob\*\?`,
+	})
+}
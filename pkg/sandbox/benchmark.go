@@ -0,0 +1,28 @@
+package sandbox
+
+import (
+	"testing"
+
+	"github.com/carapace-sh/carapace"
+	"github.com/spf13/cobra"
+)
+
+// Benchmark measures the completion of given arguments for the command generated by given function.
+// The command is recreated for each iteration (outside of the measurement) as cobra keeps the state of parsed flags.
+//
+//	func BenchmarkRemote(b *testing.B) {
+//		sandbox.Benchmark(b, newRootCmd, "remote", "")
+//	}
+//
+// Set `CARAPACE_PROFILE=1` and `CARAPACE_LOG=info` to log which callback, command or serialization is slow.
+func Benchmark(b *testing.B, cmdF func() *cobra.Command, args ...string) {
+	b.ReportAllocs()
+	context := carapace.NewContext(args...)
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cmd := cmdF()
+		b.StartTimer()
+
+		carapace.ActionExecute(cmd).Invoke(context)
+	}
+}
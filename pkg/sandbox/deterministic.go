@@ -0,0 +1,47 @@
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/carapace-sh/carapace/internal/shell"
+)
+
+// deterministicRuns is the amount of invocations compared for each shell.
+const deterministicRuns = 5
+
+// Deterministic verifies that given executable produces byte-identical output for every shell
+// when invoked repeatedly with the same arguments (e.g. no random map iteration order).
+// Each invocation is a separate process so that map iteration order differs between them.
+//
+//	sandbox.Deterministic(t, "/tmp/example", "example", "action", "--values", "")
+func Deterministic(t *testing.T, executable string, args ...string) {
+	executable, err := filepath.Abs(executable)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, name := range shell.Serializers() {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			var first string
+			for i := 0; i < deterministicRuns; i++ {
+				cmd := exec.Command(executable, append([]string{"_carapace", name}, args...)...)
+				cmd.Env = append(os.Environ(), "CARAPACE_RECORD=") // don't record the invocations
+				output, err := cmd.Output()
+				if err != nil {
+					t.Fatal(err.Error())
+				}
+
+				switch {
+				case i == 0:
+					first = string(output)
+				case string(output) != first:
+					t.Fatalf("output differs on run %v:\n%v\n[was: %v]", i+1, first, string(output))
+				}
+			}
+		})
+	}
+}
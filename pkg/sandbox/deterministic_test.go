@@ -0,0 +1,20 @@
+package sandbox
+
+import "testing"
+
+func TestDeterministic(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping deterministic test in short mode")
+	}
+
+	executable := exampleExecutable(t)
+
+	for _, args := range [][]string{
+		{"example", ""},
+		{"example", "action", "--"},
+		{"example", "action", "--values", ""},
+		{"example", "modifier", "--uniquelist", "one,"},
+	} {
+		Deterministic(t, executable, args...)
+	}
+}
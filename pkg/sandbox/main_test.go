@@ -0,0 +1,42 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+var example struct {
+	once       sync.Once
+	dir        string
+	executable string
+	err        error
+}
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if example.dir != "" {
+		os.RemoveAll(example.dir)
+	}
+	os.Exit(code)
+}
+
+// exampleExecutable builds the example once for all tests.
+func exampleExecutable(t *testing.T) string {
+	example.once.Do(func() {
+		if example.dir, example.err = os.MkdirTemp("", "carapace-sandbox"); example.err != nil {
+			return
+		}
+		example.executable = filepath.Join(example.dir, "example")
+		if output, err := exec.Command("go", "build", "-o", example.executable, "../../example").CombinedOutput(); err != nil {
+			example.err = fmt.Errorf("%v: %s", err.Error(), output)
+		}
+	})
+	if example.err != nil {
+		t.Fatal(example.err.Error())
+	}
+	return example.executable
+}
@@ -0,0 +1,85 @@
+package sandbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shlex "github.com/carapace-sh/carapace-shlex"
+	"github.com/carapace-sh/carapace/internal/record"
+)
+
+// Replay re-executes the invocations of a transcript recorded with `CARAPACE_RECORD`
+// and reports the ones whose output differs from the recording.
+//
+//	sandbox.Replay(t, "testdata/issue1234.jsonl", "/tmp/example")
+func Replay(t *testing.T, transcript, executable string) {
+	entries, err := readTranscript(transcript)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	for _, entry := range entries {
+		entry := entry
+		t.Run(entry.Shell+":"+entry.Line, func(t *testing.T) {
+			output, err := replay(executable, entry)
+			if err != nil {
+				t.Fatal(err.Error())
+			}
+
+			if hash := record.Hash(output); hash != entry.Hash {
+				t.Errorf("output differs from recording at %v (expected hash %v [was: %v]):\n%v", entry.Time, entry.Hash, hash, output)
+			}
+		})
+	}
+}
+
+func readTranscript(transcript string) ([]record.Entry, error) {
+	file, err := os.Open(transcript)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make([]record.Entry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry record.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// replay invokes given executable with the recorded command line and returns the serialized output.
+func replay(executable string, entry record.Entry) (string, error) {
+	tokens, err := shlex.Split(entry.Line)
+	if err != nil {
+		return "", err
+	}
+
+	executable, err = filepath.Abs(executable)
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"_carapace", entry.Shell}, tokens.CurrentPipeline().Words().Strings()...)
+	cmd := exec.Command(executable, args...)
+	cmd.Env = append(os.Environ(), "CARAPACE_RECORD=") // don't record the replay itself
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil // added by `_carapace`
+}
@@ -0,0 +1,45 @@
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/carapace-sh/carapace/internal/record"
+)
+
+func TestReplay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping replay test in short mode")
+	}
+
+	executable := exampleExecutable(t)
+
+	transcript := filepath.Join(t.TempDir(), "transcript.jsonl")
+	for _, args := range [][]string{
+		{"export", "example", "action", "--values", ""},
+		{"elvish", "example", "action", "--values", "fi"},
+	} {
+		cmd := exec.Command(executable, append([]string{"_carapace"}, args...)...)
+		cmd.Env = append(os.Environ(), "CARAPACE_RECORD="+transcript)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err.Error(), output)
+		}
+	}
+
+	Replay(t, transcript, executable)
+
+	entries, err := readTranscript(transcript)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries [was: %v]", len(entries))
+	}
+
+	entries[0].Line = "example action --values fi"
+	if output, err := replay(executable, entries[0]); err != nil || record.Hash(output) == entries[0].Hash {
+		t.Errorf("changed line should result in a different output: %v", output)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/carapace-sh/carapace"
 	"github.com/carapace-sh/carapace/internal/assert"
@@ -55,6 +56,13 @@ func (s *Sandbox) ClearCache() {
 	}
 }
 
+// AdvanceTime shifts the clock used to expire cache entries for subsequent runs.
+//
+//	s.AdvanceTime(10 * time.Second)
+func (s *Sandbox) AdvanceTime(d time.Duration) {
+	s.mock.Offset += d
+}
+
 // Files creates files within the sandbox directory.
 //
 //	s.Files(
@@ -118,3 +118,13 @@ func TestEnv(t *testing.T) {
 			Expect(carapace.ActionValues(os.Getenv("LS_COLORS")))
 	})
 }
+
+func BenchmarkValues(b *testing.B) {
+	Benchmark(b, func() *cobra.Command {
+		cmd := &cobra.Command{}
+		carapace.Gen(cmd).PositionalCompletion(
+			carapace.ActionValues("one", "two", "three"),
+		)
+		return cmd
+	}, "t")
+}
@@ -0,0 +1,178 @@
+package sandbox
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/carapace-sh/carapace/pkg/shlex"
+)
+
+// Shell tests the completion snippet of given executable within an actual shell.
+// The test is skipped if the shell is not installed.
+//
+//	sandbox.Shell(t, "bash", "/tmp/example")("example", "action", "--values", "").
+//		Expect("first", "second", "third")
+//
+// Supported shells are `bash` (invoking the registered completion function with `COMP_*` variables),
+// `elvish` (invoking the registered arg-completer with a stub of the `edit:` module),
+// `fish` (using `complete -C`), `ksh` (invoking the completion function with `COMP_*` variables)
+// and `zsh` (completing within a pty created by `zpty`).
+func Shell(t *testing.T, shell, executable string) func(args ...string) shellRun {
+	return func(args ...string) shellRun {
+		return shellRun{t: t, shell: shell, executable: executable, args: args}
+	}
+}
+
+type shellRun struct {
+	t          *testing.T
+	shell      string
+	executable string
+	args       []string
+}
+
+// Expect validates the candidate values returned by the shell.
+func (r shellRun) Expect(values ...string) {
+	r.t.Run(r.shell+":"+strings.Join(r.args, " "), func(t *testing.T) {
+		actual, err := r.complete(t)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+
+		expected := append([]string{}, values...)
+		sort.Strings(expected)
+		sort.Strings(actual)
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected %#v, got %#v", expected, actual)
+		}
+	})
+}
+
+func (r shellRun) complete(t *testing.T) ([]string, error) {
+	if _, err := exec.LookPath(r.shell); err != nil {
+		t.Skipf("shell not installed: %v", r.shell)
+	}
+
+	executable, err := filepath.Abs(r.executable)
+	if err != nil {
+		return nil, err
+	}
+
+	snippet, err := exec.Command(executable, "_carapace", r.shell).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	file := filepath.Join(t.TempDir(), "snippet")
+	if err := os.WriteFile(file, snippet, 0600); err != nil {
+		return nil, err
+	}
+
+	f, ok := shellScripts[r.shell]
+	if !ok {
+		t.Skipf("shell not supported: %v", r.shell)
+	}
+	script := f(file, filepath.Join(t.TempDir(), "zcompdump"), r.args)
+
+	cmd := exec.Command(r.shell, "-c", script)
+	cmd.Env = append(os.Environ(), "PATH="+filepath.Dir(executable)+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSuffix(line, "\r") // pty
+		if value := strings.SplitN(line, "\t", 2)[0]; value != "" {
+			values = append(values, value) // fish appends the description separated by a tab
+		}
+	}
+	return values, nil
+}
+
+// shellScripts create the script invoking the completion of given snippet file.
+var shellScripts = map[string]func(file, tmpFile string, args []string) string{
+	"bash": func(file, tmpFile string, args []string) string {
+		return strings.Join([]string{
+			"source " + shlex.Quote(file),
+			"COMP_LINE=" + shlex.Quote(strings.Join(args, " ")),
+			"COMP_POINT=${#COMP_LINE}",
+			"COMP_TYPE=9",
+			"COMP_WORDS=(" + shlex.Join(args) + ")",
+			"COMP_CWORD=$((${#COMP_WORDS[@]}-1))",
+			"function=$(complete -p -- " + shlex.Quote(filepath.Base(args[0])) + ")", // name of the function registered with `-F`
+			"function=${function#* -F }",
+			"function=${function%% *}",
+			`"$function" 2>/dev/null`,
+			`printf '%s\n' "${COMPREPLY[@]}"`,
+		}, "\n")
+	},
+	"elvish": func(file, tmpFile string, args []string) string {
+		quoted := make([]string, len(args))
+		for index, arg := range args {
+			quoted[index] = quoteElvish(arg)
+		}
+		return strings.Join([]string{
+			"var edit: = (ns [&completion:=(ns [&arg-completer=[&]]) &notify~={|m| } &complex-candidate~={|value &display=$nil &code-suffix=''| put $value }])",
+			"eval (slurp < " + quoteElvish(file) + ")",
+			"$edit:completion:arg-completer[" + quoteElvish(filepath.Base(args[0])) + "] " + strings.Join(quoted, " ") + " | each {|value| echo $value }",
+		}, "\n")
+	},
+	"fish": func(file, tmpFile string, args []string) string {
+		return "source " + shlex.Quote(file) + "\ncomplete -C " + shlex.Quote(strings.Join(args, " "))
+	},
+	"ksh": func(file, tmpFile string, args []string) string {
+		return strings.Join([]string{
+			". " + shlex.Quote(file),
+			"COMP_LINE=" + shlex.Quote(strings.Join(args, " ")),
+			"COMP_POINT=${#COMP_LINE}",
+			"_" + filepath.Base(args[0]) + "_completion 2>/dev/null",
+			`printf '%s\n' "${COMPREPLY[@]}"`,
+		}, "\n")
+	},
+	"zsh": func(file, tmpFile string, args []string) string {
+		// completion needs zle, so an interactive shell is started within a pty which prints the values passed to compadd
+		// (based on https://github.com/Valodim/zsh-capture-completion)
+		return strings.Join([]string{
+			"zmodload zsh/zpty || exit 1",
+			"zpty z zsh -f -i",
+			"zpty -w z " + shlex.Quote(strings.Join([]string{
+				"PROMPT=",
+				"autoload -U compinit && compinit -u -d " + shlex.Quote(tmpFile),
+				"bindkey '^I' complete-word",
+				"null-line() { echo -E - $'\\0' }",
+				"compprefuncs=( null-line )",
+				"comppostfuncs=( null-line exit )",
+				"zstyle ':completion:*' insert-tab false",
+				`compadd() { if [[ ${@[1,(i)(-|--)]} == *-(O|A|D)\ * ]]; then builtin compadd "$@"; return $?; fi; typeset -a __hits; builtin compadd -A __hits "$@"; local hit; for hit in $__hits; do echo -E - "$IPREFIX$hit"; done }`,
+				"source " + shlex.Quote(file),
+			}, "; ")),
+			"zpty -w z " + shlex.Quote(zshLine(args)) + "$'\\t'",
+			"integer tog=0",
+			"while zpty -r z; do :; done | while IFS= read -r line; do",
+			"  if [[ $line == *$'\\0\\r' ]]; then",
+			"    (( tog++ )) && exit 0 || continue",
+			"  fi",
+			"  (( tog )) && echo -E - \"${line%$'\\r'}\"",
+			"done",
+			"exit 2",
+		}, "\n")
+	},
+}
+
+func quoteElvish(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// zshLine joins given args to the line typed into zsh (an empty current word is not quoted).
+func zshLine(args []string) string {
+	if current := args[len(args)-1]; current == "" {
+		return shlex.Join(args[:len(args)-1]) + " "
+	}
+	return shlex.Join(args)
+}
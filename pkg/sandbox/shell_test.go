@@ -0,0 +1,18 @@
+package sandbox
+
+import "testing"
+
+func TestShell(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping shell test in short mode")
+	}
+
+	executable := exampleExecutable(t)
+
+	for _, shell := range []string{"bash", "elvish", "fish", "ksh", "zsh"} {
+		Shell(t, shell, executable)("example", "action", "--values", "").
+			Expect("first", "second", "third")
+		Shell(t, shell, executable)("example", "action", "--values", "fi").
+			Expect("first")
+	}
+}
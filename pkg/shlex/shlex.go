@@ -0,0 +1,170 @@
+// Package shlex provides POSIX quoting (the reverse of github.com/carapace-sh/carapace-shlex.Split)
+package shlex
+
+import (
+	"regexp"
+	"strings"
+
+	shlex "github.com/carapace-sh/carapace-shlex"
+)
+
+var unsafe = regexp.MustCompile(`[^a-zA-Z0-9_@%+=:,./-]`)
+var assignment = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*=`)
+var variable = regexp.MustCompile(`\$(\{[a-zA-Z_][a-zA-Z0-9_]*\}|[a-zA-Z_][a-zA-Z0-9_]*)`)
+var expansionPrefix = regexp.MustCompile(`^(~[a-zA-Z0-9_.-]*|\$\{[a-zA-Z_][a-zA-Z0-9_]*\}|\$[a-zA-Z_][a-zA-Z0-9_]*)(/|$)`)
+var wrappers = map[string]bool{
+	"builtin": true,
+	"command": true,
+	"exec":    true,
+	"nohup":   true,
+}
+var separators = map[string]bool{
+	";":  true,
+	"&":  true,
+	"&&": true,
+	"|":  true,
+	"||": true,
+}
+
+// Quote returns a shell-escaped version of given string.
+//
+//	Quote("it's") // 'it'\''s'
+func Quote(s string) string {
+	switch {
+	case s == "":
+		return "''"
+	case !unsafe.MatchString(s):
+		return s
+	default:
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+}
+
+// Join concatenates given words to a single (shell-escaped) string.
+//
+//	Join([]string{"echo", "$HOME"}) // echo '$HOME'
+func Join(s []string) string {
+	quoted := make([]string, len(s))
+	for index, word := range s {
+		quoted[index] = Quote(word)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// OpenQuote returns the quote left open by given lexer state (empty if none).
+//
+//	tokens, _ := shlex.Split(`example "some fi`)
+//	OpenQuote(tokens.CurrentToken().State) // "
+func OpenQuote(state shlex.LexerState) string {
+	switch state {
+	case shlex.QUOTING_ESCAPING_STATE, shlex.ESCAPING_QUOTED_STATE:
+		return `"`
+	case shlex.QUOTING_STATE:
+		return `'`
+	default:
+		return ""
+	}
+}
+
+// SkipAssignments removes leading environment assignments from given words.
+// The last word is kept as it is the one currently being completed.
+//
+//	SkipAssignments([]string{"FOO=bar", "example", ""}) // ["example", ""]
+func SkipAssignments(words []string) []string {
+	for len(words) > 1 && assignment.MatchString(words[0]) {
+		words = words[1:]
+	}
+	return words
+}
+
+// SkipWrappers removes leading wrapper words like `nohup` (and their flags) as well as environment assignments from given words.
+// Skipping stops at given program so that it is kept even when named like a wrapper.
+// The last word is kept as it is the one currently being completed.
+//
+//	SkipWrappers([]string{"FOO=bar", "nohup", "example", ""}, "example") // ["example", ""]
+//	SkipWrappers([]string{"nohup", "ech"}, "nohup")                      // ["nohup", "ech"]
+func SkipWrappers(words []string, program string) []string {
+	wrapped := false
+	for len(words) > 1 {
+		switch {
+		case words[0] == program:
+			return words
+		case !wrapped && assignment.MatchString(words[0]):
+		case wrappers[words[0]]:
+			wrapped = true
+		case wrapped && strings.HasPrefix(words[0], "-"): // e.g. `command -p`
+		default:
+			return words
+		}
+		words = words[1:]
+	}
+	return words
+}
+
+// SkipSubstitutions removes words preceding an unclosed command substitution (a word starting with `$(`
+// or with `(` in fish) as well as preceding commands within it, so that the innermost command is completed.
+// The last word is kept as it is the one currently being completed.
+//
+//	SkipSubstitutions([]string{"echo", "$(example", "sub", ""}, false)     // ["example", "sub", ""]
+//	SkipSubstitutions([]string{"echo", "(cd", "x;", "example", ""}, true) // ["example", ""]
+func SkipSubstitutions(words []string, fish bool) []string {
+	if len(words) < 2 {
+		return words
+	}
+
+	stack := make([]int, 0)
+	for i, word := range words[:len(words)-1] {
+		opens := strings.HasPrefix(word, "$(") || (fish && strings.HasPrefix(word, "("))
+		closes := strings.HasSuffix(word, ")")
+		switch {
+		case opens && !closes:
+			stack = append(stack, i)
+		case !opens && closes && len(stack) > 0:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) == 0 {
+		return words
+	}
+
+	innermost := stack[len(stack)-1]
+	opening := words[innermost]
+	inner := append([]string{opening[strings.Index(opening, "(")+1:]}, words[innermost+1:]...)
+	for i := len(inner) - 2; i >= 0; i-- {
+		if separators[inner[i]] || strings.HasSuffix(inner[i], ";") {
+			inner = inner[i+1:]
+			break
+		}
+	}
+	if inner[0] == "" {
+		inner = inner[1:] // `$( example`
+	}
+	if len(inner) < 2 {
+		return words // command itself is being completed
+	}
+	return inner
+}
+
+// ExpandVariables replaces variables (`$VAR` and `${VAR}`) in given word using given lookup function.
+// Unset variables are kept as is.
+//
+//	ExpandVariables("$HOME/docs", os.LookupEnv) // /home/user/docs
+func ExpandVariables(s string, lookupEnv func(key string) (string, bool)) string {
+	return variable.ReplaceAllStringFunc(s, func(match string) string {
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(match, "$"), "{"), "}")
+		if value, ok := lookupEnv(name); ok {
+			return value
+		}
+		return match
+	})
+}
+
+// ExpansionPrefix returns the leading segment of given word which is expanded by the shell
+// (`~`, `~user`, `$VAR` or `${VAR}` including the following `/`).
+// Serializers keep it unquoted so the inserted value retains the unexpanded form.
+//
+//	ExpansionPrefix("$HOME/some file") // $HOME/
+//	ExpansionPrefix("~user/some file") // ~user/
+func ExpansionPrefix(s string) string {
+	return expansionPrefix.FindString(s)
+}
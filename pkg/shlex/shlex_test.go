@@ -0,0 +1,190 @@
+package shlex
+
+import (
+	"strings"
+	"testing"
+
+	shlex "github.com/carapace-sh/carapace-shlex"
+)
+
+func TestQuote(t *testing.T) {
+	for s, expected := range map[string]string{
+		"":                  "''",
+		"plain":             "plain",
+		"with space":        "'with space'",
+		"it's":              `'it'\''s'`,
+		"$(rm -rf ~)":       "'$(rm -rf ~)'",
+		"`id`":              "'`id`'",
+		"--flag=value,list": "--flag=value,list",
+	} {
+		if actual := Quote(s); actual != expected {
+			t.Errorf("expected %v [was: %v]", expected, actual)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	args := []string{"example", "", "it's", "$(rm -rf ~)", "multi\nline"}
+
+	tokens, err := shlex.Split(Join(args))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	if words := tokens.Words().Strings(); len(words) != len(args) {
+		t.Errorf("expected %#v [was: %#v]", args, words)
+	} else {
+		for index, word := range words {
+			if word != args[index] {
+				t.Errorf("expected %#v [was: %#v]", args[index], word)
+			}
+		}
+	}
+}
+
+func TestOpenQuote(t *testing.T) {
+	for s, expected := range map[string]string{
+		``:                 "",
+		`example fi`:       "",
+		`example "some fi`: `"`,
+		`example "some\"`:  `"`,
+		`example 'it`:      `'`,
+		`example "it's`:    `"`,
+		`example 'closed'`: "",
+	} {
+		tokens, err := shlex.Split(s)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual := OpenQuote(tokens.CurrentToken().State); actual != expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", s, expected, actual)
+		}
+	}
+}
+
+func TestSkipAssignments(t *testing.T) {
+	for s, expected := range map[string]string{
+		``:                           ``,
+		`example fi`:                 `example fi`,
+		`FOO=bar example fi`:         `example fi`,
+		`FOO=bar BAR="a b" example `: `example `,
+		`FOO=bar`:                    `FOO=bar`,
+		`FOO=bar BA`:                 `BA`,
+		`example FOO=bar`:            `example FOO=bar`,
+		`./example --flag=value `:    `./example --flag=value `,
+	} {
+		tokens, err := shlex.Split(s)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual := strings.Join(SkipAssignments(tokens.Words().Strings()), " "); actual != expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", s, expected, actual)
+		}
+	}
+}
+
+func TestSkipWrappers(t *testing.T) {
+	for s, expected := range map[string]string{
+		`example fi`:                  `example fi`,
+		`nohup example fi`:            `example fi`,
+		`FOO=bar nohup example `:      `example `,
+		`command -p exec example -`:   `example -`,
+		`builtin`:                     `builtin`,
+		`nohup -`:                     `-`,
+		`nohup FOO=bar`:               `FOO=bar`,
+		`example nohup --flag=value `: `example nohup --flag=value `,
+		`FOO=bar example fi`:          `example fi`,
+	} {
+		tokens, err := shlex.Split(s)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if actual := strings.Join(SkipWrappers(tokens.Words().Strings(), "example"), " "); actual != expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", s, expected, actual)
+		}
+	}
+
+	for program, expected := range map[string]string{
+		"nohup":   `nohup ech`,
+		"command": `command -p nohup ech`,
+	} {
+		if actual := strings.Join(SkipWrappers([]string{"FOO=bar", "command", "-p", "nohup", "ech"}, program), " "); actual != expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", program, expected, actual)
+		}
+	}
+}
+
+func TestSkipSubstitutions(t *testing.T) {
+	for _, test := range []struct {
+		words    []string
+		fish     bool
+		expected []string
+	}{
+		{[]string{"example", "fi"}, false, []string{"example", "fi"}},
+		{[]string{"echo", "$(example", "sub", ""}, false, []string{"example", "sub", ""}},
+		{[]string{"echo", "(example", "sub", ""}, true, []string{"example", "sub", ""}},
+		{[]string{"echo", "(example", "sub", ""}, false, []string{"echo", "(example", "sub", ""}},
+		{[]string{"echo", "$(", "example", ""}, false, []string{"example", ""}},
+		{[]string{"echo", "$(cd", "dir", "&&", "example", ""}, false, []string{"example", ""}},
+		{[]string{"echo", "(cd", "dir;", "example", ""}, true, []string{"example", ""}},
+		{[]string{"echo", "$(outer", "$(example", ""}, false, []string{"example", ""}},
+		{[]string{"echo", "$(other)", "example", ""}, false, []string{"echo", "$(other)", "example", ""}},
+		{[]string{"echo", "$(other", "$(a)", "x)", "example", ""}, false, []string{"echo", "$(other", "$(a)", "x)", "example", ""}},
+		{[]string{"echo", "$(exam"}, false, []string{"echo", "$(exam"}},
+		{[]string{"echo", "$(", ""}, false, []string{"echo", "$(", ""}},
+		{[]string{"example", "--regex", "a(b", "x", ""}, false, []string{"example", "--regex", "a(b", "x", ""}},
+		{[]string{"example", "--regex", "a(b", "x", ""}, true, []string{"example", "--regex", "a(b", "x", ""}},
+		{[]string{"git", "commit", "-m", ":(", ""}, true, []string{"git", "commit", "-m", ":(", ""}},
+		{[]string{"example", "--flag=$(other", "x", ""}, false, []string{"example", "--flag=$(other", "x", ""}},
+		{[]string{"example", "`other", "x", ""}, false, []string{"example", "`other", "x", ""}},
+		{[]string{"echo", "$(example", "--regex", "a(b", "x", ""}, false, []string{"example", "--regex", "a(b", "x", ""}},
+	} {
+		if actual := SkipSubstitutions(test.words, test.fish); strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("%#v (fish: %v): expected %#v [was: %#v]", test.words, test.fish, test.expected, actual)
+		}
+	}
+}
+
+func TestExpandVariables(t *testing.T) {
+	lookupEnv := func(key string) (string, bool) {
+		switch key {
+		case "HOME":
+			return "/home/user", true
+		case "EMPTY":
+			return "", true
+		default:
+			return "", false
+		}
+	}
+
+	for s, expected := range map[string]string{
+		"$HOME/docs":         "/home/user/docs",
+		"${HOME}/docs":       "/home/user/docs",
+		"$HOME$EMPTY/docs":   "/home/user/docs",
+		"$UNSET/docs":        "$UNSET/docs",
+		"~/docs":             "~/docs",
+		"prefix-${HOME}.bak": "prefix-/home/user.bak",
+	} {
+		if actual := ExpandVariables(s, lookupEnv); actual != expected {
+			t.Errorf("expected %v [was: %v]", expected, actual)
+		}
+	}
+}
+
+func TestExpansionPrefix(t *testing.T) {
+	for s, expected := range map[string]string{
+		"$HOME/some file": "$HOME/",
+		"${HOME}/file":    "${HOME}/",
+		"$HOME":           "$HOME",
+		"~/file":          "~/",
+		"~user/file":      "~user/",
+		"~user":           "~user",
+		"$HOMEfile/x":     "$HOMEfile/",
+		"plain/file":      "",
+		"${HOME}file":     "",
+		"a$HOME/file":     "",
+	} {
+		if actual := ExpansionPrefix(s); actual != expected {
+			t.Errorf("%#v: expected %#v [was: %#v]", s, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package style
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/carapace-sh/carapace/third_party/github.com/elves/elvish/pkg/ui"
+)
+
+// Capability is the color capability of a terminal.
+type Capability int
+
+const (
+	CapabilityNone      Capability = iota // no styling
+	Capability16                          // 16 ANSI colors
+	Capability256                         // xterm 256-color palette
+	CapabilityTrueColor                   // 24-bit true color
+)
+
+// DetectCapability detects the color capability of the terminal
+// based on NO_COLOR, COLORTERM, TERM and the Windows console mode.
+func DetectCapability() Capability {
+	if os.Getenv("NO_COLOR") != "" { // any non-empty value disables color (https://no-color.org)
+		return CapabilityNone
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return CapabilityTrueColor
+	}
+
+	term := os.Getenv("TERM")
+	switch {
+	case term == "dumb":
+		return CapabilityNone
+	case strings.HasSuffix(term, "-direct"), strings.Contains(term, "truecolor"):
+		return CapabilityTrueColor
+	case strings.Contains(term, "256color"):
+		return Capability256
+	case term != "":
+		return Capability16
+	default:
+		return consoleCapability() // unknown terminal (styles are kept as is unless detected otherwise)
+	}
+}
+
+// SGR returns the SGR sequence for given style downgraded to the capability.
+func (c Capability) SGR(s string) string { return c.Downgrade(Parse(s)).SGR() }
+
+// Downgrade converts the colors of given style to ones supported by the capability.
+func (c Capability) Downgrade(s ui.Style) ui.Style {
+	if c == CapabilityNone {
+		return ui.Style{}
+	}
+	s.Foreground = c.downgradeColor(s.Foreground)
+	s.Background = c.downgradeColor(s.Background)
+	return s
+}
+
+func (c Capability) downgradeColor(color ui.Color) ui.Color {
+	if color == nil {
+		return nil
+	}
+
+	name := color.String()
+	switch {
+	case strings.HasPrefix(name, "#"): // true color
+		r, g, b := hexRGB(name)
+		switch c {
+		case Capability256:
+			return ui.XTerm256Color(nearest256(r, g, b))
+		case Capability16:
+			return ansiColors[nearest16(r, g, b)]
+		}
+	case strings.HasPrefix(name, "color") && c == Capability16: // xterm 256-color
+		i, err := strconv.Atoi(strings.TrimPrefix(name, "color"))
+		if err != nil {
+			return color
+		}
+		if i < 16 {
+			return ansiColors[i]
+		}
+		r, g, b := xterm256RGB(uint8(i))
+		return ansiColors[nearest16(r, g, b)]
+	}
+	return color
+}
+
+var ansiColors = []ui.Color{
+	ui.Black, ui.Red, ui.Green, ui.Yellow, ui.Blue, ui.Magenta, ui.Cyan, ui.White,
+	ui.BrightBlack, ui.BrightRed, ui.BrightGreen, ui.BrightYellow, ui.BrightBlue, ui.BrightMagenta, ui.BrightCyan, ui.BrightWhite,
+}
+
+// ansiRGB contains the xterm defaults for the 16 ANSI colors.
+var ansiRGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+func hexRGB(s string) (r, g, b uint8) {
+	if i, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32); err == nil {
+		return uint8(i >> 16), uint8(i >> 8), uint8(i)
+	}
+	return 0, 0, 0
+}
+
+func xterm256RGB(i uint8) (r, g, b uint8) {
+	switch {
+	case i < 16:
+		return ansiRGB[i][0], ansiRGB[i][1], ansiRGB[i][2]
+	case i < 232:
+		i -= 16
+		return cubeLevels[i/36], cubeLevels[(i/6)%6], cubeLevels[i%6]
+	default:
+		gray := 8 + 10*(i-232)
+		return gray, gray, gray
+	}
+}
+
+func nearest256(r, g, b uint8) uint8 {
+	best, bestDistance := uint8(16), -1
+	for i := 16; i < 256; i++ {
+		cr, cg, cb := xterm256RGB(uint8(i))
+		if d := distance(r, g, b, cr, cg, cb); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = uint8(i), d
+		}
+	}
+	return best
+}
+
+func nearest16(r, g, b uint8) int {
+	best, bestDistance := 0, -1
+	for i, c := range ansiRGB {
+		if d := distance(r, g, b, c[0], c[1], c[2]); bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	return best
+}
+
+func distance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package style
+
+func consoleCapability() Capability {
+	return CapabilityTrueColor
+}
@@ -0,0 +1,50 @@
+package style
+
+import "testing"
+
+func TestDetectCapability(t *testing.T) {
+	for env, expected := range map[[3]string]Capability{
+		{"1", "truecolor", "xterm-256color"}: CapabilityNone,
+		{"0", "truecolor", "xterm-256color"}: CapabilityNone,
+		{"", "truecolor", "xterm"}:           CapabilityTrueColor,
+		{"", "", "xterm-direct"}:             CapabilityTrueColor,
+		{"", "", "xterm-256color"}:           Capability256,
+		{"", "", "xterm"}:                    Capability16,
+		{"", "", "dumb"}:                     CapabilityNone,
+	} {
+		t.Setenv("NO_COLOR", env[0])
+		t.Setenv("COLORTERM", env[1])
+		t.Setenv("TERM", env[2])
+		if capability := DetectCapability(); capability != expected {
+			t.Errorf("expected %v for %#v, got %v", expected, env, capability)
+		}
+	}
+}
+
+func TestDowngrade(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("COLORTERM", "")
+	for s, expected := range map[[2]string]string{
+		{TrueColor(255, 0, 0), "xterm-direct"}:           "38;2;255;0;0",
+		{TrueColor(255, 0, 0), "xterm-256color"}:         "38;5;196",
+		{TrueColor(255, 0, 0), "xterm"}:                  "91",
+		{XTerm256Color(21), "xterm"}:                     "34",
+		{XTerm256Color(1), "xterm"}:                      "31",
+		{Of(Bold, TrueColor(0, 0, 0)), "xterm-256color"}: "1;38;5;16",
+		{Of(Bold, Red), "dumb"}:                          "",
+	} {
+		t.Setenv("TERM", s[1])
+		if sgr := SGR(s[0]); sgr != expected {
+			t.Errorf("expected %#v for %#v, got %#v", expected, s, sgr)
+		}
+	}
+}
+
+func TestCapabilitySGR(t *testing.T) {
+	if sgr := Capability256.SGR(TrueColor(255, 0, 0)); sgr != "38;5;196" {
+		t.Errorf("expected %#v, got %#v", "38;5;196", sgr)
+	}
+	if sgr := CapabilityNone.SGR(Of(Bold, Red)); sgr != "" {
+		t.Errorf("expected no SGR, got %#v", sgr)
+	}
+}
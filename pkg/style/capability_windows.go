@@ -0,0 +1,25 @@
+//go:build windows
+
+package style
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+func consoleCapability() Capability {
+	if os.Getenv("WT_SESSION") != "" {
+		return CapabilityTrueColor // Windows Terminal
+	}
+
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(os.Stderr.Fd()), &mode); err != nil {
+		return CapabilityTrueColor // not a console (e.g. redirected)
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return CapabilityTrueColor
+	}
+	return Capability16
+}
@@ -28,7 +28,11 @@ type carapace struct {
 	Value       string `description:"default style for values" tag:"core styles"`
 	Description string `description:"default style for descriptions" tag:"core styles"`
 	Error       string `description:"default style for errors" tag:"core styles"`
+	Warning     string `description:"default style for warnings" tag:"core styles"`
+	Info        string `description:"default style for info messages" tag:"core styles"`
 	Usage       string `description:"default style for usage" tag:"core styles"`
+	Match       string `description:"style for the part of values matching the current word in elvish and zsh (empty to disable)" tag:"core styles"`
+	Default     string `description:"style added to the default value (empty to disable)" tag:"core styles"`
 
 	KeywordAmbiguous string `description:"keyword describing a ambiguous state" tag:"keyword styles"`
 	KeywordNegative  string `description:"keyword describing a negative state" tag:"keyword styles"`
@@ -56,16 +60,21 @@ type carapace struct {
 	Highlight11 string `description:"Highlight 11" tag:"highlight styles"`
 	Highlight12 string `description:"Highlight 12" tag:"highlight styles"`
 
-	FlagArg      string `description:"flag with argument" tag:"flag styles"`
-	FlagMultiArg string `description:"flag with multiple arguments" tag:"flag styles"`
-	FlagNoArg    string `description:"flag without argument" tag:"flag styles"`
-	FlagOptArg   string `description:"flag with optional argument" tag:"flag styles"`
+	FlagArg        string `description:"flag with argument" tag:"flag styles"`
+	FlagMultiArg   string `description:"flag with multiple arguments" tag:"flag styles"`
+	FlagNoArg      string `description:"flag without argument" tag:"flag styles"`
+	FlagOptArg     string `description:"flag with optional argument" tag:"flag styles"`
+	FlagRequired   string `description:"flag required by another flag already set" tag:"flag styles"`
+	FlagDeprecated string `description:"deprecated flag" tag:"flag styles"`
+	FlagHidden     string `description:"hidden flag" tag:"flag styles"`
 }
 
 var Carapace = carapace{
 	Value:       Default,
 	Description: Dim,
 	Error:       Of(Bold, Red),
+	Warning:     Of(Bold, Yellow),
+	Info:        Of(Bold, Blue),
 	Usage:       Dim,
 
 	KeywordAmbiguous: Yellow,
@@ -94,10 +103,13 @@ var Carapace = carapace{
 	Highlight11: Bold,
 	Highlight12: Of(Dim, Bold),
 
-	FlagArg:      Blue,
-	FlagMultiArg: Magenta,
-	FlagNoArg:    Default,
-	FlagOptArg:   Yellow,
+	FlagArg:        Blue,
+	FlagMultiArg:   Magenta,
+	FlagNoArg:      Default,
+	FlagOptArg:     Yellow,
+	FlagRequired:   Underlined,
+	FlagDeprecated: Of(Dim, Italic),
+	FlagHidden:     Dim,
 }
 
 // Highlight returns the style for given level (0..n)
@@ -63,8 +63,9 @@ func XTerm256Color(i uint8) string { return ui.XTerm256Color(i).String() }
 // TrueColor returns a 24-bit true color.
 func TrueColor(r, g, b uint8) string { return ui.TrueColor(r, g, b).String() }
 
-// SGR returns the SGR sequence for given style.
-func SGR(s string) string { return Parse(s).SGR() }
+// SGR returns the SGR sequence for given style (downgraded to the detected terminal capability).
+// The capability is detected on each call, so use Capability.SGR for many styles.
+func SGR(s string) string { return DetectCapability().SGR(s) }
 
 func Parse(s string) ui.Style {
 	stylings := make([]ui.Styling, 0)
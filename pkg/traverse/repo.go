@@ -0,0 +1,46 @@
+package traverse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RepoMarkers are the markers used by RepoRoot if none are given.
+var RepoMarkers = []string{".git", "go.mod", "package.json"}
+
+// RepoRoot returns the nearest parent directory containing any of given markers (default: RepoMarkers).
+// In contrast to Parent the closest directory wins regardless of the order of the markers,
+// so nested modules of a monorepo are found before the repository itself.
+func RepoRoot(markers ...string) func(tc Context) (string, error) {
+	if len(markers) == 0 {
+		markers = RepoMarkers
+	}
+	return func(tc Context) (string, error) {
+		wd, err := tc.Abs("")
+		if err != nil {
+			return "", err
+		}
+
+		for dir := filepath.ToSlash(filepath.Clean(wd)); ; {
+			for _, marker := range markers {
+				if _, err := os.Stat(dir + "/" + strings.TrimSuffix(marker, "/")); err == nil {
+					return dir, nil
+				}
+			}
+
+			parent := filepath.ToSlash(filepath.Dir(dir))
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+
+		formattedMarkers := fmt.Sprintf("%#v", markers)
+		formattedMarkers = strings.TrimPrefix(formattedMarkers, "[]string{")
+		formattedMarkers = strings.TrimSuffix(formattedMarkers, "}")
+		return "", errors.New("could not find parent directory containing any of: " + formattedMarkers)
+	}
+}
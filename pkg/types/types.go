@@ -0,0 +1,38 @@
+// Package types provides stable aliases for types shared with action libraries.
+//
+// The underlying types are located in internal packages, which can't be imported outside of carapace
+// and may be restructured at any time. Referring to them through this package keeps downstream code
+// working when they move. Aliases are only removed in a new major version and are marked as
+// `Deprecated` beforehand (e.g. when a type is renamed the old name stays as alias of the new one).
+//
+// Context is part of the root package (carapace.Context) and thus already stable.
+package types
+
+import (
+	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/export"
+)
+
+type (
+	// Export is the json representation of an invoked action (see ActionImport).
+	Export = export.Export
+	// Meta contains the metadata of an action (messages, nospace, usage, ...).
+	Meta = common.Meta
+	// Message is a message with its level.
+	Message = common.Message
+	// Messages contains the messages of an action.
+	Messages = common.Messages
+	// RawValue is a completion value with its description, style, tag, ...
+	RawValue = common.RawValue
+	// RawValues is a list of completion values.
+	RawValues = common.RawValues
+	// SuffixMatcher matches the suffixes which prevent a space being added.
+	SuffixMatcher = common.SuffixMatcher
+)
+
+// Levels of a Message.
+const (
+	LevelError   = common.LevelError
+	LevelWarning = common.LevelWarning
+	LevelInfo    = common.LevelInfo
+)
@@ -0,0 +1,33 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/carapace-sh/carapace"
+	"github.com/carapace-sh/carapace/pkg/types"
+	"github.com/carapace-sh/carapace/pkg/x"
+	"github.com/spf13/cobra"
+)
+
+func TestExport(t *testing.T) {
+	cmd := &cobra.Command{}
+	carapace.Gen(cmd).PositionalCompletion(
+		carapace.ActionValues("one", "two").NoSpace('o'),
+	)
+
+	var e *types.Export
+	e, err := x.Complete(cmd, "export", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var values types.RawValues = e.Values
+	if values = values.Filter("two"); len(values) != 1 || values[0].Value != "one" {
+		t.Errorf("unexpected values: %#v", values)
+	}
+
+	var meta types.Meta = e.Meta
+	if !meta.Nospace.Matches("two") {
+		t.Errorf("expected nospace for 'two': %#v", meta.Nospace)
+	}
+}
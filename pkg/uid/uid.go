@@ -1,14 +1,26 @@
-// Package uid provides unique identifiers
+// Package uid provides unique identifiers in the form of URLs.
+//
+// Commands use the `cmd` scheme with the root command as host and the names of subcommands as path.
+// Flags add their name as `flag` query parameter.
+//
+//	cmd://example                 // root command
+//	cmd://example/sub             // subcommand
+//	cmd://example/sub?flag=values // flag (the one whose callback completes its values)
+//
+// Files use the `file` scheme with an absolute path (`file:///tmp/example.txt`).
+// The format is stable so that external tools (e.g. spec files or bridges) can reference commands and flags.
 package uid
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/carapace-sh/carapace/internal/pflagfork"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 type Context interface {
@@ -17,6 +29,18 @@ type Context interface {
 	LookupEnv(key string) (string, bool)
 }
 
+// New creates a uid for given command path (root command first).
+//
+//	New("example", "sub") // cmd://example/sub
+func New(path ...string) *url.URL {
+	uid := &url.URL{Scheme: "cmd"}
+	if len(path) > 0 {
+		uid.Host = path[0]
+		uid.Path = strings.Join(path[1:], "/")
+	}
+	return uid
+}
+
 // Command creates a uid for given command.
 func Command(cmd *cobra.Command) *url.URL {
 	path := []string{cmd.Name()}
@@ -24,11 +48,7 @@ func Command(cmd *cobra.Command) *url.URL {
 		path = append(path, parent.Name())
 	}
 	reverse(path) // TODO slices.Reverse
-	return &url.URL{
-		Scheme: "cmd",
-		Host:   path[0],
-		Path:   strings.Join(path[1:], "/"),
-	}
+	return New(path...)
 }
 
 // reverse reverses the elements of the slice in place.
@@ -38,15 +58,74 @@ func reverse(s []string) {
 	}
 }
 
-// Flag creates a uid for given flag.
-func Flag(cmd *cobra.Command, flag *pflagfork.Flag) *url.URL {
+// Flag creates a uid for the flag with given name.
+//
+//	Flag(subCmd, "values") // cmd://example/sub?flag=values
+func Flag(cmd *cobra.Command, name string) *url.URL {
 	uid := Command(cmd)
 	values := uid.Query()
-	values.Set("flag", flag.Name)
+	values.Set("flag", name)
 	uid.RawQuery = values.Encode()
 	return uid
 }
 
+// Parse parses given uid into the command path (root command first) and the flag name (if any).
+//
+//	Parse("cmd://example/sub?flag=values") // ["example", "sub"], "values"
+func Parse(s string) (path []string, flag string, err error) {
+	uid, err := url.Parse(s)
+	if err != nil {
+		return nil, "", err
+	}
+	if uid.Scheme != "cmd" || uid.Host == "" {
+		return nil, "", fmt.Errorf("not a command uid: %v", s)
+	}
+
+	path = []string{uid.Host}
+	if trimmed := strings.Trim(uid.Path, "/"); trimmed != "" {
+		path = append(path, strings.Split(trimmed, "/")...)
+	}
+	return path, uid.Query().Get("flag"), nil
+}
+
+// Find returns the command and flag (nil if none) referenced by given uid within the command tree of root.
+func Find(root *cobra.Command, s string) (*cobra.Command, *pflag.Flag, error) {
+	path, flagName, err := Parse(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if path[0] != root.Name() {
+		return nil, nil, fmt.Errorf("unknown root command: %v", path[0])
+	}
+
+	cmd := root
+	for _, name := range path[1:] {
+		var found *cobra.Command
+		for _, subcmd := range cmd.Commands() {
+			if subcmd.Name() == name {
+				found = subcmd
+				break
+			}
+		}
+		if found == nil {
+			return nil, nil, fmt.Errorf("unknown subcommand of %v: %v", Command(cmd), name)
+		}
+		cmd = found
+	}
+
+	if flagName == "" {
+		return cmd, nil, nil
+	}
+	flag := cmd.Flags().Lookup(flagName)
+	if flag == nil {
+		flag = cmd.InheritedFlags().Lookup(flagName)
+	}
+	if flag == nil {
+		return nil, nil, errors.New("unknown flag: " + Flag(cmd, flagName).String())
+	}
+	return cmd, flag, nil
+}
+
 // Executable returns the name of the executable.
 func Executable() string {
 	if executable, err := os.Executable(); err != nil {
@@ -32,3 +32,69 @@ func TestExecutable(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestUidFlag(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	sub := &cobra.Command{Use: "sub"}
+	root.AddCommand(sub)
+
+	assert.Equal(t, "cmd://root?flag=values", Flag(root, "values").String())
+	assert.Equal(t, "cmd://root/sub?flag=values", Flag(sub, "values").String())
+	assert.Equal(t, "cmd://root/sub", New("root", "sub").String())
+}
+
+func TestParse(t *testing.T) {
+	for s, expected := range map[string]string{
+		"cmd://root":                    "root ",
+		"cmd://root/sub1/sub2":          "root,sub1,sub2 ",
+		"cmd://root/sub1/?flag=values":  "root,sub1 values",
+		"cmd://root?flag=with%20space&": "root with space",
+	} {
+		path, flag, err := Parse(s)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		assert.Equal(t, expected, strings.Join(path, ",")+" "+flag)
+	}
+
+	for _, s := range []string{"file:///tmp/example.txt", "cmd:///sub", "%zz"} {
+		if _, _, err := Parse(s); err == nil {
+			t.Errorf("expected error for %#v", s)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().Bool("persistent", false, "")
+	sub := &cobra.Command{Use: "sub", Aliases: []string{"alias"}}
+	sub.Flags().String("values", "", "")
+	root.AddCommand(sub)
+
+	for _, s := range []string{
+		Command(sub).String(),
+		Flag(sub, "values").String(),
+		Flag(sub, "persistent").String(),
+	} {
+		cmd, flag, err := Find(root, s)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if cmd != sub {
+			t.Errorf("%v: expected subcommand", s)
+		}
+		if flag != nil {
+			assert.Equal(t, s, Flag(cmd, flag.Name).String())
+		}
+	}
+
+	for _, s := range []string{
+		"cmd://other/sub",
+		"cmd://root/alias",
+		"cmd://root/sub?flag=unknown",
+	} {
+		if _, _, err := Find(root, s); err == nil {
+			t.Errorf("expected error for %#v", s)
+		}
+	}
+}
@@ -33,7 +33,18 @@ func HasPathPrefix(s string) bool {
 	return strings.HasPrefix(s, ".") ||
 		strings.HasPrefix(s, "/") ||
 		strings.HasPrefix(s, "~") ||
-		HasVolumePrefix(s)
+		HasVolumePrefix(s) ||
+		HasUNCPrefix(s)
+}
+
+// CaseInsensitiveFilesystem checks if the filesystem is case insensitive by default (GOOS=darwin or GOOS=windows).
+func CaseInsensitiveFilesystem() bool {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return true
+	default:
+		return false
+	}
 }
 
 // HasVolumePrefix checks if given path has a volume prefix (only for GOOS=windows).
@@ -49,3 +60,11 @@ func HasVolumePrefix(s string) bool {
 		return false
 	}
 }
+
+// HasUNCPrefix checks if given path has an UNC prefix like `\\server\share` (only for GOOS=windows).
+func HasUNCPrefix(s string) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	return strings.HasPrefix(s, `\\`) || strings.HasPrefix(s, "//")
+}
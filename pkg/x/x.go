@@ -2,9 +2,9 @@
 package x
 
 import (
-	"github.com/carapace-sh/carapace/internal/export"
+	"github.com/carapace-sh/carapace/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 var ClearStorage func()
-var Complete func(cmd *cobra.Command, args ...string) (*export.Export, error)
+var Complete func(cmd *cobra.Command, args ...string) (*types.Export, error)
@@ -0,0 +1,51 @@
+package carapace
+
+import "sync"
+
+type _prefetch struct {
+	mutex   sync.Mutex
+	actions []Action
+	index   map[string]int // registration site -> index in actions
+}
+
+var prefetch _prefetch
+
+// add registers given action once per key (later registrations replace earlier ones).
+func (p *_prefetch) add(key string, a Action) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.index == nil {
+		p.index = make(map[string]int)
+	}
+	if i, ok := p.index[key]; ok {
+		p.actions[i] = a
+		return
+	}
+	p.index[key] = len(p.actions)
+	p.actions = append(p.actions, a)
+}
+
+// warm invokes all prefetchable actions concurrently and returns their messages.
+func (p *_prefetch) warm(c Context) []string {
+	p.mutex.Lock()
+	actions := append([]Action{}, p.actions...)
+	p.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	messages := make([][]string, len(actions))
+	for index, a := range actions {
+		wg.Add(1)
+		go func(index int, a Action) {
+			defer wg.Done()
+			messages[index] = a.Invoke(c).action.meta.Messages.Get()
+		}(index, a)
+	}
+	wg.Wait()
+
+	result := make([]string, 0)
+	for _, m := range messages {
+		result = append(result, m...)
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package carapace
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/carapace-sh/carapace/internal/shell"
+	"github.com/carapace-sh/carapace/pkg/uid"
+	"github.com/spf13/cobra"
+)
+
+// selftestShells are the shells checked by `_carapace selftest` by default (ion has no snippet).
+var selftestShells = []string{"bash", "bash-ble", "elvish", "export", "fish", "ksh", "nushell", "oil", "powershell", "tcsh", "xonsh", "ysh", "zsh"}
+
+// selftestSyntax are the commands checking the syntax of a snippet file for shells supporting it.
+// The check is skipped if the shell is not installed.
+var selftestSyntax = map[string][]string{
+	"bash":     {"bash", "-n"},
+	"bash-ble": {"bash", "-n"},
+	"elvish":   {"elvish", "-compileonly"},
+	"fish":     {"fish", "--no-execute"},
+	"ksh":      {"ksh", "-n"},
+	"oil":      {"osh", "-n"},
+	"tcsh":     {"tcsh", "-n"},
+	"ysh":      {"ysh", "-n"},
+	"zsh":      {"zsh", "-n"},
+}
+
+// selftestCommand creates the command completed by `_carapace selftest`.
+// A fresh one is needed for each compline as flag state is kept by cobra.
+func selftestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "selftest",
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	cmd.Flags().String("flag", "", "flag with argument")
+
+	Gen(cmd).FlagCompletion(ActionMap{
+		"flag": ActionValues("gamma", "delta"),
+	})
+
+	Gen(cmd).PositionalCompletion(
+		ActionValuesDescribed(
+			"alpha", "first",
+			"beta", "second",
+		),
+	)
+	return cmd
+}
+
+// selftest generates the snippet for given shell and completes a couple of complines
+// to verify that the serializer of the shell produces the expected values.
+func selftest(cmd *cobra.Command, shellName string) error {
+	snippet, err := shell.Snippet(cmd, shellName, uid.Executable())
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(snippet) == "" {
+		return fmt.Errorf("empty snippet")
+	}
+	if err := checkSyntax(shellName, snippet); err != nil {
+		return err
+	}
+
+	for _, compline := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{""}, "alpha"},
+		{[]string{"--fl"}, "--flag"},
+		{[]string{"--flag", ""}, "gamma"},
+	} {
+		output, err := complete(selftestCommand(), append([]string{shellName, "--plain", "selftest"}, compline.args...)) // independent of the invoking shell and user settings
+		if err != nil {
+			return fmt.Errorf("%#v: %v", compline.args, err.Error())
+		}
+		if !strings.Contains(output, compline.expected) {
+			return fmt.Errorf("%#v: expected %#v in output [was: %#v]", compline.args, compline.expected, output)
+		}
+	}
+	return nil
+}
+
+// checkSyntax checks the syntax of the snippet if the shell is installed.
+func checkSyntax(shellName, snippet string) error {
+	command, ok := selftestSyntax[shellName]
+	if !ok {
+		return nil
+	}
+	if _, err := exec.LookPath(command[0]); err != nil {
+		return nil
+	}
+
+	file, err := os.CreateTemp("", "carapace-selftest-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(snippet); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if output, err := exec.Command(command[0], append(command[1:], file.Name())...).CombinedOutput(); err != nil {
+		return fmt.Errorf("invalid snippet: %v", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
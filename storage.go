@@ -2,10 +2,12 @@ package carapace
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/carapace-sh/carapace/internal/common"
+	"github.com/carapace-sh/carapace/internal/pflagfork"
 	"github.com/carapace-sh/carapace/pkg/uid"
 
 	"github.com/spf13/cobra"
@@ -23,6 +25,7 @@ type entry struct {
 	dashAny       *Action
 	preinvoke     func(cmd *cobra.Command, flag *pflag.Flag, action Action) Action
 	prerun        func(cmd *cobra.Command, args []string)
+	catalog       Catalog
 	bridged       bool
 	initialized   bool
 }
@@ -107,14 +110,14 @@ func (s _storage) getFlag(cmd *cobra.Command, name string) Action {
 			if invoked.action.meta.Usage == "" {
 				invoked.action.meta.Usage = flag.Usage
 			}
-			return invoked.ToA()
-		})
+			return invoked.Suggest(pflagfork.Flag{Flag: flag}.DefaultValues()...).ToA()
+		}).source("flag:--" + name)
 	}
 }
 
 func (s _storage) preRun(cmd *cobra.Command, args []string) {
 	if entry := s.get(cmd); entry.prerun != nil {
-		LOG.Printf("executing PreRun for %#v with args %#v", cmd.Name(), args)
+		Log().Debugf("executing PreRun for %#v with args %#v", cmd.Name(), args)
 		entry.prerun(cmd, args)
 	}
 }
@@ -156,23 +159,28 @@ func (s _storage) getPositional(cmd *cobra.Command, index int) Action {
 	isDash := common.IsDash(cmd)
 
 	var a Action
+	var producer string
 	switch {
 	case !isDash && len(entry.positional) > index:
 		a = entry.positional[index]
+		producer = fmt.Sprintf("positional[%v]", index)
 	case !isDash:
 		if entry.positionalAny != nil {
 			a = *entry.positionalAny
 		} else {
 			a = ActionCobra(cmd.ValidArgsFunction)
 		}
+		producer = "positionalany"
 	case len(entry.dash) > index:
 		a = entry.dash[index]
+		producer = fmt.Sprintf("dash[%v]", index)
 	default:
 		if entry.dashAny != nil {
 			a = *entry.dashAny
 		} else {
 			a = ActionCobra(cmd.ValidArgsFunction)
 		}
+		producer = "dashany"
 	}
 	a = s.preinvoke(cmd, nil, a)
 
@@ -182,24 +190,83 @@ func (s _storage) getPositional(cmd *cobra.Command, index int) Action {
 			invoked.action.meta.Usage = cmd.Use
 		}
 		return invoked.ToA()
-	})
+	}).source(producer)
 }
 
 func (s _storage) check() []string {
+	storageMutex.RLock()
+	defer storageMutex.RUnlock()
+
 	errors := make([]string, 0)
 	for cmd, entry := range s {
-		func() {
-			entry.flagMutex.RLock()
-			defer entry.flagMutex.RUnlock()
-
-			for name := range entry.flag {
-				if flag := cmd.LocalFlags().Lookup(name); flag == nil {
-					errors = append(errors, fmt.Sprintf("unknown flag for %s: %s\n", uid.Command(cmd), name))
-				}
-			}
-		}()
+		for _, err := range entry.validate(cmd) {
+			errors = append(errors, err.Error())
+		}
 	}
+	sort.Strings(errors)
 	return errors
 }
 
+func (s _storage) validate(root *cobra.Command) []error {
+	storageMutex.RLock()
+	defer storageMutex.RUnlock()
+
+	errs := make([]error, 0)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if entry, ok := s[cmd]; ok {
+			errs = append(errs, entry.validate(cmd)...)
+		}
+		for _, subcmd := range cmd.Commands() {
+			walk(subcmd)
+		}
+	}
+	walk(root)
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// validate detects invalid registrations of given command.
+func (e *entry) validate(cmd *cobra.Command) []error {
+	errs := make([]error, 0)
+	e.flagMutex.RLock()
+	for name := range e.flag {
+		if lookupFlag(cmd, name) == nil {
+			errs = append(errs, errUnknownFlag(cmd, name))
+		}
+	}
+	e.flagMutex.RUnlock()
+
+	errs = append(errs, errsMissing(cmd, "positional", e.positional)...)
+	return append(errs, errsMissing(cmd, "dash", e.dash)...)
+}
+
+func errUnknownFlag(cmd *cobra.Command, name string) error {
+	return fmt.Errorf("%v: completion registered for unknown flag: %v", uid.Command(cmd), name)
+}
+
+func errsMissing(cmd *cobra.Command, kind string, actions []Action) []error {
+	errs := make([]error, 0)
+	for index, a := range actions {
+		if a.callback == nil && a.rawValues == nil {
+			errs = append(errs, fmt.Errorf("%v: %v completion missing at index %v", uid.Command(cmd), kind, index))
+		}
+	}
+	return errs
+}
+
+// lookupFlag looks up given flag in the local flags and the persistent flags of the command and its parents.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if flag := cmd.Flags().Lookup(name); flag != nil {
+		return flag
+	}
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if flag := parent.PersistentFlags().Lookup(name); flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
 var storage = make(_storage)
@@ -1,6 +1,7 @@
 package carapace
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -76,6 +77,40 @@ func TestCheck(t *testing.T) {
 	}
 }
 
+func TestValidate(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "validate"}
+	rootCmd.PersistentFlags().String("persistent", "", "")
+	subCmd := &cobra.Command{Use: "sub"}
+	subCmd.Flags().String("flag", "", "")
+	rootCmd.AddCommand(subCmd)
+	orphanCmd := &cobra.Command{Use: "orphan"}
+
+	Gen(rootCmd).PositionalCompletion(ActionValues("a"), ActionValues("b"))
+	Gen(subCmd).FlagCompletion(ActionMap{
+		"flag":       ActionValues("a", "b"),
+		"persistent": ActionValues("a", "b"),
+	})
+	if errs := Gen(rootCmd).Validate(); len(errs) != 0 {
+		t.Errorf("validate should succeed: %v", errs)
+	}
+
+	Gen(subCmd).FlagCompletion(ActionMap{
+		"unknown": ActionValues("a", "b"),
+	})
+	Gen(subCmd).DashCompletion(Action{}, ActionValues("a"))
+	Gen(orphanCmd).FlagCompletion(ActionMap{ // not part of the tree
+		"unknown": ActionValues("a", "b"),
+	})
+
+	expected := []string{
+		"cmd://validate/sub: completion registered for unknown flag: unknown",
+		"cmd://validate/sub: dash completion missing at index 0",
+	}
+	if actual := fmt.Sprint(Gen(subCmd).Validate()); actual != fmt.Sprint(expected) {
+		t.Errorf("expected %v [was: %v]", expected, actual)
+	}
+}
+
 // BenchmarkStorage tests for concurrent map read/write.
 func BenchmarkStorage(b *testing.B) {
 	cmd := &cobra.Command{}
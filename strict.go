@@ -0,0 +1,44 @@
+package carapace
+
+import "github.com/spf13/cobra"
+
+var strict bool
+
+// Strict makes invalid registrations (e.g. completion for an unknown flag) panic instead of only being logged.
+// Enable it for development builds so that typos don't silently result in dead completions.
+// Needs to be set before completions are registered.
+//
+//	carapace.Strict(version == "dev")
+func Strict(enabled bool) {
+	strict = enabled
+}
+
+// checkFlag verifies that completion is registered for an existing flag.
+// Detached commands are verified on initialization as the flag might be inherited from a parent added later.
+func checkFlag(cmd *cobra.Command, name string) {
+	switch {
+	case lookupFlag(cmd, name) != nil:
+	case !cmd.HasParent():
+		cobra.OnInitialize(func() {
+			if lookupFlag(cmd, name) == nil {
+				invalidRegistration(errUnknownFlag(cmd, name))
+			}
+		})
+	default:
+		invalidRegistration(errUnknownFlag(cmd, name))
+	}
+}
+
+// checkPositional verifies that there are no gaps in given positional completion.
+func checkPositional(cmd *cobra.Command, kind string, actions []Action) {
+	for _, err := range errsMissing(cmd, kind, actions) {
+		invalidRegistration(err)
+	}
+}
+
+func invalidRegistration(err error) {
+	Log().Errorf("invalid registration: %v", err.Error())
+	if strict {
+		panic(err.Error())
+	}
+}
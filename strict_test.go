@@ -0,0 +1,40 @@
+package carapace
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestStrict(t *testing.T) {
+	Strict(true)
+	defer Strict(false)
+
+	rootCmd := &cobra.Command{Use: "strict"}
+	rootCmd.PersistentFlags().String("persistent", "", "")
+	subCmd := &cobra.Command{Use: "sub"}
+	subCmd.Flags().String("flag", "", "")
+
+	expectPanic := func(expected string, f func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != expected {
+				t.Errorf("expected panic %#v, got %#v", expected, r)
+			}
+		}()
+		f()
+	}
+
+	Gen(subCmd).FlagCompletion(ActionMap{
+		"flag":       ActionValues(),
+		"persistent": ActionValues(), // detached: verified on initialization
+	})
+	rootCmd.AddCommand(subCmd)
+
+	expectPanic("cmd://strict/sub: completion registered for unknown flag: unknown", func() {
+		Gen(subCmd).FlagCompletion(ActionMap{"unknown": ActionValues()})
+	})
+	expectPanic("cmd://strict/sub: dash completion missing at index 1", func() {
+		Gen(subCmd).DashCompletion(ActionValues(), Action{})
+	})
+}
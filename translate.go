@@ -0,0 +1,92 @@
+package carapace
+
+import (
+	"os"
+	"strings"
+)
+
+// Catalog contains translated descriptions by locale (e.g. `de` or `de_DE`) and english description.
+//
+//	carapace.Catalog{
+//		"de": {
+//			"bool flag": "Boolescher Schalter",
+//		},
+//	}
+type Catalog map[string]map[string]string
+
+// Translate translates descriptions to the locale of the user (`LC_ALL`, `LC_MESSAGES` or `LANG`).
+// Descriptions without a translation are kept as is (english).
+// Messages are looked up by their template (e.g. `unknown macro: %#v`) so built-in ones can be translated as well.
+// Needs to be set on the root command.
+func (c Carapace) Translate(catalog Catalog) {
+	storage.get(c.cmd).catalog = catalog
+}
+
+// lookup returns the translation of given description for the first matching locale.
+func (c Catalog) lookup(locales []string, s string) string {
+	for _, locale := range locales {
+		if translation := c[locale][s]; translation != "" {
+			return translation
+		}
+	}
+	return s
+}
+
+// locales returns the user locale in decreasing specificity (e.g. `de_DE.UTF-8` -> `[de_DE, de]`).
+func locales() []string {
+	var locale string
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(name); locale != "" {
+			break
+		}
+	}
+
+	locale = strings.SplitN(locale, ".", 2)[0] // encoding
+	locale = strings.SplitN(locale, "@", 2)[0] // modifier
+	switch locale {
+	case "", "C", "POSIX":
+		return nil
+	}
+
+	result := []string{locale}
+	if language := strings.SplitN(locale, "_", 2)[0]; language != locale {
+		result = append(result, language)
+	}
+	return result
+}
+
+// translate translates descriptions and usage of given action.
+func (a Action) translate(catalog Catalog) Action {
+	return ActionCallback(func(c Context) Action {
+		invoked := a.Invoke(c)
+		locales := locales()
+		if len(catalog) == 0 || len(locales) == 0 {
+			return invoked.ToA()
+		}
+
+		for index, value := range invoked.action.rawValues {
+			if value.Description != "" {
+				invoked.action.rawValues[index].Description = catalog.lookup(locales, value.Description)
+			}
+		}
+		if usage := invoked.action.meta.Usage; usage != "" {
+			invoked.action.meta.Usage = catalog.lookup(locales, usage)
+		}
+		return invoked.ToA()
+	})
+}
+
+// translate returns the translation of given message (template) using the catalog of the root command.
+func (c Context) translate(s string) string {
+	if c.cmd == nil {
+		return s
+	}
+
+	storageMutex.RLock()
+	e, ok := storage[c.cmd.Root()]
+	storageMutex.RUnlock()
+	if !ok || len(e.catalog) == 0 {
+		return s
+	}
+	return e.catalog.lookup(locales(), s)
+}
@@ -0,0 +1,139 @@
+package carapace
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestLocales(t *testing.T) {
+	for lang, expected := range map[string][]string{
+		"":                 nil,
+		"C":                nil,
+		"C.UTF-8":          nil,
+		"de":               {"de"},
+		"de_DE.UTF-8":      {"de_DE", "de"},
+		"sr_RS.UTF-8@latn": {"sr_RS", "sr"},
+	} {
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", lang)
+		if actual := locales(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%#v: expected %#v [was: %#v]", lang, expected, actual)
+		}
+	}
+
+	t.Setenv("LC_MESSAGES", "fr_FR")
+	if actual := locales(); !reflect.DeepEqual([]string{"fr_FR", "fr"}, actual) {
+		t.Errorf("LC_MESSAGES should take precedence over LANG [was: %#v]", actual)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	catalog := Catalog{
+		"de": {
+			"first value": "erster Wert",
+			"positional":  "Positionsargument",
+		},
+		"de_AT": {
+			"second value": "zweiter Wert (AT)",
+		},
+	}
+	a := ActionValuesDescribed(
+		"first", "first value",
+		"second", "second value",
+		"third", "third value",
+	).Usage("positional").translate(catalog)
+
+	t.Setenv("LC_ALL", "de_AT.UTF-8")
+	assertEqual(t,
+		ActionValuesDescribed(
+			"first", "erster Wert",
+			"second", "zweiter Wert (AT)",
+			"third", "third value",
+		).Usage("Positionsargument").Invoke(Context{}),
+		a.Invoke(Context{}),
+	)
+
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	assertEqual(t,
+		ActionValuesDescribed(
+			"first", "first value",
+			"second", "second value",
+			"third", "third value",
+		).Usage("positional").Invoke(Context{}),
+		a.Invoke(Context{}),
+	)
+}
+
+func TestCompleteTranslate(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	cmd.Flags().Bool("bool", false, "bool flag")
+
+	Gen(cmd).Translate(Catalog{
+		"de": {
+			"bool flag": "Boolescher Schalter",
+		},
+	})
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if s, err := complete(cmd, []string{"export", "test", "--b"}); err != nil || !strings.Contains(s, `"description":"Boolescher Schalter"`) {
+		t.Error(s)
+	}
+}
+
+func TestCompleteTranslateMessage(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionMacro("$unknown"),
+	)
+
+	Gen(cmd).Translate(Catalog{
+		"de": {
+			"unknown macro: %#v": "unbekanntes Makro: %#v",
+		},
+	})
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if s, err := complete(cmd, []string{"export", "test", ""}); err != nil || !strings.Contains(s, `unbekanntes Makro: \"unknown\"`) {
+		t.Error(s)
+	}
+
+	t.Setenv("LC_ALL", "C")
+	if s, err := complete(cmd, []string{"export", "test", ""}); err != nil || !strings.Contains(s, `unknown macro: \"unknown\"`) {
+		t.Error(s)
+	}
+}
+
+func TestCompleteTranslateMore(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+
+	Gen(cmd).PositionalCompletion(
+		ActionValues("a1", "a2", "a3").Limit(2),
+	)
+
+	Gen(cmd).Translate(Catalog{
+		"de": {
+			"… %v more (use narrower prefix)": "… %v weitere (genaueres Präfix verwenden)",
+		},
+	})
+
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	if s, err := complete(cmd, []string{"export", "test", ""}); err != nil || !strings.Contains(s, `… 1 weitere (genaueres Präfix verwenden)`) {
+		t.Error(s)
+	}
+
+	t.Setenv("CARAPACE_MAX_RESULTS", "1")
+	if s, err := complete(cmd, []string{"export", "test", ""}); err != nil || !strings.Contains(s, `… 2 weitere (genaueres Präfix verwenden)`) || strings.Contains(s, "1 weitere") {
+		t.Error(s)
+	}
+}